@@ -3,9 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/fsnotify.v1"
@@ -24,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	routev1 "github.com/openshift/api/route/v1"
 	unidlingapi "github.com/openshift/api/unidling/v1alpha1"
 )
 
@@ -39,6 +42,11 @@ type StartOptions struct {
 	ShutdownFile string
 	// MetricsListenAddr is the address on which to expose the metrics endpoint.
 	MetricsListenAddr string
+	// MetricsTLSCertFile is the path to a certificate file for serving the
+	// metrics endpoint over TLS.  If set, MetricsTLSKeyFile must be set too.
+	MetricsTLSCertFile string
+	// MetricsTLSKeyFile is the path to the key for MetricsTLSCertFile.
+	MetricsTLSKeyFile string
 	// OperatorNamespace is the namespace the operator should watch for
 	// ingresscontroller resources.
 	OperatorNamespace string
@@ -48,8 +56,86 @@ type StartOptions struct {
 	// CanaryImage is the pullspec of the canary tester server image to
 	// be managed.
 	CanaryImage string
+	// CanaryExternalProbeURL is an optional external URL for the canary
+	// controller to probe in addition to the internal canary route.
+	CanaryExternalProbeURL string
+	// CanaryRouteTLSTermination is an optional TLS termination type
+	// ("edge" or "reencrypt") to set on the canary route.
+	CanaryRouteTLSTermination string
+	// CanaryPlacementNodeRole is an optional node role to direct the canary
+	// workload's pods to.
+	CanaryPlacementNodeRole string
+	// CanaryRouteProbeCABundleFile is an optional path to a PEM CA bundle
+	// used to verify the canary route's serving certificate.
+	CanaryRouteProbeCABundleFile string
 	// ReleaseVersion is the cluster version which the operator will converge to.
 	ReleaseVersion string
+	// DefaultPlacementNodeRole is an optional node role to direct the default
+	// ingresscontroller's router pods to when the operator creates it.
+	DefaultPlacementNodeRole string
+	// ReconcileInterval is the interval of the operator's periodic ensure
+	// loop.
+	ReconcileInterval time.Duration
+	// ReconcileJitter is the jitter factor applied to ReconcileInterval.
+	ReconcileJitter float64
+	// CacheSyncPeriod is the informer resync period of the operator's
+	// cache.
+	CacheSyncPeriod time.Duration
+	// DNSResolutionCheckResolver is the "host:port" of a DNS resolver for
+	// verifying that published DNS records resolve (optional).
+	DNSResolutionCheckResolver string
+	// DNSResolutionCheckTimeout bounds each DNS resolution check attempt.
+	DNSResolutionCheckTimeout time.Duration
+	// DNSDeletionTimeout is how long the dns controller keeps retrying a
+	// failing DNS provider cleanup before giving up and removing the
+	// dnsrecord's finalizer.
+	DNSDeletionTimeout time.Duration
+	// DefaultReplicasNodesPerReplica, if positive, scales the default
+	// ingresscontroller's initial replica count with the worker node count.
+	DefaultReplicasNodesPerReplica int
+	// DefaultReplicasMaximum, if positive, caps the scaled replica count.
+	DefaultReplicasMaximum int
+	// RouteMetricsChunkSize is the page size used when listing routes for
+	// route metrics.
+	RouteMetricsChunkSize int64
+	// DisabledControllers names controllers to disable on any topology.
+	DisabledControllers []string
+	// ExternalTopologyDisabledControllers names controllers to disable when
+	// the cluster's control-plane topology is External.
+	ExternalTopologyDisabledControllers []string
+	// AdditionalWatchNamespaces names namespaces for the operator's cache to
+	// watch in addition to the built-in namespaces.
+	AdditionalWatchNamespaces []string
+	// TrustedCASourceConfigMap is an optional configmap in the operator
+	// namespace whose CA bundle is appended to the trusted CA bundle.
+	TrustedCASourceConfigMap string
+	// TrustedCAConfigMapAnnotations are optional annotations to enforce on
+	// the trusted CA configmap.
+	TrustedCAConfigMapAnnotations map[string]string
+	// TrustedCAConfigMapLabels are optional labels to enforce on the
+	// trusted CA configmap.
+	TrustedCAConfigMapLabels map[string]string
+	// AdditionalOwnerReference is an optional object ("Kind/name" in the
+	// operand namespace) added as an additional owner of the router
+	// deployments the operator creates.
+	AdditionalOwnerReference string
+	// MinimumTLSVersion is an optional cluster-wide floor on the minimum
+	// TLS version of the routers' effective TLS profiles.
+	MinimumTLSVersion string
+	// CertificateControllerMaxConcurrentReconciles is the maximum number of
+	// ingresscontrollers that the certificate controller reconciles
+	// concurrently.
+	CertificateControllerMaxConcurrentReconciles int
+	// ClientQPS is the maximum sustained queries per second that the
+	// operator's client allows to the API server.
+	ClientQPS float32
+	// ClientBurst is the maximum burst of queries that the operator's client
+	// allows to the API server before throttling to ClientQPS.
+	ClientBurst int
+	// Preflight, if true, verifies that required CRDs are registered and
+	// that the operator's service account has the access the controllers
+	// need before starting, failing fast when a prerequisite is missing.
+	Preflight bool
 }
 
 func NewStartCommand() *cobra.Command {
@@ -70,9 +156,37 @@ func NewStartCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.OperatorNamespace, "namespace", "n", operatorcontroller.DefaultOperatorNamespace, "namespace the operator is deployed to (required)")
 	cmd.Flags().StringVarP(&options.IngressControllerImage, "image", "i", "", "image of the ingress controller the operator will manage (required)")
 	cmd.Flags().StringVarP(&options.CanaryImage, "canary-image", "c", "", "image of the canary container that the operator will manage (optional)")
+	cmd.Flags().StringVar(&options.CanaryExternalProbeURL, "canary-external-probe-url", "", "external URL for the canary controller to probe (optional)")
+	cmd.Flags().StringVar(&options.CanaryRouteTLSTermination, "canary-route-tls-termination", "", "TLS termination type to set on the canary route, either edge or reencrypt (optional)")
+	cmd.Flags().StringVar(&options.CanaryPlacementNodeRole, "canary-placement-node-role", "", "node role to place the canary workload's pods on, e.g. master (optional)")
+	cmd.Flags().StringVar(&options.CanaryRouteProbeCABundleFile, "canary-route-probe-ca-bundle", "", "path to a PEM CA bundle used to verify the canary route's serving certificate (optional)")
 	cmd.Flags().StringVarP(&options.ReleaseVersion, "release-version", "", statuscontroller.UnknownVersionValue, "the release version the operator should converge to (required)")
 	cmd.Flags().StringVarP(&options.MetricsListenAddr, "metrics-listen-addr", "", "127.0.0.1:60000", "metrics endpoint listen address (required)")
+	cmd.Flags().StringVar(&options.MetricsTLSCertFile, "metrics-tls-cert-file", "", "certificate for serving the metrics endpoint over TLS (optional)")
+	cmd.Flags().StringVar(&options.MetricsTLSKeyFile, "metrics-tls-key-file", "", "key for --metrics-tls-cert-file (optional)")
 	cmd.Flags().StringVarP(&options.ShutdownFile, "shutdown-file", "s", defaultTrustedCABundle, "if provided, shut down the operator when this file changes")
+	cmd.Flags().Float32Var(&options.ClientQPS, "client-qps", 20, "maximum sustained queries per second to the API server")
+	cmd.Flags().IntVar(&options.ClientBurst, "client-burst", 40, "maximum burst of queries to the API server")
+	cmd.Flags().DurationVar(&options.ReconcileInterval, "reconcile-interval", 1*time.Minute, "interval of the operator's periodic ensure loop (minimum 10s)")
+	cmd.Flags().Float64Var(&options.ReconcileJitter, "reconcile-jitter", 0.1, "jitter factor applied to --reconcile-interval (maximum 1.0)")
+	cmd.Flags().DurationVar(&options.CacheSyncPeriod, "cache-sync-period", 24*time.Hour, "informer resync period of the operator's cache (minimum 10m)")
+	cmd.Flags().StringVar(&options.DNSResolutionCheckResolver, "dns-resolution-check-resolver", "", "host:port of a DNS resolver for verifying that published DNS records resolve (optional)")
+	cmd.Flags().DurationVar(&options.DNSResolutionCheckTimeout, "dns-resolution-check-timeout", 5*time.Second, "timeout for each DNS resolution check attempt")
+	cmd.Flags().DurationVar(&options.DNSDeletionTimeout, "dns-deletion-timeout", 10*time.Minute, "how long to keep retrying a failing DNS provider cleanup before removing the dnsrecord's finalizer")
+	cmd.Flags().StringVar(&options.DefaultPlacementNodeRole, "default-placement-node-role", "", "node role to place the default ingresscontroller's router pods on (optional)")
+	cmd.Flags().IntVar(&options.DefaultReplicasNodesPerReplica, "default-replicas-nodes-per-replica", 0, "scale the default ingresscontroller's initial replicas with the worker node count, one replica per this many workers; 0 disables scaling (optional)")
+	cmd.Flags().IntVar(&options.DefaultReplicasMaximum, "default-replicas-maximum", 0, "cap on the scaled default ingresscontroller replica count; 0 means no cap (optional)")
+	cmd.Flags().Int64Var(&options.RouteMetricsChunkSize, "route-metrics-chunk-size", 1000, "page size used when listing routes for route metrics")
+	cmd.Flags().StringSliceVar(&options.DisabledControllers, "disabled-controllers", nil, "comma-separated controllers to disable on any topology (allowed: canary, dns, pod-health)")
+	cmd.Flags().StringSliceVar(&options.ExternalTopologyDisabledControllers, "external-topology-disabled-controllers", nil, "comma-separated controllers to disable when the control-plane topology is External (allowed: canary, dns, pod-health)")
+	cmd.Flags().StringSliceVar(&options.AdditionalWatchNamespaces, "additional-watch-namespaces", nil, "comma-separated namespaces for the operator's cache to watch in addition to the built-in namespaces (optional)")
+	cmd.Flags().StringToStringVar(&options.TrustedCAConfigMapAnnotations, "trusted-ca-configmap-annotations", nil, "comma-separated key=value annotations to enforce on the trusted CA configmap (optional)")
+	cmd.Flags().StringToStringVar(&options.TrustedCAConfigMapLabels, "trusted-ca-configmap-labels", nil, "comma-separated key=value labels to enforce on the trusted CA configmap (optional)")
+	cmd.Flags().StringVar(&options.TrustedCASourceConfigMap, "trusted-ca-source-configmap", "", "configmap in the operator namespace whose ca-bundle.crt is appended to the injected trusted CA bundle (optional)")
+	cmd.Flags().StringVar(&options.AdditionalOwnerReference, "additional-owner-reference", "", "object (Kind/name in the operand namespace) added as an additional owner of the router deployments (optional)")
+	cmd.Flags().StringVar(&options.MinimumTLSVersion, "minimum-tls-version", "", "floor on the minimum TLS version of the routers' TLS profiles, e.g. VersionTLS12 (optional)")
+	cmd.Flags().IntVar(&options.CertificateControllerMaxConcurrentReconciles, "certificate-controller-max-concurrent-reconciles", 1, "maximum number of ingresscontrollers that the certificate controller reconciles concurrently")
+	cmd.Flags().BoolVar(&options.Preflight, "preflight", false, "verify required CRDs and RBAC access before starting, and fail fast if any prerequisite is missing")
 
 	if err := cmd.MarkFlagRequired("namespace"); err != nil {
 		panic(err)
@@ -93,6 +207,30 @@ func start(opts *StartOptions) error {
 
 	log.Info("using operator namespace", "namespace", opts.OperatorNamespace)
 
+	if opts.Preflight {
+		if err := operator.Preflight(kubeConfig); err != nil {
+			return fmt.Errorf("preflight checks failed: %v", err)
+		}
+		log.Info("preflight checks passed")
+	}
+
+	// Fail fast on an incomplete or invalid metrics TLS configuration
+	// rather than failing asynchronously once scraping starts.
+	if len(opts.MetricsTLSCertFile) != 0 || len(opts.MetricsTLSKeyFile) != 0 {
+		if len(opts.MetricsTLSCertFile) == 0 || len(opts.MetricsTLSKeyFile) == 0 {
+			return fmt.Errorf("metrics TLS requires both --metrics-tls-cert-file and --metrics-tls-key-file")
+		}
+		if _, err := tls.LoadX509KeyPair(opts.MetricsTLSCertFile, opts.MetricsTLSKeyFile); err != nil {
+			return fmt.Errorf("invalid metrics TLS certificate/key pair: %v", err)
+		}
+	}
+
+	switch opts.CanaryRouteTLSTermination {
+	case "", string(routev1.TLSTerminationEdge), string(routev1.TLSTerminationReencrypt):
+	default:
+		return fmt.Errorf("invalid value %q for --canary-route-tls-termination; must be %q or %q", opts.CanaryRouteTLSTermination, routev1.TLSTerminationEdge, routev1.TLSTerminationReencrypt)
+	}
+
 	if opts.ReleaseVersion == statuscontroller.UnknownVersionValue {
 		log.Info("Warning: no release version is specified", "release version", statuscontroller.UnknownVersionValue)
 	}
@@ -116,15 +254,45 @@ func start(opts *StartOptions) error {
 	signal := signals.SetupSignalHandler()
 
 	operatorConfig := operatorconfig.Config{
-		OperatorReleaseVersion: opts.ReleaseVersion,
-		Namespace:              opts.OperatorNamespace,
-		IngressControllerImage: opts.IngressControllerImage,
-		CanaryImage:            opts.CanaryImage,
-		Stop:                   stop,
+		OperatorReleaseVersion:                       opts.ReleaseVersion,
+		Namespace:                                    opts.OperatorNamespace,
+		IngressControllerImage:                       opts.IngressControllerImage,
+		CanaryImage:                                  opts.CanaryImage,
+		CanaryExternalProbeURL:                       opts.CanaryExternalProbeURL,
+		CanaryRouteTLSTermination:                    opts.CanaryRouteTLSTermination,
+		CanaryPlacementNodeRole:                      opts.CanaryPlacementNodeRole,
+		CanaryRouteProbeCABundleFile:                 opts.CanaryRouteProbeCABundleFile,
+		ReconcileInterval:                            opts.ReconcileInterval,
+		ReconcileJitter:                              opts.ReconcileJitter,
+		CacheSyncPeriod:                              opts.CacheSyncPeriod,
+		DNSResolutionCheckResolver:                   opts.DNSResolutionCheckResolver,
+		DNSResolutionCheckTimeout:                    opts.DNSResolutionCheckTimeout,
+		DNSDeletionTimeout:                           opts.DNSDeletionTimeout,
+		ClientQPS:                                    opts.ClientQPS,
+		ClientBurst:                                  opts.ClientBurst,
+		DefaultPlacementNodeRole:                     opts.DefaultPlacementNodeRole,
+		DefaultReplicasNodesPerReplica:               opts.DefaultReplicasNodesPerReplica,
+		DefaultReplicasMaximum:                       opts.DefaultReplicasMaximum,
+		RouteMetricsChunkSize:                        opts.RouteMetricsChunkSize,
+		DisabledControllers:                          opts.DisabledControllers,
+		ExternalTopologyDisabledControllers:          opts.ExternalTopologyDisabledControllers,
+		AdditionalWatchNamespaces:                    opts.AdditionalWatchNamespaces,
+		TrustedCASourceConfigMap:                     opts.TrustedCASourceConfigMap,
+		TrustedCAConfigMapAnnotations:                opts.TrustedCAConfigMapAnnotations,
+		TrustedCAConfigMapLabels:                     opts.TrustedCAConfigMapLabels,
+		AdditionalOwnerReference:                     opts.AdditionalOwnerReference,
+		MinimumTLSVersion:                            opts.MinimumTLSVersion,
+		CertificateControllerMaxConcurrentReconciles: opts.CertificateControllerMaxConcurrentReconciles,
+		Stop: stop,
 	}
 
+	// Log the fully-resolved configuration once at startup so that the
+	// configuration the operator is actually running with is answerable
+	// from its logs.
+	log.Info("effective operator configuration", operatorConfig.EffectiveConfig()...)
+
 	// Start operator metrics.
-	go canarycontroller.StartMetricsListener(opts.MetricsListenAddr, stop)
+	go canarycontroller.StartMetricsListener(opts.MetricsListenAddr, opts.MetricsTLSCertFile, opts.MetricsTLSKeyFile, stop)
 
 	// Set up and start the file watcher.
 	watcher, err := fsnotify.NewWatcher()
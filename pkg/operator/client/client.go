@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	iov1 "github.com/openshift/api/operatoringress/v1"
@@ -13,7 +14,9 @@ import (
 	"k8s.io/client-go/rest"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -54,12 +57,162 @@ func NewClient(kubeConfig *rest.Config) (client.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover api rest mapper: %v", err)
 	}
-	kubeClient, err := client.New(kubeConfig, client.Options{
+	return NewProtobufAwareClient(kubeConfig, client.Options{
 		Scheme: scheme,
 		Mapper: mapper,
 	})
+}
+
+// protobufGroups are the API groups for which the operator's client requests
+// protobuf encoding.  Only built-in kube groups support protobuf; CRD-backed
+// groups (operator.openshift.io, config.openshift.io, and so on) are served
+// as JSON only.
+var protobufGroups = sets.NewString("", "apps")
+
+// ProtobufConfig returns a copy of the given REST config that negotiates
+// protobuf encoding, falling back to JSON if the server does not support
+// protobuf for a resource.
+func ProtobufConfig(kubeConfig *rest.Config) *rest.Config {
+	cfg := rest.CopyConfig(kubeConfig)
+	cfg.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
+	cfg.ContentType = "application/vnd.kubernetes.protobuf"
+	return cfg
+}
+
+// NewProtobufAwareClient builds a client that uses protobuf encoding for the
+// built-in kube groups that support it and JSON for everything else.  A
+// single rest.Config can only negotiate one content type, so the client
+// dispatches between a protobuf-configured and a JSON-configured client by
+// the object's group.
+func NewProtobufAwareClient(kubeConfig *rest.Config, options client.Options) (client.Client, error) {
+	jsonClient, err := client.New(kubeConfig, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kube client: %v", err)
 	}
-	return kubeClient, nil
+	protoClient, err := client.New(ProtobufConfig(kubeConfig), options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create protobuf kube client: %v", err)
+	}
+	clientScheme := options.Scheme
+	if clientScheme == nil {
+		clientScheme = scheme
+	}
+	return &protobufAwareClient{
+		json:   jsonClient,
+		proto:  protoClient,
+		scheme: clientScheme,
+	}, nil
+}
+
+// protobufAwareClient dispatches requests to a protobuf-encoding client for
+// groups that support protobuf and to a JSON-encoding client otherwise.
+type protobufAwareClient struct {
+	json   client.Client
+	proto  client.Client
+	scheme *runtime.Scheme
+}
+
+// delegateFor returns the client to use for the given object.
+func (c *protobufAwareClient) delegateFor(obj runtime.Object) client.Client {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		// The JSON client can serialize anything the scheme knows.
+		return c.json
+	}
+	if protobufGroups.Has(gvk.Group) {
+		return c.proto
+	}
+	return c.json
+}
+
+func (c *protobufAwareClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	return c.delegateFor(obj).Get(ctx, key, obj)
+}
+
+func (c *protobufAwareClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return c.delegateFor(list).List(ctx, list, opts...)
+}
+
+func (c *protobufAwareClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	return c.delegateFor(obj).Create(ctx, obj, opts...)
+}
+
+func (c *protobufAwareClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	return c.delegateFor(obj).Delete(ctx, obj, opts...)
+}
+
+func (c *protobufAwareClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return c.delegateFor(obj).Update(ctx, obj, opts...)
+}
+
+func (c *protobufAwareClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.delegateFor(obj).Patch(ctx, obj, patch, opts...)
+}
+
+func (c *protobufAwareClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	return c.delegateFor(obj).DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *protobufAwareClient) Status() client.StatusWriter {
+	return &protobufAwareStatusWriter{client: c}
+}
+
+// protobufAwareStatusWriter dispatches status writes with the same group
+// rules as its parent client.
+type protobufAwareStatusWriter struct {
+	client *protobufAwareClient
+}
+
+func (w *protobufAwareStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return w.client.delegateFor(obj).Status().Update(ctx, obj, opts...)
+}
+
+func (w *protobufAwareStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.client.delegateFor(obj).Status().Patch(ctx, obj, patch, opts...)
+}
+
+// NewCachedReader returns a reader that serves reads for objects in one of
+// the given namespaces from the given cache and falls back to the given live
+// client for everything else.  In particular, cluster-scoped objects and
+// lists that span namespaces fall back to the live client because a
+// multi-namespace cache cannot serve them.
+//
+// The returned reader does not promise read-your-writes coherence, so only
+// controllers that tolerate stale data (for example, controllers that only
+// derive status from watched objects and reconcile again on every change)
+// should use it.
+func NewCachedReader(cache cache.Cache, liveClient client.Client, namespaces ...string) client.Reader {
+	return &cachedReader{
+		cache:      cache,
+		client:     liveClient,
+		namespaces: sets.NewString(namespaces...),
+	}
+}
+
+// cachedReader implements client.Reader using a cache for reads in a fixed
+// set of namespaces and a live client for everything else.
+type cachedReader struct {
+	cache      cache.Cache
+	client     client.Client
+	namespaces sets.String
+}
+
+// Get retrieves the object with the given key, using the cache if the key's
+// namespace is cached.
+func (r *cachedReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if r.namespaces.Has(key.Namespace) {
+		return r.cache.Get(ctx, key, obj)
+	}
+	return r.client.Get(ctx, key, obj)
+}
+
+// List retrieves a list of objects, using the cache if the list is scoped to
+// a cached namespace.
+func (r *cachedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if len(listOpts.Namespace) != 0 && r.namespaces.Has(listOpts.Namespace) {
+		return r.cache.List(ctx, list, opts...)
+	}
+	return r.client.List(ctx, list, opts...)
 }
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestProtobufConfig verifies that the protobuf REST config negotiates
+// protobuf with a JSON fallback and does not modify the original config.
+func TestProtobufConfig(t *testing.T) {
+	original := &rest.Config{}
+	cfg := ProtobufConfig(original)
+	if expected := "application/vnd.kubernetes.protobuf"; cfg.ContentType != expected {
+		t.Errorf("expected content type %q, got %q", expected, cfg.ContentType)
+	}
+	if expected := "application/vnd.kubernetes.protobuf,application/json"; cfg.AcceptContentTypes != expected {
+		t.Errorf("expected accepted content types %q, got %q", expected, cfg.AcceptContentTypes)
+	}
+	if len(original.ContentType) != 0 || len(original.AcceptContentTypes) != 0 {
+		t.Errorf("expected the original config to be unmodified, got content type %q and accepted content types %q", original.ContentType, original.AcceptContentTypes)
+	}
+}
+
+// TestProtobufAwareClientDelegation verifies that the client uses protobuf
+// encoding for built-in kube groups and JSON for CRD-backed groups.
+func TestProtobufAwareClientDelegation(t *testing.T) {
+	jsonClient := fake.NewFakeClientWithScheme(GetScheme())
+	protoClient := fake.NewFakeClientWithScheme(GetScheme())
+	c := &protobufAwareClient{
+		json:   jsonClient,
+		proto:  protoClient,
+		scheme: GetScheme(),
+	}
+	testCases := []struct {
+		description    string
+		object         runtime.Object
+		expectProtobuf bool
+	}{
+		{
+			description:    "core/v1 objects use protobuf",
+			object:         &corev1.Pod{},
+			expectProtobuf: true,
+		},
+		{
+			description:    "apps/v1 objects use protobuf",
+			object:         &appsv1.Deployment{},
+			expectProtobuf: true,
+		},
+		{
+			description: "operator.openshift.io objects use JSON",
+			object:      &operatorv1.IngressController{},
+		},
+		{
+			description: "config.openshift.io objects use JSON",
+			object:      &configv1.Infrastructure{},
+		},
+		{
+			description: "ingress.operator.openshift.io objects use JSON",
+			object:      &iov1.DNSRecord{},
+		},
+	}
+	for _, tc := range testCases {
+		expected := jsonClient
+		if tc.expectProtobuf {
+			expected = protoClient
+		}
+		if delegate := c.delegateFor(tc.object); delegate != expected {
+			t.Errorf("%q: object %T was dispatched to the wrong client", tc.description, tc.object)
+		}
+	}
+}
@@ -1,11 +1,27 @@
 package config
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
 // Config is configuration for the operator and should include things like
 // operated images, scheduling configuration, etc.
 type Config struct {
 	// OperatorReleaseVersion is the current version of operator.
 	OperatorReleaseVersion string
 
+	// ClientQPS is the maximum sustained queries per second that the
+	// operator's client allows to the API server.  All controllers share
+	// the same client and therefore the same rate limiter.
+	ClientQPS float32
+
+	// ClientBurst is the maximum burst of queries that the operator's
+	// client allows to the API server before throttling to ClientQPS.
+	ClientBurst int
+
 	// Namespace is the operator namespace.
 	Namespace string
 
@@ -15,5 +31,173 @@ type Config struct {
 	// CanaryImage is the ingress canary image to manage.
 	CanaryImage string
 
+	// CanaryExternalProbeURL, if set, is an external URL that the canary
+	// controller probes in addition to the internal canary route.
+	CanaryExternalProbeURL string
+
+	// CanaryRouteTLSTermination, if set, overrides the canary route's TLS
+	// termination type ("edge" or "reencrypt") so the canary route
+	// matches shards that enforce a particular termination.
+	CanaryRouteTLSTermination string
+
+	// CanaryPlacementNodeRole, if set, is a node role (for example,
+	// "master") that the canary workload's pods are directed to, including
+	// a toleration for the taint conventionally applied to such nodes.
+	// Empty leaves the canary on the operand defaults.
+	CanaryPlacementNodeRole string
+
+	// CanaryRouteProbeCABundleFile, if set, is the path to a PEM CA
+	// bundle that the canary route probe uses to verify the route's
+	// serving certificate.  Empty skips certificate verification.
+	CanaryRouteProbeCABundleFile string
+
+	// ReconcileInterval is the interval of the operator's periodic ensure
+	// loop (for example, the default ingresscontroller existence check).
+	// Zero means the default of 1 minute; the minimum is 10 seconds.
+	ReconcileInterval time.Duration
+
+	// ReconcileJitter is the jitter factor applied to ReconcileInterval:
+	// each pass of the periodic ensure loop waits the interval plus a
+	// random fraction of it up to this factor.  Zero means the default of
+	// 0.1; the maximum is 1.0.
+	ReconcileJitter float64
+
+	// CacheSyncPeriod is the informer resync period of the operator's
+	// cache, which bounds how long state that drifted because of a missed
+	// watch event can go unrepaired.  Zero means the default of 24 hours;
+	// the minimum is 10 minutes.
+	CacheSyncPeriod time.Duration
+
+	// DNSResolutionCheckResolver, if set, is the "host:port" of a DNS
+	// resolver that the dns controller uses to verify that published
+	// records actually resolve.  Empty disables the check.
+	DNSResolutionCheckResolver string
+
+	// DNSResolutionCheckTimeout bounds each DNS resolution check attempt.
+	DNSResolutionCheckTimeout time.Duration
+
+	// DNSDeletionTimeout is how long the dns controller keeps retrying a
+	// failing DNS provider cleanup before giving up and removing the
+	// dnsrecord's finalizer.
+	DNSDeletionTimeout time.Duration
+
+	// DefaultPlacementNodeRole, if set, is a node role (for example,
+	// "infra") that the default ingresscontroller's router pods are
+	// directed to when the operator creates the default
+	// ingresscontroller.  Placement that a user has already set on the
+	// ingresscontroller is never overwritten.
+	DefaultPlacementNodeRole string
+
+	// DefaultReplicasNodesPerReplica, if positive, scales the default
+	// ingresscontroller's initial replica count with the cluster's worker
+	// node count: one replica per this many workers, rounded up, never
+	// below the topology baseline.  Zero disables scaling.
+	DefaultReplicasNodesPerReplica int
+
+	// DefaultReplicasMaximum, if positive, caps the scaled replica count.
+	DefaultReplicasMaximum int
+
+	// RouteMetricsChunkSize is the page size used when listing routes for
+	// route metrics.
+	RouteMetricsChunkSize int64
+
+	// MinimumTLSVersion, if set, is a cluster-wide floor on the minimum
+	// TLS version of the routers' effective TLS profiles (for example,
+	// "VersionTLS12").  Individual ingresscontrollers can tighten their
+	// profile beyond the floor but not loosen it.
+	MinimumTLSVersion string
+
+	// AdditionalOwnerReference, if set, names an object ("Kind/name" in
+	// the operand namespace) that is added as an additional owner of the
+	// router deployments the operator creates, so that a wrapper operator
+	// can tear them down by deleting the owner.
+	AdditionalOwnerReference string
+
+	// TrustedCASourceConfigMap, if set, names a configmap in the operator
+	// namespace whose ca-bundle.crt is appended to the injected bundle in
+	// the trusted CA configmap.
+	TrustedCASourceConfigMap string
+
+	// TrustedCAConfigMapAnnotations, if set, are annotations (for
+	// example, compliance metadata) that the operator enforces on the
+	// trusted CA configmap.  Annotations not named here are left alone.
+	TrustedCAConfigMapAnnotations map[string]string
+
+	// TrustedCAConfigMapLabels, if set, are labels that the operator
+	// enforces on the trusted CA configmap.  Labels not named here are
+	// left alone, and the injection label cannot be overridden.
+	TrustedCAConfigMapLabels map[string]string
+
+	// CertificateControllerMaxConcurrentReconciles is the maximum number
+	// of ingresscontrollers that the certificate controller reconciles
+	// concurrently.  Zero or less means 1.
+	CertificateControllerMaxConcurrentReconciles int
+
+	// AdditionalWatchNamespaces names namespaces that the operator's cache
+	// watches in addition to the built-in operator, operand, canary, and
+	// global-config namespaces, so that custom installs with routers in a
+	// non-default namespace still trigger reconciles.
+	AdditionalWatchNamespaces []string
+
+	// DisabledControllers names controllers that the operator does not run
+	// on any topology.  Only controllers that are optional (canary, dns,
+	// pod-health) may be named.
+	DisabledControllers []string
+
+	// ExternalTopologyDisabledControllers names controllers that the
+	// operator does not run when the cluster's control-plane topology is
+	// External.  Only controllers that are optional on External topology
+	// (canary, dns, pod-health) may be named.
+	ExternalTopologyDisabledControllers []string
+
 	Stop chan struct{}
 }
+
+// EffectiveConfig returns the operator's fully-resolved configuration as a
+// flat list of alternating keys and values suitable for structured logging.
+// Any future field that holds a credential must be redacted here rather than
+// included verbatim; no current field holds one.
+func (c Config) EffectiveConfig() []interface{} {
+	return []interface{}{
+		"operatorReleaseVersion", c.OperatorReleaseVersion,
+		"clientQPS", c.ClientQPS,
+		"clientBurst", c.ClientBurst,
+		"namespace", c.Namespace,
+		"ingressControllerImage", c.IngressControllerImage,
+		"canaryImage", c.CanaryImage,
+		"canaryExternalProbeURL", c.CanaryExternalProbeURL,
+		"canaryRouteTLSTermination", c.CanaryRouteTLSTermination,
+		"canaryPlacementNodeRole", c.CanaryPlacementNodeRole,
+		"canaryRouteProbeCABundleFile", c.CanaryRouteProbeCABundleFile,
+		"reconcileInterval", c.ReconcileInterval,
+		"reconcileJitter", c.ReconcileJitter,
+		"cacheSyncPeriod", c.CacheSyncPeriod.String(),
+		"dnsResolutionCheckResolver", c.DNSResolutionCheckResolver,
+		"dnsResolutionCheckTimeout", c.DNSResolutionCheckTimeout.String(),
+		"dnsDeletionTimeout", c.DNSDeletionTimeout.String(),
+		"defaultPlacementNodeRole", c.DefaultPlacementNodeRole,
+		"routeMetricsChunkSize", c.RouteMetricsChunkSize,
+		"defaultReplicasNodesPerReplica", c.DefaultReplicasNodesPerReplica,
+		"defaultReplicasMaximum", c.DefaultReplicasMaximum,
+		"minimumTLSVersion", c.MinimumTLSVersion,
+		"additionalOwnerReference", c.AdditionalOwnerReference,
+		"trustedCASourceConfigMap", c.TrustedCASourceConfigMap,
+		"trustedCAConfigMapAnnotations", joinMap(c.TrustedCAConfigMapAnnotations),
+		"trustedCAConfigMapLabels", joinMap(c.TrustedCAConfigMapLabels),
+		"certificateControllerMaxConcurrentReconciles", c.CertificateControllerMaxConcurrentReconciles,
+		"additionalWatchNamespaces", strings.Join(c.AdditionalWatchNamespaces, ","),
+		"disabledControllers", strings.Join(c.DisabledControllers, ","),
+		"externalTopologyDisabledControllers", strings.Join(c.ExternalTopologyDisabledControllers, ","),
+	}
+}
+
+// joinMap renders a map as a comma-separated, sorted list of key=value pairs
+// for logging.
+func joinMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for key, value := range m {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEffectiveConfigCoversAllFields verifies that EffectiveConfig stays in
+// sync with the Config struct so that newly added fields are not silently
+// omitted from the startup log.
+func TestEffectiveConfigCoversAllFields(t *testing.T) {
+	pairs := Config{}.EffectiveConfig()
+	if len(pairs)%2 != 0 {
+		t.Fatalf("EffectiveConfig returned an odd number of elements: %d", len(pairs))
+	}
+	keys := map[string]bool{}
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			t.Fatalf("EffectiveConfig key at index %d is not a string: %v", i, pairs[i])
+		}
+		if keys[key] {
+			t.Errorf("EffectiveConfig returned duplicate key %q", key)
+		}
+		keys[key] = true
+	}
+	// Every field except Stop should be represented by a key.
+	numFields := reflect.TypeOf(Config{}).NumField() - 1
+	if len(keys) != numFields {
+		t.Errorf("EffectiveConfig returned %d keys for %d config fields; update EffectiveConfig when adding fields", len(keys), numFields)
+	}
+}
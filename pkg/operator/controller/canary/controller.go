@@ -19,6 +19,8 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -52,11 +54,18 @@ const (
 	// Canary route rotation is enabled when the canary route rotation annotation has
 	// a value of "true" (disabled otherwise).
 	CanaryRouteRotationAnnotation = "ingress.operator.openshift.io/rotate-canary-route"
+
+	// CanaryDisabledAnnotation is an annotation on the default ingress
+	// controller that disables the canary check.  When the annotation has a
+	// value of "true", the canary controller tears down the canary
+	// daemonset, service, and route instead of reconciling them.
+	CanaryDisabledAnnotation = "ingress.operator.openshift.io/disable-canary"
 )
 
 var (
-	log              = logf.Logger.WithName(canaryControllerName)
-	routeProbeRunner sync.Once
+	log                 = logf.Logger.WithName(canaryControllerName)
+	routeProbeRunner    sync.Once
+	externalProbeRunner sync.Once
 )
 
 // New creates the canary controller.
@@ -67,6 +76,7 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 	reconciler := &reconciler{
 		config:                    config,
 		client:                    mgr.GetClient(),
+		recorder:                  mgr.GetEventRecorderFor(canaryControllerName),
 		enableCanaryRouteRotation: false,
 	}
 	c, err := controller.New(canaryControllerName, mgr, controller.Options{Reconciler: reconciler})
@@ -132,6 +142,28 @@ func enqueueRequestForDefaultIngressController(namespace string) handler.EventHa
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	result := reconcile.Result{}
 
+	// Tear down the canary resources if the canary check is disabled on the
+	// default ingress controller.
+	ic := &operatorv1.IngressController{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
+		return result, fmt.Errorf("failed to get ingress controller %s: %v", request.NamespacedName.Name, err)
+	}
+	if val, ok := ic.Annotations[CanaryDisabledAnnotation]; ok {
+		if disabled, _ := strconv.ParseBool(val); disabled {
+			removed, err := r.ensureCanaryTeardown()
+			if err != nil {
+				return result, fmt.Errorf("failed to tear down canary resources: %v", err)
+			}
+			// Only report a removal on the pass that actually deleted
+			// something; subsequent reconciles while the annotation is
+			// set find nothing to remove.
+			if removed {
+				r.recorder.Eventf(ic, "Normal", "CanaryDisabled", "Removed canary resources because the canary check is disabled")
+			}
+			return result, nil
+		}
+	}
+
 	if _, _, err := r.ensureCanaryNamespace(); err != nil {
 		// Return if the canary namespace cannot be created since
 		// resource creation in a namespace that does not exist will fail.
@@ -170,11 +202,6 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 
 	// Get the canary route rotation annotation value
 	// from the default ingress controller.
-	ic := &operatorv1.IngressController{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
-		return result, fmt.Errorf("failed to get ingress controller %s: %v", request.NamespacedName.Name, err)
-	}
-
 	if val, ok := ic.Annotations[CanaryRouteRotationAnnotation]; ok {
 		v, _ := strconv.ParseBool(val)
 		r.mu.Lock()
@@ -186,7 +213,16 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 	// has been admitted.
 	if checkRouteAdmitted(route) {
 		routeProbeRunner.Do(func() {
-			r.startCanaryRoutePolling(r.config.Stop)
+			if err := r.startCanaryRoutePolling(r.config.Stop); err != nil {
+				log.Error(err, "failed to start canary route polling")
+			}
+		})
+	}
+
+	// Start probing the configured external endpoint, if any.
+	if len(r.config.ExternalProbeURL) != 0 {
+		externalProbeRunner.Do(func() {
+			r.startExternalEndpointPolling(r.config.Stop)
 		})
 	}
 
@@ -198,6 +234,35 @@ type Config struct {
 	Namespace   string
 	CanaryImage string
 	Stop        chan struct{}
+	// NodeSelector, if set, overrides the canary workload's node selector
+	// so the canary pods can be confined to specific nodes.
+	NodeSelector map[string]string
+	// Tolerations, if set, overrides the canary workload's tolerations.
+	Tolerations []corev1.Toleration
+	// Resources, if set, overrides the canary container's resource
+	// requests and limits.
+	Resources *corev1.ResourceRequirements
+	// ExternalProbeURL, if set, is an external URL that the canary
+	// controller probes in addition to the internal canary route.  Probing
+	// an external URL goes through the actual load-balancer path and so
+	// catches external DNS and load-balancer reachability problems that
+	// the internal probe misses.
+	ExternalProbeURL string
+	// ExternalProbeTimeout is the timeout for each external probe request.
+	// If zero, a default of 10 seconds is used.
+	ExternalProbeTimeout time.Duration
+	// ExternalProbeInsecureTLS indicates whether TLS certificate
+	// verification is skipped for the external probe.
+	ExternalProbeInsecureTLS bool
+	// RouteTLSTermination, if set, overrides the canary route's TLS
+	// termination type so the canary route matches shards that enforce a
+	// particular termination (for example, reencrypt).
+	RouteTLSTermination routev1.TLSTerminationType
+	// RouteProbeCABundleFile, if set, is the path to a PEM CA bundle
+	// that the canary route probe uses to verify the route's serving
+	// certificate.  When unset, certificate verification is skipped
+	// because the default router certificate may be self signed.
+	RouteProbeCABundleFile string
 }
 
 // reconciler handles the actual canary reconciliation logic in response to
@@ -205,12 +270,19 @@ type Config struct {
 type reconciler struct {
 	config Config
 
-	client client.Client
+	client   client.Client
+	recorder record.EventRecorder
 
 	// Use a mutex so enableCanaryRotation is
 	// go-routine safe.
 	mu                        sync.Mutex
 	enableCanaryRouteRotation bool
+
+	// lastProbeFailureCategory and lastProbeFailureEventTime de-duplicate
+	// the Warning events emitted for canary probe failures.  They are only
+	// accessed from the polling goroutine.
+	lastProbeFailureCategory  string
+	lastProbeFailureEventTime time.Time
 }
 
 func (r *reconciler) isCanaryRouteRotationEnabled() bool {
@@ -229,6 +301,13 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 	// for status reporting.
 	successiveFail := 0
 
+	// Build the probe's TLS configuration once; the CA bundle is part of
+	// the operator's static configuration and does not change at runtime.
+	tlsConfig, err := routeProbeTLSConfig(r.config.RouteProbeCABundleFile)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS for the canary route probe: %v", err)
+	}
+
 	go wait.Until(func() {
 		// Get the current canary route every iteration in case it has been modified
 		haveRoute, route, err := r.currentCanaryRoute()
@@ -264,9 +343,10 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 			return
 		}
 
-		err = probeRouteEndpoint(route)
+		err = probeRouteEndpoint(route, tlsConfig)
 		if err != nil {
 			log.Error(err, "error performing canary route check")
+			r.recordProbeFailureEvent(route, err)
 			SetCanaryRouteReachableMetric(route.Spec.Host, false)
 			successiveFail += 1
 			// Mark the default ingress controller degraded after 5 successive canary check failures
@@ -279,6 +359,7 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 		}
 
 		SetCanaryRouteReachableMetric(route.Spec.Host, true)
+		r.resetProbeFailureTracking()
 		if err := r.setCanaryPassingStatusCondition(); err != nil {
 			log.Error(err, "error updating canary status condition")
 		}
@@ -294,6 +375,63 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 	return nil
 }
 
+// startExternalEndpointPolling periodically probes the configured external
+// endpoint.  The external probe has its own status condition and metric,
+// separate from the internal canary route probe.
+func (r *reconciler) startExternalEndpointPolling(stop <-chan struct{}) {
+	// Keep track of successive external probe failures
+	// for status reporting.
+	successiveFail := 0
+
+	timeout := r.config.ExternalProbeTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	go wait.Until(func() {
+		if err := probeExternalEndpoint(r.config.ExternalProbeURL, timeout, r.config.ExternalProbeInsecureTLS); err != nil {
+			log.Error(err, "error performing external endpoint check")
+			SetCanaryExternalEndpointReachableMetric(r.config.ExternalProbeURL, false)
+			successiveFail += 1
+			// Mark the default ingress controller degraded after 5 successive external probe failures
+			if successiveFail >= canaryCheckFailureCount {
+				if err := r.setExternalEndpointFailingStatusCondition(); err != nil {
+					log.Error(err, "error updating external endpoint status condition")
+				}
+			}
+			return
+		}
+
+		SetCanaryExternalEndpointReachableMetric(r.config.ExternalProbeURL, true)
+		if err := r.setExternalEndpointPassingStatusCondition(); err != nil {
+			log.Error(err, "error updating external endpoint status condition")
+		}
+		successiveFail = 0
+	}, canaryCheckFrequency, stop)
+}
+
+func (r *reconciler) setExternalEndpointFailingStatusCondition() error {
+	cond := operatorv1.OperatorCondition{
+		Type:    ingresscontroller.IngressControllerExternalEndpointCheckSuccessConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "ExternalEndpointChecksRepetitiveFailures",
+		Message: fmt.Sprintf("External endpoint checks against %q are failing", r.config.ExternalProbeURL),
+	}
+
+	return r.setCanaryStatusCondition(cond)
+}
+
+func (r *reconciler) setExternalEndpointPassingStatusCondition() error {
+	cond := operatorv1.OperatorCondition{
+		Type:    ingresscontroller.IngressControllerExternalEndpointCheckSuccessConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ExternalEndpointChecksSucceeding",
+		Message: fmt.Sprintf("External endpoint checks against %q are successful", r.config.ExternalProbeURL),
+	}
+
+	return r.setCanaryStatusCondition(cond)
+}
+
 func (r *reconciler) setCanaryFailingStatusCondition() error {
 	cond := operatorv1.OperatorCondition{
 		Type:    ingresscontroller.IngressControllerCanaryCheckSuccessConditionType,
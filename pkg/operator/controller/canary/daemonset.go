@@ -17,7 +17,7 @@ import (
 
 // ensureCanaryDaemonSet ensures the canary daemonset exists
 func (r *reconciler) ensureCanaryDaemonSet() (bool, *appsv1.DaemonSet, error) {
-	desired := desiredCanaryDaemonSet(r.config.CanaryImage)
+	desired := desiredCanaryDaemonSet(r.config)
 	haveDs, current, err := r.currentCanaryDaemonSet()
 	if err != nil {
 		return false, nil, err
@@ -79,7 +79,7 @@ func (r *reconciler) updateCanaryDaemonSet(current, desired *appsv1.DaemonSet) (
 
 // desiredCanaryDaemonSet returns the desired canary daemonset read in
 // from manifests
-func desiredCanaryDaemonSet(canaryImage string) *appsv1.DaemonSet {
+func desiredCanaryDaemonSet(config Config) *appsv1.DaemonSet {
 	daemonset := manifests.CanaryDaemonSet()
 	name := controller.CanaryDaemonSetName()
 	daemonset.Name = name.Name
@@ -93,7 +93,20 @@ func desiredCanaryDaemonSet(canaryImage string) *appsv1.DaemonSet {
 	daemonset.Spec.Selector = controller.CanaryDaemonSetPodSelector(canaryControllerName)
 	daemonset.Spec.Template.Labels = controller.CanaryDaemonSetPodSelector(canaryControllerName).MatchLabels
 
-	daemonset.Spec.Template.Spec.Containers[0].Image = canaryImage
+	daemonset.Spec.Template.Spec.Containers[0].Image = config.CanaryImage
+
+	// Only override the manifest's placement and resources when the
+	// operator was configured with explicit values so that behavior is
+	// unchanged by default.
+	if len(config.NodeSelector) != 0 {
+		daemonset.Spec.Template.Spec.NodeSelector = config.NodeSelector
+	}
+	if len(config.Tolerations) != 0 {
+		daemonset.Spec.Template.Spec.Tolerations = config.Tolerations
+	}
+	if config.Resources != nil {
+		daemonset.Spec.Template.Spec.Containers[0].Resources = *config.Resources
+	}
 
 	return daemonset
 }
@@ -121,6 +134,12 @@ func canaryDaemonSetChanged(current, expected *appsv1.DaemonSet) (bool, *appsv1.
 		changed = true
 	}
 
+	if len(current.Spec.Template.Spec.Containers) > 0 && len(expected.Spec.Template.Spec.Containers) > 0 &&
+		!cmp.Equal(current.Spec.Template.Spec.Containers[0].Resources, expected.Spec.Template.Spec.Containers[0].Resources, cmpopts.EquateEmpty()) {
+		updated.Spec.Template.Spec.Containers[0].Resources = expected.Spec.Template.Spec.Containers[0].Resources
+		changed = true
+	}
+
 	if !changed {
 		return false, nil
 	}
@@ -10,13 +10,14 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestDesiredCanaryDaemonSet(t *testing.T) {
 	canaryImage := "openshift/hello-openshift:latest"
 
-	daemonset := desiredCanaryDaemonSet(canaryImage)
+	daemonset := desiredCanaryDaemonSet(Config{CanaryImage: canaryImage})
 
 	expectedDaemonSetName := controller.CanaryDaemonSetName()
 
@@ -126,7 +127,7 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		original := desiredCanaryDaemonSet("")
+		original := desiredCanaryDaemonSet(Config{})
 		mutated := original.DeepCopy()
 		tc.mutate(mutated)
 		if changed, updated := canaryDaemonSetChanged(original, mutated); changed != tc.expect {
@@ -138,3 +139,47 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestDesiredCanaryDaemonSetPlacementAndResources(t *testing.T) {
+	defaultDaemonset := desiredCanaryDaemonSet(Config{})
+
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("50m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+	}
+	nodeSelector := map[string]string{"node-role.kubernetes.io/infra": ""}
+	tolerations := []corev1.Toleration{{
+		Key:      "node-role.kubernetes.io/infra",
+		Operator: corev1.TolerationOpExists,
+	}}
+	daemonset := desiredCanaryDaemonSet(Config{
+		NodeSelector: nodeSelector,
+		Tolerations:  tolerations,
+		Resources:    resources,
+	})
+
+	if !cmp.Equal(daemonset.Spec.Template.Spec.NodeSelector, nodeSelector) {
+		t.Errorf("expected node selector %v, got %v", nodeSelector, daemonset.Spec.Template.Spec.NodeSelector)
+	}
+	if !cmp.Equal(daemonset.Spec.Template.Spec.Tolerations, tolerations) {
+		t.Errorf("expected tolerations %v, got %v", tolerations, daemonset.Spec.Template.Spec.Tolerations)
+	}
+	if !cmp.Equal(daemonset.Spec.Template.Spec.Containers[0].Resources, *resources) {
+		t.Errorf("expected resources %v, got %v", *resources, daemonset.Spec.Template.Spec.Containers[0].Resources)
+	}
+
+	// An unset config must leave the manifest's defaults untouched.
+	unconfigured := desiredCanaryDaemonSet(Config{})
+	if !cmp.Equal(unconfigured.Spec.Template.Spec, defaultDaemonset.Spec.Template.Spec) {
+		t.Error("expected unconfigured daemonset to match the manifest defaults")
+	}
+
+	// A resources change must be reconciled onto the current daemonset.
+	if changed, updated := canaryDaemonSetChanged(defaultDaemonset, daemonset); !changed {
+		t.Error("expected resources change to be detected")
+	} else if !cmp.Equal(updated.Spec.Template.Spec.Containers[0].Resources, *resources) {
+		t.Errorf("expected updated daemonset to have resources %v, got %v", *resources, updated.Spec.Template.Spec.Containers[0].Resources)
+	}
+}
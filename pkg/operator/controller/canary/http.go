@@ -2,6 +2,7 @@ package canary
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -20,9 +21,71 @@ const (
 	echoServerPortAckHeader = "x-request-port"
 )
 
-// probeRouteEndpoint probes the given route's host
-// and returns an error when applicable.
-func probeRouteEndpoint(route *routev1.Route) error {
+// probeExternalEndpoint probes the given external URL through the normal
+// load-balancer path and returns an error when the endpoint is unreachable
+// or returns an unexpected status code.  Unlike the canary route probe, no
+// assumptions are made about the response body since the target is not the
+// canary echo server.
+func probeExternalEndpoint(url string, timeout time.Duration, insecureTLS bool) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating external probe HTTP request for %q: %v", url, err)
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Use the cluster-wide proxy if it is available in the
+			// pod's environment.
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureTLS},
+		},
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		dnsErr := &net.DNSError{}
+		if errors.As(err, &dnsErr) {
+			return fmt.Errorf("error sending external probe HTTP request: DNS error: %v", err)
+		}
+		if os.IsTimeout(err) {
+			return fmt.Errorf("error sending external probe HTTP request: Timeout: %v", err)
+		}
+		return fmt.Errorf("error sending external probe HTTP request to %q: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("unexpected status code from external probe endpoint %q: %d", url, response.StatusCode)
+	}
+
+	return nil
+}
+
+// routeProbeTLSConfig returns the TLS client configuration for probing the
+// canary route.  When a CA bundle file is given, the returned config
+// verifies the route's serving certificate against it so that a wrong or
+// untrusted certificate fails the canary check.  When no CA bundle is
+// given, certificate verification is skipped because the default router
+// certificate may be self signed.  See
+// https://bugzilla.redhat.com/show_bug.cgi?id=1932401.
+func routeProbeTLSConfig(caBundleFile string) (*tls.Config, error) {
+	if len(caBundleFile) == 0 {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	pem, err := ioutil.ReadFile(caBundleFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading canary route probe CA bundle %q: %v", caBundleFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates could be parsed from canary route probe CA bundle %q", caBundleFile)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// probeRouteEndpoint probes the given route's host using the given TLS
+// client configuration and returns an error when applicable.
+func probeRouteEndpoint(route *routev1.Route, tlsConfig *tls.Config) error {
 	if len(route.Spec.Host) == 0 {
 		return fmt.Errorf("route.Spec.Host is empty, cannot test route")
 	}
@@ -48,37 +111,36 @@ func probeRouteEndpoint(route *routev1.Route) error {
 
 	// Send the HTTP request
 	timeout, _ := time.ParseDuration("10s")
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
 	client := &http.Client{
 		Timeout: timeout,
-		// The canary route uses edge termination and the
-		// default router certificate may be self signed, so
-		// skip certificate verification here. See
-		// https://bugzilla.redhat.com/show_bug.cgi?id=1932401.
-		// TODO: Add the router's certificate to the HTTP client
-		// so we can enable TLS verification.
 		Transport: &http.Transport{
 			// Use the cluster-wide proxy if it is available in the
 			// pod's environment.
 			Proxy:           http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 	response, err := client.Do(request)
 
 	if err != nil {
 		// Check if err is a DNS error
+		// Wrap with %w so that callers can still inspect the underlying
+		// error type, for example to categorize the failure.
 		dnsErr := &net.DNSError{}
 		if errors.As(err, &dnsErr) {
 			// Handle DNS error
 			CanaryRouteDNSError.WithLabelValues(route.Spec.Host, dnsErr.Server).Inc()
-			return fmt.Errorf("error sending canary HTTP request: DNS error: %v", err)
+			return fmt.Errorf("error sending canary HTTP request: DNS error: %w", err)
 		}
 		// Check if err is a timeout error
 		if os.IsTimeout(err) {
 			// Handle timeout error
-			return fmt.Errorf("error sending canary HTTP Request: Timeout: %v", err)
+			return fmt.Errorf("error sending canary HTTP Request: Timeout: %w", err)
 		}
-		return fmt.Errorf("error sending canary HTTP request to %q: %v", route.Spec.Host, err)
+		return fmt.Errorf("error sending canary HTTP request to %q: %w", route.Spec.Host, err)
 	}
 
 	// Close response body even if read fails
@@ -123,14 +185,14 @@ func probeRouteEndpoint(route *routev1.Route) error {
 		// Register total time in metrics (use milliseconds)
 		CanaryRequestTime.WithLabelValues(route.Spec.Host).Observe(float64(totalTime.Milliseconds()))
 	case 408:
-		return fmt.Errorf("status code %d: request timed out", status)
+		return httpStatusError{statusCode: status, detail: "request timed out"}
 	case 503:
-		return fmt.Errorf("status code %d: Canary route not available via router", status)
+		return httpStatusError{statusCode: status, detail: "Canary route not available via router"}
 	// TODO (sgreene):
 	// Add more specific status code checks, if any are missing.
 	// Also, use HTTP status code constants, if available.
 	default:
-		return fmt.Errorf("unexpected status code: %d", status)
+		return httpStatusError{statusCode: status}
 	}
 
 	return nil
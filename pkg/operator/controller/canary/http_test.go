@@ -0,0 +1,118 @@
+package canary
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestProbeExternalEndpoint(t *testing.T) {
+	testCases := []struct {
+		description string
+		statusCode  int
+		expectError bool
+	}{
+		{
+			description: "endpoint returning 200 passes",
+			statusCode:  http.StatusOK,
+		},
+		{
+			description: "endpoint returning 302 passes",
+			statusCode:  http.StatusFound,
+		},
+		{
+			description: "endpoint returning 503 fails",
+			statusCode:  http.StatusServiceUnavailable,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.statusCode)
+		}))
+		err := probeExternalEndpoint(server.URL, 10*time.Second, true)
+		server.Close()
+		switch {
+		case err != nil && !tc.expectError:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case err == nil && tc.expectError:
+			t.Errorf("%q: expected error, got none", tc.description)
+		}
+	}
+
+	// An unreachable endpoint must fail.
+	if err := probeExternalEndpoint("https://localhost:1", 1*time.Second, true); err == nil {
+		t.Error("expected error probing unreachable endpoint, got none")
+	}
+}
+
+// TestProbeRouteEndpointEdgeTermination exercises the canary probe against an
+// edge-terminated endpoint, both with verification disabled and with the
+// serving certificate's CA configured as the probe's trust bundle.
+func TestProbeRouteEndpointEdgeTermination(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080-tcp")
+		fmt.Fprint(w, "Hello OpenShift!")
+	}))
+	defer server.Close()
+
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: strings.TrimPrefix(server.URL, "https://"),
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("8080-tcp"),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination: routev1.TLSTerminationEdge,
+			},
+		},
+	}
+
+	// With no CA bundle configured, verification is skipped, so the
+	// server's self-signed certificate is accepted.
+	tlsConfig, err := routeProbeTLSConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error building probe TLS config: %v", err)
+	}
+	if err := probeRouteEndpoint(route, tlsConfig); err != nil {
+		t.Errorf("unexpected error probing edge route without verification: %v", err)
+	}
+
+	// With the server's certificate configured as the CA bundle, the
+	// probe verifies the serving certificate and still passes.
+	caBundleFile := filepath.Join(t.TempDir(), "ca-bundle.pem")
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caBundleFile, caBundle, 0644); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+	tlsConfig, err = routeProbeTLSConfig(caBundleFile)
+	if err != nil {
+		t.Fatalf("unexpected error building probe TLS config: %v", err)
+	}
+	if err := probeRouteEndpoint(route, tlsConfig); err != nil {
+		t.Errorf("unexpected error probing edge route with the serving CA trusted: %v", err)
+	}
+
+	// A trust bundle that does not include the serving CA must fail the
+	// probe.
+	if err := probeRouteEndpoint(route, &tls.Config{RootCAs: x509.NewCertPool()}); err == nil {
+		t.Error("expected certificate verification error probing edge route with an empty trust bundle, got none")
+	}
+
+	// An unreadable CA bundle is a configuration error.
+	if _, err := routeProbeTLSConfig(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected error building probe TLS config from a missing CA bundle, got none")
+	}
+}
@@ -35,6 +35,12 @@ var (
 			Help: "A counter tracking canary route DNS lookup errors",
 		}, []string{"host", "dnsServer"})
 
+	CanaryExternalEndpointReachable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingress_canary_external_endpoint_reachable",
+			Help: "A gauge set to 0 or 1 to signify whether or not the configured external probe endpoint is reachable",
+		}, []string{"host"})
+
 	// Populate prometheus collector.
 	// Individual metrics are stored as public variables
 	// so that metrics can be globally controlled.
@@ -43,9 +49,20 @@ var (
 		CanaryEndpointWrongPortEcho,
 		CanaryRouteReachable,
 		CanaryRouteDNSError,
+		CanaryExternalEndpointReachable,
 	}
 )
 
+// SetCanaryExternalEndpointReachableMetric is a wrapper function to
+// mark the external probe endpoint as either online or offline.
+func SetCanaryExternalEndpointReachableMetric(host string, status bool) {
+	if status {
+		CanaryExternalEndpointReachable.WithLabelValues(host).Set(1)
+	} else {
+		CanaryExternalEndpointReachable.WithLabelValues(host).Set(0)
+	}
+}
+
 // SetCanaryRouteMetric is a wrapper function to
 // mark the canary route as either online or offline.
 func SetCanaryRouteReachableMetric(host string, status bool) {
@@ -68,8 +85,10 @@ func registerCanaryMetrics() error {
 	return nil
 }
 
-// StartMetricsListener starts the metrics listener on addr.
-func StartMetricsListener(addr string, stopCh chan struct{}) {
+// StartMetricsListener starts the metrics listener on addr.  If certFile and
+// keyFile are both non-empty, the listener serves the metrics endpoint over
+// TLS using the given certificate and key.
+func StartMetricsListener(addr, certFile, keyFile string, stopCh chan struct{}) {
 	// These metrics get registered in controller-runtime's registry via an init in the internal/controller/metrics package.
 	// Unregister the controller-runtime metrics, so that we can combine the controller-runtime metric's registry
 	// with that of the ingress-operator. This shouldn't have any side effects, as long as no 2 metrics across
@@ -96,7 +115,13 @@ func StartMetricsListener(addr string, stopCh chan struct{}) {
 	s := http.Server{Addr: addr, Handler: mux}
 
 	go func() {
-		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if len(certFile) != 0 && len(keyFile) != 0 {
+			err = s.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			err = s.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Error(err, "metrics listener exited")
 		}
 	}()
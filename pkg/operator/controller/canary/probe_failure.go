@@ -0,0 +1,89 @@
+package canary
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// probeFailureEventInterval is how often a Warning event is emitted for a
+// persisting canary probe failure.  Repeated failures with the same category
+// within the interval do not produce additional events.
+const probeFailureEventInterval = 10 * time.Minute
+
+// Probe failure categories reported in Warning events so that `oc get events`
+// shows why the canary check is failing without digging through operator logs.
+const (
+	probeFailureDNSResolution = "DNSResolution"
+	probeFailureTimeout       = "Timeout"
+	probeFailureTLSHandshake  = "TLSHandshake"
+	probeFailureTCPConnect    = "TCPConnect"
+	probeFailureHTTPStatus    = "HTTPStatus"
+	probeFailureOther         = "Other"
+)
+
+// httpStatusError indicates that the canary endpoint responded with an
+// unexpected HTTP status code.
+type httpStatusError struct {
+	statusCode int
+	detail     string
+}
+
+func (e httpStatusError) Error() string {
+	if len(e.detail) != 0 {
+		return fmt.Sprintf("status code %d: %s", e.statusCode, e.detail)
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.statusCode)
+}
+
+// categorizeProbeFailure maps a canary probe error to a failure category.
+// The probe wraps transport errors with %w, so the underlying error types are
+// still visible here.
+func categorizeProbeFailure(err error) string {
+	dnsErr := &net.DNSError{}
+	if errors.As(err, &dnsErr) {
+		return probeFailureDNSResolution
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return probeFailureTimeout
+	}
+	recordHeaderErr := tls.RecordHeaderError{}
+	if errors.As(err, &recordHeaderErr) {
+		return probeFailureTLSHandshake
+	}
+	opErr := &net.OpError{}
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return probeFailureTCPConnect
+	}
+	statusErr := httpStatusError{}
+	if errors.As(err, &statusErr) {
+		return probeFailureHTTPStatus
+	}
+	return probeFailureOther
+}
+
+// recordProbeFailureEvent emits a Warning event describing the canary probe
+// failure.  While a failure with the same category persists, the event is
+// repeated only once per probeFailureEventInterval to avoid event spam.
+func (r *reconciler) recordProbeFailureEvent(route *routev1.Route, err error) {
+	category := categorizeProbeFailure(err)
+	now := time.Now()
+	if category == r.lastProbeFailureCategory && now.Sub(r.lastProbeFailureEventTime) < probeFailureEventInterval {
+		return
+	}
+	r.lastProbeFailureCategory = category
+	r.lastProbeFailureEventTime = now
+	r.recorder.Eventf(route, "Warning", "CanaryCheckFailed", "Canary probe of host %q failed (%s): %v", route.Spec.Host, category, err)
+}
+
+// resetProbeFailureTracking clears the probe failure de-duplication state so
+// that the next failure after a successful check emits an event immediately.
+func (r *reconciler) resetProbeFailureTracking() {
+	r.lastProbeFailureCategory = ""
+	r.lastProbeFailureEventTime = time.Time{}
+}
@@ -0,0 +1,119 @@
+package canary
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/client-go/tools/record"
+)
+
+// timeoutError implements net.Error with Timeout() == true, as returned for
+// probes that exceed the HTTP client timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "request timed out" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCategorizeProbeFailure(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		expected    string
+	}{
+		{
+			description: "DNS resolution failure",
+			err:         &url.Error{Op: "Get", URL: "https://canary.example.com", Err: &net.DNSError{Name: "canary.example.com", IsNotFound: true}},
+			expected:    probeFailureDNSResolution,
+		},
+		{
+			description: "timeout",
+			err:         fmt.Errorf("error sending canary HTTP Request: Timeout: %w", &url.Error{Op: "Get", URL: "https://canary.example.com", Err: timeoutError{}}),
+			expected:    probeFailureTimeout,
+		},
+		{
+			description: "TLS handshake failure",
+			err:         &url.Error{Op: "Get", URL: "https://canary.example.com", Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}},
+			expected:    probeFailureTLSHandshake,
+		},
+		{
+			description: "TCP connect failure",
+			err:         &url.Error{Op: "Get", URL: "https://canary.example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}},
+			expected:    probeFailureTCPConnect,
+		},
+		{
+			description: "unexpected HTTP status",
+			err:         httpStatusError{statusCode: 503, detail: "Canary route not available via router"},
+			expected:    probeFailureHTTPStatus,
+		},
+		{
+			description: "uncategorized failure",
+			err:         fmt.Errorf("expected canary response body to not be empty"),
+			expected:    probeFailureOther,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := categorizeProbeFailure(tc.err); actual != tc.expected {
+			t.Errorf("%q: expected category %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestRecordProbeFailureEventDeduplication(t *testing.T) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-canary", Name: "canary"},
+		Spec:       routev1.RouteSpec{Host: "canary.apps.example.com"},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &reconciler{recorder: recorder}
+	statusErr := httpStatusError{statusCode: 503}
+
+	countEvents := func() int {
+		count := 0
+		for {
+			select {
+			case <-recorder.Events:
+				count++
+			default:
+				return count
+			}
+		}
+	}
+
+	// The first failure emits an event; an identical failure within the
+	// de-duplication interval does not.
+	r.recordProbeFailureEvent(route, statusErr)
+	r.recordProbeFailureEvent(route, statusErr)
+	if count := countEvents(); count != 1 {
+		t.Errorf("expected 1 event for repeated identical failures, got %d", count)
+	}
+
+	// A failure with a different category emits immediately.
+	r.recordProbeFailureEvent(route, &url.Error{Op: "Get", URL: "https://canary.example.com", Err: &net.DNSError{Name: "canary.example.com"}})
+	if count := countEvents(); count != 1 {
+		t.Errorf("expected 1 event for a new failure category, got %d", count)
+	}
+
+	// After a successful check resets tracking, the same failure emits
+	// again.
+	r.resetProbeFailureTracking()
+	r.recordProbeFailureEvent(route, statusErr)
+	if count := countEvents(); count != 1 {
+		t.Errorf("expected 1 event after tracking reset, got %d", count)
+	}
+
+	// Once the de-duplication interval has passed, a persisting failure
+	// emits again.
+	r.lastProbeFailureEventTime = r.lastProbeFailureEventTime.Add(-2 * probeFailureEventInterval)
+	r.recordProbeFailureEvent(route, statusErr)
+	if count := countEvents(); count != 1 {
+		t.Errorf("expected 1 event after the event interval elapsed, got %d", count)
+	}
+}
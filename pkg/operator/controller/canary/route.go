@@ -18,7 +18,7 @@ import (
 
 // ensureCanaryRoute ensures the canary route exists
 func (r *reconciler) ensureCanaryRoute(service *corev1.Service) (bool, *routev1.Route, error) {
-	desired, err := desiredCanaryRoute(service)
+	desired, err := desiredCanaryRoute(service, r.config.RouteTLSTermination)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to build canary route: %v", err)
 	}
@@ -123,8 +123,10 @@ func canaryRouteChanged(current, expected *routev1.Route) (bool, *routev1.Route)
 }
 
 // desiredCanaryRoute returns the desired canary route read in
-// from manifests
-func desiredCanaryRoute(service *corev1.Service) (*routev1.Route, error) {
+// from manifests.  If termination is non-empty, it overrides the
+// manifest's TLS termination type so the canary route matches shards
+// that enforce a particular termination.
+func desiredCanaryRoute(service *corev1.Service, termination routev1.TLSTerminationType) (*routev1.Route, error) {
 	route := manifests.CanaryRoute()
 
 	name := controller.CanaryRouteName()
@@ -143,6 +145,10 @@ func desiredCanaryRoute(service *corev1.Service) (*routev1.Route, error) {
 
 	route.Spec.To.Name = controller.CanaryServiceName().Name
 
+	if len(termination) != 0 && route.Spec.TLS != nil {
+		route.Spec.TLS.Termination = termination
+	}
+
 	// Set spec.port.targetPort to the first port available in the canary service.
 	// The canary controller may toggle which targetPort the route targets
 	// to test > 1 endpoint, so it does not matter which port is selected as long
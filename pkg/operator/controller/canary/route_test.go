@@ -19,7 +19,7 @@ func TestDesiredCanaryRoute(t *testing.T) {
 		Name: "test",
 	}
 	service := desiredCanaryService(daemonsetRef)
-	route, err := desiredCanaryRoute(service)
+	route, err := desiredCanaryRoute(service, "")
 
 	if err != nil {
 		t.Fatalf("desiredCanaryService returned an error: %v", err)
@@ -83,6 +83,15 @@ func TestDesiredCanaryRoute(t *testing.T) {
 	if !cmp.Equal(route.Spec.TLS, expectedTLS) {
 		t.Errorf("expected route TLS config to be %v, but got %v", route.Spec.TLS, expectedTLS)
 	}
+
+	// A configured termination type overrides the manifest's.
+	route, err = desiredCanaryRoute(service, routev1.TLSTerminationReencrypt)
+	if err != nil {
+		t.Fatalf("desiredCanaryRoute returned an error: %v", err)
+	}
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination != routev1.TLSTerminationReencrypt {
+		t.Errorf("expected route TLS termination to be %q, but got %v", routev1.TLSTerminationReencrypt, route.Spec.TLS)
+	}
 }
 
 func TestCanaryRouteChanged(t *testing.T) {
@@ -127,7 +136,7 @@ func TestCanaryRouteChanged(t *testing.T) {
 	service := desiredCanaryService(daemonsetRef)
 
 	for _, tc := range testCases {
-		original, err := desiredCanaryRoute(service)
+		original, err := desiredCanaryRoute(service, "")
 		if err != nil {
 			t.Fatalf("desiredCanaryService returned an error: %v", err)
 		}
@@ -0,0 +1,76 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureCanaryTeardown removes the canary daemonset, service, and route and
+// returns whether anything was actually deleted.  The canary namespace is
+// left in place because other cluster components may reference it.  Only
+// objects that carry the operator's canary label are removed, so objects
+// that a user created in the canary namespace are left alone.
+func (r *reconciler) ensureCanaryTeardown() (bool, error) {
+	removed := false
+	if haveRoute, route, err := r.currentCanaryRoute(); err != nil {
+		return removed, err
+	} else if haveRoute {
+		deleted, err := deleteIfCanaryOwned(r.client, route, route.Labels)
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete canary route: %v", err)
+		}
+		removed = removed || deleted
+	}
+	if haveService, service, err := r.currentCanaryService(); err != nil {
+		return removed, err
+	} else if haveService {
+		deleted, err := deleteIfCanaryOwned(r.client, service, service.Labels)
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete canary service: %v", err)
+		}
+		removed = removed || deleted
+	}
+	if haveDs, daemonset, err := r.currentCanaryDaemonSet(); err != nil {
+		return removed, err
+	} else if haveDs {
+		deleted, err := deleteIfCanaryOwned(r.client, daemonset, daemonset.Labels)
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete canary daemonset: %v", err)
+		}
+		removed = removed || deleted
+	}
+	return removed, nil
+}
+
+// deleteIfCanaryOwned deletes the given object if it carries the operator's
+// canary label and returns whether the object was deleted.  Deletion uses
+// the default propagation policy so that garbage collection of dependents is
+// left to the API server.
+func deleteIfCanaryOwned(cl client.Client, obj runtime.Object, labels map[string]string) (bool, error) {
+	if labels[manifests.OwningIngressCanaryCheckLabel] != canaryControllerName {
+		return false, nil
+	}
+	if err := cl.Delete(context.TODO(), obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureCanaryResourcesRemoved removes any canary resources that a previous
+// run of the operator created.  The operator calls it at startup when the
+// canary is not enabled.
+func EnsureCanaryResourcesRemoved(cl client.Client) error {
+	r := &reconciler{client: cl}
+	_, err := r.ensureCanaryTeardown()
+	return err
+}
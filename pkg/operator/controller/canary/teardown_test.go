@@ -0,0 +1,131 @@
+package canary
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestEnsureCanaryTeardown(t *testing.T) {
+	daemonset := desiredCanaryDaemonSet(Config{CanaryImage: "canary-image"})
+	daemonsetRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "daemonset",
+		Name:       daemonset.Name,
+	}
+	service := desiredCanaryService(daemonsetRef)
+	route, err := desiredCanaryRoute(service, "")
+	if err != nil {
+		t.Fatalf("failed to build canary route: %v", err)
+	}
+	// An object in the canary namespace that the operator did not create
+	// must not be removed by the teardown.
+	userService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: service.Namespace,
+			Name:      "user-service",
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), daemonset, service, route, userService)
+	r := &reconciler{client: client}
+
+	removed, err := r.ensureCanaryTeardown()
+	if err != nil {
+		t.Fatalf("failed to tear down canary resources: %v", err)
+	}
+	if !removed {
+		t.Error("expected teardown to report that it removed resources")
+	}
+
+	if haveDs, _, err := r.currentCanaryDaemonSet(); err != nil {
+		t.Errorf("failed to get canary daemonset: %v", err)
+	} else if haveDs {
+		t.Error("expected canary daemonset to be deleted")
+	}
+	if haveService, _, err := r.currentCanaryService(); err != nil {
+		t.Errorf("failed to get canary service: %v", err)
+	} else if haveService {
+		t.Error("expected canary service to be deleted")
+	}
+	if haveRoute, _, err := r.currentCanaryRoute(); err != nil {
+		t.Errorf("failed to get canary route: %v", err)
+	} else if haveRoute {
+		t.Error("expected canary route to be deleted")
+	}
+
+	name := types.NamespacedName{Namespace: operatorcontroller.DefaultCanaryNamespace, Name: "user-service"}
+	if err := client.Get(context.TODO(), name, &corev1.Service{}); err != nil {
+		t.Errorf("expected user-created service to survive teardown: %v", err)
+	}
+
+	// Teardown is idempotent, and a pass that finds nothing to delete
+	// reports that nothing was removed.
+	if removed, err := r.ensureCanaryTeardown(); err != nil {
+		t.Errorf("expected second teardown to succeed: %v", err)
+	} else if removed {
+		t.Error("expected second teardown to report that nothing was removed")
+	}
+}
+
+// TestCanaryDisabledEventEmittedOncePerRemoval verifies that the
+// "CanaryDisabled" event is emitted only on the reconcile pass that actually
+// removes canary resources, not on every pass while the disable annotation is
+// set.
+func TestCanaryDisabledEventEmittedOncePerRemoval(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorcontroller.DefaultOperatorNamespace,
+			Name:      "default",
+			Annotations: map[string]string{
+				CanaryDisabledAnnotation: "true",
+			},
+		},
+	}
+	daemonset := desiredCanaryDaemonSet(Config{CanaryImage: "canary-image"})
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), ic, daemonset)
+	recorder := record.NewFakeRecorder(10)
+	r := &reconciler{
+		client:   client,
+		recorder: recorder,
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+	}
+
+	// The first reconcile deletes the daemonset and emits the event.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "CanaryDisabled") {
+			t.Fatalf("expected a CanaryDisabled event, got %q", event)
+		}
+	default:
+		t.Fatal("expected an event on the pass that removed canary resources")
+	}
+
+	// A second reconcile finds nothing to remove and stays quiet.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Fatalf("expected no event on a pass that removed nothing, got %q", event)
+	default:
+	}
+}
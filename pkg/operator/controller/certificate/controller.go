@@ -1,8 +1,8 @@
 // The certificate controller is responsible for:
 //
-//   1. Managing a CA for minting self-signed certs
-//   2. Managing self-signed certificates for any ingresscontrollers which require them
-//   3. Publishing the CA to `openshift-config-managed`
+//  1. Managing a CA for minting self-signed certs
+//  2. Managing self-signed certificates for any ingresscontrollers which require them
+//  3. Publishing the CA to `openshift-config-managed`
 package certificate
 
 import (
@@ -40,13 +40,16 @@ const (
 
 var log = logf.Logger.WithName(controllerName)
 
-func New(mgr manager.Manager, operatorNamespace string) (runtimecontroller.Controller, error) {
+func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, error) {
 	reconciler := &reconciler{
 		client:            mgr.GetClient(),
 		recorder:          mgr.GetEventRecorderFor(controllerName),
-		operatorNamespace: operatorNamespace,
+		operatorNamespace: config.Namespace,
 	}
-	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{
+		Reconciler:              reconciler,
+		MaxConcurrentReconciles: config.MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +59,19 @@ func New(mgr manager.Manager, operatorNamespace string) (runtimecontroller.Contr
 	return c, nil
 }
 
+// Config holds all the things necessary for the controller to run.
+type Config struct {
+	// Namespace is the operator namespace.
+	Namespace string
+	// MaxConcurrentReconciles is the maximum number of ingresscontrollers
+	// that the controller reconciles concurrently.  Zero or less means the
+	// controller-runtime default of 1.  Reconciles of different
+	// ingresscontrollers are independent: the reconciler holds no mutable
+	// state, per-ingresscontroller secret names cannot collide, and secret
+	// creation tolerates another reconcile having created the secret first.
+	MaxConcurrentReconciles int
+}
+
 type reconciler struct {
 	client            client.Client
 	recorder          record.EventRecorder
@@ -81,6 +97,11 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		}
 	} else if !ingresscontroller.IsStatusDomainSet(ingress) {
 		log.Info("ingresscontroller domain not set; reconciliation will be skipped", "request", request)
+	} else if ingresscontroller.DefaultCertificateIsUnmanaged(ingress) {
+		// The default certificate is provisioned out-of-band; do not
+		// generate, rotate, or publish a certificate for this
+		// ingresscontroller.
+		log.Info("default certificate management is disabled; reconciliation will be skipped", "ingresscontroller", ingress.Name)
 	} else {
 		deployment := &appsv1.Deployment{}
 		err = r.client.Get(context.TODO(), controller.RouterDeploymentName(ingress), deployment)
@@ -102,7 +123,23 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 				UID:        deployment.UID,
 				Controller: &trueVar,
 			}
-			if _, err := r.ensureDefaultCertificateForIngress(ca, deployment.Namespace, deploymentRef, ingress); err != nil {
+			fallback := false
+			if missing, err := r.userSpecifiedDefaultCertificateMissing(ingress, deployment.Namespace); err != nil {
+				errs = append(errs, fmt.Errorf("failed to check the referenced default certificate secret for %s: %v", ingress.Name, err))
+			} else {
+				if missing && defaultCertificateFallbackEnabled(ingress) {
+					fallback = true
+					// The controller does not watch secrets, so
+					// poll for the referenced secret to reappear.
+					if result.RequeueAfter == 0 {
+						result.RequeueAfter = 1 * time.Minute
+					}
+				}
+				if err := r.syncDefaultCertificateDegradedCondition(ingress, missing, fallback); err != nil {
+					errs = append(errs, fmt.Errorf("failed to sync the %s condition for %s: %v", defaultCertificateDegradedConditionType, ingress.Name, err))
+				}
+			}
+			if _, err := r.ensureDefaultCertificateForIngress(ca, deployment.Namespace, deploymentRef, ingress, fallback); err != nil {
 				errs = append(errs, fmt.Errorf("failed to ensure default cert for %s: %v", ingress.Name, err))
 			}
 		}
@@ -2,23 +2,53 @@ package certificate
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/openshift/library-go/pkg/crypto"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// DefaultCertificateFallbackAnnotation can be set to "true" on an
+	// ingresscontroller to have the operator fall back to serving an
+	// operator-generated default certificate if the secret referenced by
+	// spec.defaultCertificate is deleted.  Without the annotation, the
+	// operator reports the missing secret and retries until the secret
+	// reappears.
+	DefaultCertificateFallbackAnnotation = "ingress.operator.openshift.io/default-certificate-fallback"
+
+	// DefaultCertificateAdditionalSANsAnnotation can be set on an
+	// ingresscontroller to a comma-separated list of DNS names within the
+	// ingresscontroller's domain that the operator includes as additional
+	// subject alternative names when generating the default certificate.
+	// This covers hostnames, such as the domain apex, that the wildcard
+	// name does not.
+	DefaultCertificateAdditionalSANsAnnotation = "ingress.operator.openshift.io/default-certificate-additional-sans"
+
+	// defaultCertificateDegradedConditionType is the type of the status
+	// condition that reports whether the secret referenced by
+	// spec.defaultCertificate exists.
+	defaultCertificateDegradedConditionType = "DefaultCertificateDegraded"
 )
 
 // ensureDefaultCertificateForIngress creates or deletes an operator-generated
 // default certificate for a given IngressController as appropriate.  Returns true
 // if it the secret exists, or false if it does not, as well as any errors.
-func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController) (bool, error) {
+func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController, fallback bool) (bool, error) {
 	ca, err := crypto.GetCAFromBytes(caSecret.Data["tls.crt"], caSecret.Data["tls.key"])
 	if err != nil {
 		return false, fmt.Errorf("failed to get CA from secret %s/%s: %v", caSecret.Namespace, caSecret.Name, err)
@@ -27,6 +57,18 @@ func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret,
 	if err != nil {
 		return false, err
 	}
+	if !wantCert && fallback {
+		// The referenced default certificate secret is missing, and the
+		// ingresscontroller has opted into falling back to an
+		// operator-generated certificate, so generate one as if no
+		// certificate were specified.
+		withoutRef := ci.DeepCopy()
+		withoutRef.Spec.DefaultCertificate = nil
+		wantCert, desired, err = desiredRouterDefaultCertificateSecret(ca, namespace, deploymentRef, withoutRef)
+		if err != nil {
+			return false, err
+		}
+	}
 	if !wantCert {
 		// If the operator generated certificate is not being used, ensure that the ingress controller's
 		// Spec.DefaultCertificate secret exists before deleting the operator generated secret.
@@ -56,15 +98,99 @@ func (r *reconciler) ensureDefaultCertificateForIngress(caSecret *corev1.Secret,
 			return false, fmt.Errorf("failed to create default certificate: %v", err)
 		} else if created {
 			r.recorder.Eventf(ci, "Normal", "CreatedDefaultCertificate", "Created default wildcard certificate %q", desired.Name)
-			return true, nil
 		}
+		return true, nil
 	case wantCert && haveCert:
 		// TODO Update if CA certificate changed.
+		// If the ingress domain or the configured additional SANs changed
+		// after the certificate was generated, the certificate no longer
+		// matches the desired hostnames and must be regenerated.
+		hostnames := defaultCertificateHostnames(ci)
+		if matches, err := certificateCoversHostnames(current.Data["tls.crt"], hostnames); err != nil {
+			return true, fmt.Errorf("failed to check default certificate %s/%s against hostnames %v: %v", current.Namespace, current.Name, hostnames.List(), err)
+		} else if !matches {
+			updated := current.DeepCopy()
+			updated.Data = desired.Data
+			if err := r.client.Update(context.TODO(), updated); err != nil {
+				return true, fmt.Errorf("failed to update default certificate: %v", err)
+			}
+			r.recorder.Eventf(ci, "Normal", "UpdatedDefaultCertificate", "Regenerated default wildcard certificate %q for hostnames %v", current.Name, hostnames.List())
+		}
 		return true, nil
 	}
 	return false, nil
 }
 
+// additionalSANsFromAnnotation returns any additional subject alternative
+// names configured on the ingresscontroller.  Each name must be a DNS name
+// equal to or within the ingresscontroller's domain; if any name is invalid,
+// the entire annotation is ignored.
+func additionalSANsFromAnnotation(ci *operatorv1.IngressController) []string {
+	val, ok := ci.Annotations[DefaultCertificateAdditionalSANsAnnotation]
+	if !ok {
+		return nil
+	}
+	var sans []string
+	for _, name := range strings.Split(val, ",") {
+		name = strings.TrimSpace(name)
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) != 0 {
+			log.Info("ignoring invalid annotation value", "annotation", DefaultCertificateAdditionalSANsAnnotation, "value", val, "invalid name", name)
+			return nil
+		}
+		if name != ci.Status.Domain && !strings.HasSuffix(name, "."+ci.Status.Domain) {
+			log.Info("ignoring invalid annotation value", "annotation", DefaultCertificateAdditionalSANsAnnotation, "value", val, "invalid name", name, "reason", "name is outside the ingresscontroller domain")
+			return nil
+		}
+		sans = append(sans, name)
+	}
+	return sans
+}
+
+// defaultCertificateHostnames returns the set of hostnames that the
+// operator-generated default certificate for the given ingresscontroller must
+// cover: the wildcard name for the ingresscontroller's domain plus any
+// configured additional SANs.
+func defaultCertificateHostnames(ci *operatorv1.IngressController) sets.String {
+	hostnames := sets.NewString(fmt.Sprintf("*.%s", ci.Status.Domain))
+	hostnames.Insert(additionalSANsFromAnnotation(ci)...)
+	return hostnames
+}
+
+// certificateCoversHostnames returns true if the subject alternative names of
+// the leaf certificate in the given PEM-encoded certificate data are exactly
+// the given hostnames.
+func certificateCoversHostnames(pemData []byte, hostnames sets.String) (bool, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return false, fmt.Errorf("no PEM data found")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	return sets.NewString(certificate.DNSNames...).Equal(hostnames), nil
+}
+
+// certificateCoversDomain returns true if the leaf certificate in the given
+// PEM-encoded certificate data covers the wildcard name for the given domain.
+func certificateCoversDomain(pemData []byte, domain string) (bool, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return false, fmt.Errorf("no PEM data found")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	wildcard := fmt.Sprintf("*.%s", domain)
+	for _, name := range certificate.DNSNames {
+		if name == wildcard {
+			return true, nil
+		}
+	}
+	return certificate.Subject.CommonName == wildcard, nil
+}
+
 // desiredRouterDefaultCertificateSecret returns the desired default certificate
 // secret.
 func desiredRouterDefaultCertificateSecret(ca *crypto.CA, namespace string, deploymentRef metav1.OwnerReference, ci *operatorv1.IngressController) (bool, *corev1.Secret, error) {
@@ -82,7 +208,7 @@ func desiredRouterDefaultCertificateSecret(ca *crypto.CA, namespace string, depl
 		return false, nil, nil
 	}
 
-	hostnames := sets.NewString(fmt.Sprintf("*.%s", ci.Status.Domain))
+	hostnames := defaultCertificateHostnames(ci)
 	cert, err := ca.MakeServerCert(hostnames, 0)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to make certificate: %v", err)
@@ -123,9 +249,14 @@ func (r *reconciler) currentRouterDefaultCertificate(ci *operatorv1.IngressContr
 }
 
 // createRouterDefaultCertificate creates a router default certificate secret.
-// Returns true if the secret was newly created, otherwise returns false.
+// Returns true if the secret was newly created, otherwise returns false.  A
+// secret that already exists (for example, because a concurrent reconcile
+// created it) is not an error.
 func (r *reconciler) createRouterDefaultCertificate(secret *corev1.Secret) (bool, error) {
 	if err := r.client.Create(context.TODO(), secret); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		}
 		return false, err
 	}
 	return true, nil
@@ -143,6 +274,70 @@ func (r *reconciler) deleteRouterDefaultCertificate(secret *corev1.Secret) (bool
 	return true, nil
 }
 
+// defaultCertificateFallbackEnabled returns true if the ingresscontroller has
+// opted into falling back to an operator-generated default certificate when
+// the referenced default certificate secret is missing.
+func defaultCertificateFallbackEnabled(ci *operatorv1.IngressController) bool {
+	val, ok := ci.Annotations[DefaultCertificateFallbackAnnotation]
+	if !ok {
+		return false
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Info("ignoring invalid annotation value", "annotation", DefaultCertificateFallbackAnnotation, "value", val)
+		return false
+	}
+	return enabled
+}
+
+// userSpecifiedDefaultCertificateMissing returns true if the ingresscontroller
+// references a default certificate secret that does not exist.
+func (r *reconciler) userSpecifiedDefaultCertificateMissing(ci *operatorv1.IngressController, namespace string) (bool, error) {
+	if ci.Spec.DefaultCertificate == nil {
+		return false, nil
+	}
+	generatedName := controller.RouterOperatorGeneratedDefaultCertificateSecretName(ci, namespace)
+	if ci.Spec.DefaultCertificate.Name == generatedName.Name {
+		return false, nil
+	}
+	secret := &corev1.Secret{}
+	name := types.NamespacedName{Namespace: namespace, Name: ci.Spec.DefaultCertificate.Name}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// syncDefaultCertificateDegradedCondition sets or clears the status condition
+// that reports a missing referenced default certificate secret.
+func (r *reconciler) syncDefaultCertificateDegradedCondition(ci *operatorv1.IngressController, missing, fallback bool) error {
+	condition := operatorv1.OperatorCondition{
+		Type:    defaultCertificateDegradedConditionType,
+		Status:  operatorv1.ConditionFalse,
+		Reason:  "DefaultCertificateAvailable",
+		Message: "The default certificate secret is available.",
+	}
+	if missing {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ReferencedSecretNotFound"
+		condition.Message = fmt.Sprintf("The secret %q referenced by spec.defaultCertificate does not exist.", ci.Spec.DefaultCertificate.Name)
+		if fallback {
+			condition.Message = fmt.Sprintf("%s The router is serving an operator-generated fallback certificate.", condition.Message)
+		}
+	}
+	for _, cond := range ci.Status.Conditions {
+		if cond.Type == condition.Type && cond.Status == condition.Status && cond.Reason == condition.Reason && cond.Message == condition.Message {
+			return nil
+		}
+	}
+	updated := ci.DeepCopy()
+	updated.Status.Conditions = ingresscontroller.MergeConditions(updated.Status.Conditions, condition)
+	return r.client.Status().Update(context.TODO(), updated)
+}
+
 // lookupUserSpecifiedRouterDefaultCertificate checks to see if the given ingress controller's
 // Spec.DefaultCertificate field corresponds to an existing secret. This function assumes that
 // ci.Spec.DefaultCertificate is not nil.
@@ -1,14 +1,32 @@
 package certificate
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/openshift/library-go/pkg/crypto"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -134,3 +152,390 @@ func TestDesiredRouterDefaultCertificateSecret(t *testing.T) {
 		}
 	}
 }
+
+// TestEnsureDefaultCertificateRegeneratedOnDomainChange verifies that the
+// operator-generated default certificate is regenerated when the
+// ingresscontroller's domain changes so that the certificate no longer covers
+// it.
+func TestEnsureDefaultCertificateRegeneratedOnDomainChange(t *testing.T) {
+	caSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-namespace",
+			Name:      "router-ca",
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte(cert),
+			"tls.key": []byte(key),
+		},
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.old.example.com",
+		},
+	}
+	deploymentRef := metav1.OwnerReference{Name: "test-ref"}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme())
+	r := &reconciler{
+		client:   client,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	// The first sync generates a certificate for the original domain.
+	if have, err := r.ensureDefaultCertificateForIngress(caSecret, "test-namespace", deploymentRef, ic, false); err != nil {
+		t.Fatalf("failed to ensure default certificate: %v", err)
+	} else if !have {
+		t.Fatal("expected default certificate to be created")
+	}
+	_, original, err := r.currentRouterDefaultCertificate(ic, "test-namespace")
+	if err != nil {
+		t.Fatalf("failed to get default certificate: %v", err)
+	}
+	if covers, err := certificateCoversDomain(original.Data["tls.crt"], "apps.old.example.com"); err != nil || !covers {
+		t.Fatalf("expected generated certificate to cover the original domain: covers=%v, err=%v", covers, err)
+	}
+
+	// Simulate a domain change and verify that the certificate is
+	// regenerated for the new domain.
+	ic.Status.Domain = "apps.new.example.com"
+	if have, err := r.ensureDefaultCertificateForIngress(caSecret, "test-namespace", deploymentRef, ic, false); err != nil {
+		t.Fatalf("failed to ensure default certificate after domain change: %v", err)
+	} else if !have {
+		t.Fatal("expected default certificate to exist after domain change")
+	}
+	_, regenerated, err := r.currentRouterDefaultCertificate(ic, "test-namespace")
+	if err != nil {
+		t.Fatalf("failed to get regenerated default certificate: %v", err)
+	}
+	if covers, err := certificateCoversDomain(regenerated.Data["tls.crt"], "apps.new.example.com"); err != nil || !covers {
+		t.Fatalf("expected regenerated certificate to cover the new domain: covers=%v, err=%v", covers, err)
+	}
+
+	// A sync with an unchanged domain must not regenerate the certificate.
+	if _, err := r.ensureDefaultCertificateForIngress(caSecret, "test-namespace", deploymentRef, ic, false); err != nil {
+		t.Fatalf("failed to re-ensure default certificate: %v", err)
+	}
+	_, unchanged, err := r.currentRouterDefaultCertificate(ic, "test-namespace")
+	if err != nil {
+		t.Fatalf("failed to get default certificate: %v", err)
+	}
+	if string(unchanged.Data["tls.crt"]) != string(regenerated.Data["tls.crt"]) {
+		t.Error("expected certificate to be unchanged when the domain did not change")
+	}
+}
+
+func TestNoDefaultCertificateGeneratedWhenUnmanaged(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "test",
+			Annotations: map[string]string{
+				ingresscontroller.UnmanagedDefaultCertificateAnnotation: "true",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorcontroller.DefaultOperandNamespace,
+			Name:      "router-test",
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), ic, deployment)
+	r := &reconciler{
+		client:            client,
+		recorder:          record.NewFakeRecorder(10),
+		operatorNamespace: "openshift-ingress-operator",
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	secretName := operatorcontroller.RouterOperatorGeneratedDefaultCertificateSecretName(ic, operatorcontroller.DefaultOperandNamespace)
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), secretName, secret); err == nil {
+		t.Fatalf("expected no default certificate secret to be generated, found %s", secretName)
+	} else if !errors.IsNotFound(err) {
+		t.Fatalf("failed to get secret %s: %v", secretName, err)
+	}
+
+	// With management enabled, the same reconcile generates a certificate.
+	ic.Annotations = nil
+	if err := client.Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if err := client.Get(context.TODO(), secretName, secret); err != nil {
+		t.Fatalf("expected a default certificate secret to be generated: %v", err)
+	}
+}
+
+// TestDefaultCertificateFallbackOnDeletedSecret verifies that deleting the
+// secret referenced by spec.defaultCertificate causes the operator to report
+// the missing secret and, with fallback enabled, to generate a fallback
+// certificate, and that recreating the secret restores the original state.
+func TestDefaultCertificateFallbackOnDeletedSecret(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "test",
+			Annotations: map[string]string{
+				DefaultCertificateFallbackAnnotation: "true",
+			},
+		},
+		Spec: operatorv1.IngressControllerSpec{
+			DefaultCertificate: &corev1.LocalObjectReference{Name: "custom-cert"},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.example.com",
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorcontroller.DefaultOperandNamespace,
+			Name:      "router-test",
+		},
+	}
+	customSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: operatorcontroller.DefaultOperandNamespace,
+			Name:      "custom-cert",
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), ic, deployment, customSecret)
+	r := &reconciler{
+		client:            client,
+		recorder:          record.NewFakeRecorder(10),
+		operatorNamespace: "openshift-ingress-operator",
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+	}
+	condition := func() *operatorv1.OperatorCondition {
+		updated := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), request.NamespacedName, updated); err != nil {
+			t.Fatalf("failed to get ingresscontroller: %v", err)
+		}
+		for i, cond := range updated.Status.Conditions {
+			if cond.Type == defaultCertificateDegradedConditionType {
+				return &updated.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+	secretName := operatorcontroller.RouterOperatorGeneratedDefaultCertificateSecretName(ic, operatorcontroller.DefaultOperandNamespace)
+	generatedSecret := &corev1.Secret{}
+
+	// While the referenced secret exists, no fallback certificate is
+	// generated, and the condition is clear.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if err := client.Get(context.TODO(), secretName, generatedSecret); !errors.IsNotFound(err) {
+		t.Fatalf("expected no fallback certificate secret while the referenced secret exists, got error %v", err)
+	}
+	if cond := condition(); cond == nil || cond.Status != operatorv1.ConditionFalse {
+		t.Fatalf("expected condition %s to be False, got %+v", defaultCertificateDegradedConditionType, cond)
+	}
+
+	// Deleting the referenced secret causes a fallback certificate to be
+	// generated and the condition to be set.
+	if err := client.Delete(context.TODO(), customSecret); err != nil {
+		t.Fatalf("failed to delete secret: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if err := client.Get(context.TODO(), secretName, generatedSecret); err != nil {
+		t.Fatalf("expected a fallback certificate secret to be generated: %v", err)
+	}
+	if cond := condition(); cond == nil || cond.Status != operatorv1.ConditionTrue || cond.Reason != "ReferencedSecretNotFound" {
+		t.Fatalf("expected condition %s to be True with reason ReferencedSecretNotFound, got %+v", defaultCertificateDegradedConditionType, cond)
+	}
+
+	// Recreating the referenced secret clears the condition and removes
+	// the fallback certificate.
+	customSecret.ResourceVersion = ""
+	if err := client.Create(context.TODO(), customSecret); err != nil {
+		t.Fatalf("failed to recreate secret: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if err := client.Get(context.TODO(), secretName, generatedSecret); !errors.IsNotFound(err) {
+		t.Fatalf("expected the fallback certificate secret to be deleted, got error %v", err)
+	}
+	if cond := condition(); cond == nil || cond.Status != operatorv1.ConditionFalse {
+		t.Fatalf("expected condition %s to be False, got %+v", defaultCertificateDegradedConditionType, cond)
+	}
+}
+
+// TestEnsureDefaultCertificateConcurrent verifies that concurrent reconciles of
+// different ingresscontrollers generate distinct certificate secrets without
+// colliding and that generation is idempotent: once a certificate exists,
+// further reconciles leave it unchanged.
+func TestEnsureDefaultCertificateConcurrent(t *testing.T) {
+	const numControllers = 4
+
+	objs := []runtime.Object{}
+	requests := []reconcile.Request{}
+	for i := 0; i < numControllers; i++ {
+		name := fmt.Sprintf("concurrent-%d", i)
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      name,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: fmt.Sprintf("apps-%d.example.com", i),
+			},
+		}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: operatorcontroller.DefaultOperandNamespace,
+				Name:      "router-" + name,
+			},
+		}
+		objs = append(objs, ic, deployment)
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name},
+		})
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...)
+	r := &reconciler{
+		client:            client,
+		recorder:          record.NewFakeRecorder(100),
+		operatorNamespace: "openshift-ingress-operator",
+	}
+
+	// Reconcile every ingresscontroller from several goroutines at once.
+	var wg sync.WaitGroup
+	errs := make(chan error, numControllers*4*2)
+	for _, request := range requests {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(request reconcile.Request) {
+				defer wg.Done()
+				for j := 0; j < 2; j++ {
+					if _, err := r.Reconcile(request); err != nil {
+						errs <- fmt.Errorf("reconcile %s failed: %v", request.NamespacedName, err)
+					}
+				}
+			}(request)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Every ingresscontroller must have its own generated secret.
+	secretNames := map[string]struct{}{}
+	secretData := map[string][]byte{}
+	for i, request := range requests {
+		ic := &operatorv1.IngressController{}
+		if err := client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
+			t.Fatalf("failed to get ingresscontroller %s: %v", request.NamespacedName, err)
+		}
+		secretName := operatorcontroller.RouterOperatorGeneratedDefaultCertificateSecretName(ic, operatorcontroller.DefaultOperandNamespace)
+		secret := &corev1.Secret{}
+		if err := client.Get(context.TODO(), secretName, secret); err != nil {
+			t.Fatalf("expected a generated certificate secret for ingresscontroller %d: %v", i, err)
+		}
+		secretNames[secretName.Name] = struct{}{}
+		secretData[secretName.Name] = secret.Data["tls.crt"]
+	}
+	if len(secretNames) != numControllers {
+		t.Fatalf("expected %d distinct certificate secrets, got %d: %v", numControllers, len(secretNames), secretNames)
+	}
+
+	// Reconciling again must not regenerate any certificate.
+	for _, request := range requests {
+		if _, err := r.Reconcile(request); err != nil {
+			t.Fatalf("reconcile %s failed: %v", request.NamespacedName, err)
+		}
+	}
+	for name, data := range secretData {
+		secret := &corev1.Secret{}
+		secretName := types.NamespacedName{Namespace: operatorcontroller.DefaultOperandNamespace, Name: name}
+		if err := client.Get(context.TODO(), secretName, secret); err != nil {
+			t.Fatalf("failed to get secret %s: %v", secretName, err)
+		}
+		if string(secret.Data["tls.crt"]) != string(data) {
+			t.Errorf("expected certificate secret %s to be unchanged by a repeated reconcile", name)
+		}
+	}
+}
+
+// TestDefaultCertificateAdditionalSANs verifies that additional SANs
+// configured on the ingresscontroller are included in the generated default
+// certificate and that invalid or out-of-domain names are ignored.
+func TestDefaultCertificateAdditionalSANs(t *testing.T) {
+	ca, err := crypto.GetCAFromBytes([]byte(cert), []byte(key))
+	if err != nil {
+		t.Fatalf("failed to create CA")
+	}
+
+	testCases := []struct {
+		description       string
+		annotation        string
+		expectedHostnames []string
+	}{
+		{
+			description:       "no annotation",
+			expectedHostnames: []string{"*.apps.example.com"},
+		},
+		{
+			description:       "domain apex and a subdomain",
+			annotation:        "apps.example.com, portal.apps.example.com",
+			expectedHostnames: []string{"*.apps.example.com", "apps.example.com", "portal.apps.example.com"},
+		},
+		{
+			description:       "name outside the domain is ignored",
+			annotation:        "example.com",
+			expectedHostnames: []string{"*.apps.example.com"},
+		},
+		{
+			description:       "invalid DNS name is ignored",
+			annotation:        "not a dns name",
+			expectedHostnames: []string{"*.apps.example.com"},
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.example.com",
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{DefaultCertificateAdditionalSANsAnnotation: tc.annotation}
+		}
+		wantCert, secret, err := desiredRouterDefaultCertificateSecret(ca, "test-namespace", metav1.OwnerReference{Name: "test-ref"}, ic)
+		if err != nil {
+			t.Fatalf("%q: failed to generate default certificate: %v", tc.description, err)
+		}
+		if !wantCert {
+			t.Fatalf("%q: expected a default certificate", tc.description)
+		}
+		if matches, err := certificateCoversHostnames(secret.Data["tls.crt"], sets.NewString(tc.expectedHostnames...)); err != nil {
+			t.Fatalf("%q: failed to check generated certificate: %v", tc.description, err)
+		} else if !matches {
+			t.Errorf("%q: expected the generated certificate to have exactly the SANs %v", tc.description, tc.expectedHostnames)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package dns
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -60,16 +62,34 @@ const (
 	kubeCloudConfigName = "kube-cloud-config"
 	// cloudCABundleKey is the key in the kube cloud config ConfigMap where the custom CA bundle is located
 	cloudCABundleKey = "ca-bundle.pem"
+
+	// dnsRecordResolvableConditionType is a condition type that reports
+	// whether a published DNS record actually resolves to its expected
+	// target.  The condition is only managed when the resolution check is
+	// enabled.
+	dnsRecordResolvableConditionType = "Resolvable"
+
+	// defaultResolutionCheckTimeout bounds a single resolution attempt
+	// when no timeout is configured.
+	defaultResolutionCheckTimeout = 5 * time.Second
+
+	// maxResolutionCheckRetries is how many times a record is rechecked
+	// for resolution before the controller stops requeueing it.
+	maxResolutionCheckRetries = 10
 )
 
 var log = logf.Logger.WithName(controllerName)
 
 func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, error) {
+	if config.DeletionTimeout < 0 {
+		return nil, fmt.Errorf("invalid deletion timeout: %s", config.DeletionTimeout)
+	}
 	reconciler := &reconciler{
-		config:   config,
-		client:   mgr.GetClient(),
-		cache:    mgr.GetCache(),
-		recorder: mgr.GetEventRecorderFor(controllerName),
+		config:            config,
+		client:            mgr.GetClient(),
+		cache:             mgr.GetCache(),
+		recorder:          mgr.GetEventRecorderFor(controllerName),
+		resolutionRetries: map[types.NamespacedName]int{},
 	}
 	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
 	if err != nil {
@@ -78,7 +98,9 @@ func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, erro
 	if err := c.Watch(&source.Kind{Type: &iov1.DNSRecord{}}, &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{}); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &configv1.DNS{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ToDNSRecords)}); err != nil {
+	// The DNS config's status does not affect desired state, so ignore
+	// status-only updates.
+	if err := c.Watch(&source.Kind{Type: &configv1.DNS{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ToDNSRecords)}, predicate.GenerationChangedPredicate{}); err != nil {
 		return nil, err
 	}
 	if err := c.Watch(&source.Kind{Type: &configv1.Infrastructure{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ToDNSRecords)}); err != nil {
@@ -99,6 +121,21 @@ func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, erro
 	}); err != nil {
 		return nil, err
 	}
+	// The DNS zone and target override annotations live on the
+	// ingresscontroller, and the DNSRecord watch filters out
+	// annotation-only updates, so watch ingresscontrollers and enqueue
+	// their own dnsrecords when one of those annotations is added,
+	// changed, or removed.
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ingressControllerToDNSRecords)}, predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return false },
+		DeleteFunc: func(e event.DeleteEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return dnsAnnotationsChanged(e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations())
+		},
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -106,6 +143,19 @@ func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, erro
 type Config struct {
 	Namespace              string
 	OperatorReleaseVersion string
+	// ResolutionCheckResolver, if set, is the "host:port" of a DNS
+	// resolver against which published records are verified to resolve.
+	// Setting it enables the post-publish resolution check.
+	ResolutionCheckResolver string
+	// ResolutionCheckTimeout bounds each resolution attempt.  If zero, a
+	// default of 5 seconds is used.
+	ResolutionCheckTimeout time.Duration
+	// DeletionTimeout is how long after a dnsrecord's deletion timestamp
+	// the reconciler keeps retrying the DNS provider cleanup before giving
+	// up and removing the finalizer, so an unreachable cloud API cannot
+	// block the owning ingresscontroller's deletion forever.  If zero, a
+	// default of 10 minutes is used; negative values are invalid.
+	DeletionTimeout time.Duration
 }
 
 type reconciler struct {
@@ -117,6 +167,13 @@ type reconciler struct {
 	infraConfig      *configv1.Infrastructure
 	cloudCredentials *corev1.Secret
 	recorder         record.EventRecorder
+
+	// resolve checks whether a record resolves to its expected target.
+	// It exists as a field so tests can stub out actual DNS resolution.
+	resolve func(*iov1.DNSRecord) (bool, error)
+	// resolutionRetries tracks how many times each record has been
+	// rechecked for resolution, to bound retries.
+	resolutionRetries map[types.NamespacedName]int
 }
 
 func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -167,7 +224,8 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		return reconcile.Result{}, nil
 	}
 
-	if err := r.cache.Get(context.TODO(), types.NamespacedName{Namespace: record.Namespace, Name: ingressName}, &operatorv1.IngressController{}); err != nil {
+	ingress := &operatorv1.IngressController{}
+	if err := r.cache.Get(context.TODO(), types.NamespacedName{Namespace: record.Namespace, Name: ingressName}, ingress); err != nil {
 		if errors.IsNotFound(err) {
 			// TODO: what should we do here? something upstream could detect and delete the orphan? add new conditions?
 			// is it safe to retry without verifying the owner isn't a new object?
@@ -179,14 +237,48 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		}
 	}
 
+	forceReplace := false
+	if overrides, haveOverrides, err := targetOverridesFromIngressAnnotation(ingress); err != nil {
+		log.Error(err, "invalid DNS target overrides; the dnsrecord will not be published until the annotation is fixed", "dnsrecord", record, "ingresscontroller", ingressName)
+		r.recorder.Eventf(record, "Warning", "InvalidDNSTargetOverrides", "Invalid %s annotation on ingresscontroller %s: %v", dnsTargetOverridesAnnotation, ingressName, err)
+		return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
+	} else if updated, changed := syncTargetOverridesToRecord(record, overrides, haveOverrides); changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			log.Error(err, "failed to update dnsrecord; will retry", "dnsrecord", updated)
+			return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
+		}
+		record = updated
+		// The per-zone targets changed, so already-published zones must
+		// be republished.
+		forceReplace = true
+	}
+
 	var zones []configv1.DNSZone
-	if dnsConfig.Spec.PrivateZone != nil {
-		zones = append(zones, *dnsConfig.Spec.PrivateZone)
+	if overrideZones, haveOverride, err := zonesFromIngressAnnotation(ingress); err != nil {
+		// Do not fall back to the inferred zones: publishing to the
+		// wrong zone is exactly what the override exists to prevent.
+		log.Error(err, "invalid DNS zone override; the dnsrecord will not be published until the annotation is fixed", "dnsrecord", record, "ingresscontroller", ingressName)
+		r.recorder.Eventf(record, "Warning", "InvalidDNSZoneOverride", "Invalid %s annotation on ingresscontroller %s: %v", dnsZonesAnnotation, ingressName, err)
+		return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
+	} else if haveOverride {
+		if err := validateRecordInZones(overrideZones, record); err != nil {
+			log.Error(err, "dnsrecord is not within the configured DNS zones; the dnsrecord will not be published until the annotation is fixed", "dnsrecord", record, "ingresscontroller", ingressName)
+			r.recorder.Eventf(record, "Warning", "InvalidDNSZoneOverride", "Invalid %s annotation on ingresscontroller %s: %v", dnsZonesAnnotation, ingressName, err)
+			return reconcile.Result{RequeueAfter: 1 * time.Minute}, nil
+		}
+		zones = overrideZones
+	} else {
+		if dnsConfig.Spec.PrivateZone != nil {
+			zones = append(zones, *dnsConfig.Spec.PrivateZone)
+		}
+		if dnsConfig.Spec.PublicZone != nil {
+			zones = append(zones, *dnsConfig.Spec.PublicZone)
+		}
 	}
-	if dnsConfig.Spec.PublicZone != nil {
-		zones = append(zones, *dnsConfig.Spec.PublicZone)
+	statuses, result := r.publishRecordToZones(zones, record, forceReplace)
+	if len(r.config.ResolutionCheckResolver) != 0 {
+		statuses, result = r.verifyRecordResolution(record, statuses, result)
 	}
-	statuses, result := r.publishRecordToZones(zones, record)
 	if !dnsZoneStatusSlicesEqual(statuses, record.Status.Zones) {
 		updated := record.DeepCopy()
 		updated.Status.Zones = statuses
@@ -248,7 +340,11 @@ func (r *reconciler) createDNSProviderIfNeeded(dnsConfig *configv1.DNS) error {
 	return nil
 }
 
-func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1.DNSRecord) ([]iov1.DNSZoneStatus, reconcile.Result) {
+// publishRecordToZones publishes the record to the provided zones.  If
+// forceReplace is true, zones to which the record is already published are
+// republished even if the record's generation is unchanged (for example,
+// because the per-zone target overrides changed).
+func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1.DNSRecord, forceReplace bool) ([]iov1.DNSZoneStatus, reconcile.Result) {
 	result := reconcile.Result{}
 	var statuses []iov1.DNSZoneStatus
 	for i := range zones {
@@ -257,7 +353,7 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 		// Only publish the record if the DNSRecord has been modified
 		// (which would mean the target could have changed) or its
 		// status does not indicate that it has already been published.
-		if record.Generation == record.Status.ObservedGeneration && recordIsAlreadyPublishedToZone(record, &zone) {
+		if !forceReplace && record.Generation == record.Status.ObservedGeneration && recordIsAlreadyPublishedToZone(record, &zone) {
 			log.Info("skipping zone to which the DNS record is already published", "record", record.Spec, "dnszone", zone)
 			continue
 		}
@@ -268,30 +364,31 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 			LastTransitionTime: metav1.Now(),
 		}
 
+		rec := recordForZone(record, &zone)
 		if recordIsAlreadyPublishedToZone(record, &zone) {
-			log.Info("replacing DNS record", "record", record.Spec, "dnszone", zone)
+			log.Info("replacing DNS record", "record", rec.Spec, "dnszone", zone)
 
-			if err := r.dnsProvider.Replace(record, zone); err != nil {
-				log.Error(err, "failed to replace DNS record in zone", "record", record.Spec, "dnszone", zone)
+			if err := r.dnsProvider.Replace(rec, zone); err != nil {
+				log.Error(err, "failed to replace DNS record in zone", "record", rec.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionTrue)
 				condition.Reason = "ProviderError"
 				condition.Message = fmt.Sprintf("The DNS provider failed to replace the record: %v", err)
 				result.RequeueAfter = 30 * time.Second
 			} else {
-				log.Info("replaced DNS record in zone", "record", record.Spec, "dnszone", zone)
+				log.Info("replaced DNS record in zone", "record", rec.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionFalse)
 				condition.Reason = "ProviderSuccess"
 				condition.Message = "The DNS provider succeeded in replacing the record"
 			}
 		} else {
-			if err := r.dnsProvider.Ensure(record, zone); err != nil {
-				log.Error(err, "failed to publish DNS record to zone", "record", record.Spec, "dnszone", zone)
+			if err := r.dnsProvider.Ensure(rec, zone); err != nil {
+				log.Error(err, "failed to publish DNS record to zone", "record", rec.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionTrue)
 				condition.Reason = "ProviderError"
 				condition.Message = fmt.Sprintf("The DNS provider failed to ensure the record: %v", err)
 				result.RequeueAfter = 30 * time.Second
 			} else {
-				log.Info("published DNS record to zone", "record", record.Spec, "dnszone", zone)
+				log.Info("published DNS record to zone", "record", rec.Spec, "dnszone", zone)
 				condition.Status = string(operatorv1.ConditionFalse)
 				condition.Reason = "ProviderSuccess"
 				condition.Message = "The DNS provider succeeded in ensuring the record"
@@ -305,6 +402,123 @@ func (r *reconciler) publishRecordToZones(zones []configv1.DNSZone, record *iov1
 	return mergeStatuses(record.Status.DeepCopy().Zones, statuses), result
 }
 
+// verifyRecordResolution checks whether the record resolves to its expected
+// target and sets the "Resolvable" condition on each zone status to which the
+// record was successfully published.  When publishing succeeded but
+// resolution has not yet converged, the condition is False with reason
+// "ResolutionPending" and the record is requeued with bounded retries.
+func (r *reconciler) verifyRecordResolution(record *iov1.DNSRecord, statuses []iov1.DNSZoneStatus, result reconcile.Result) ([]iov1.DNSZoneStatus, reconcile.Result) {
+	anyPublished := false
+	for i := range statuses {
+		for _, cond := range statuses[i].Conditions {
+			if cond.Type == iov1.DNSRecordFailedConditionType && cond.Status == string(operatorv1.ConditionFalse) {
+				anyPublished = true
+			}
+		}
+	}
+	if !anyPublished {
+		return statuses, result
+	}
+
+	resolve := r.resolve
+	if resolve == nil {
+		resolve = r.recordResolves
+	}
+	resolved, err := resolve(record)
+
+	condition := iov1.DNSZoneCondition{
+		Type:               dnsRecordResolvableConditionType,
+		LastTransitionTime: metav1.Now(),
+	}
+	name := types.NamespacedName{Namespace: record.Namespace, Name: record.Name}
+	switch {
+	case resolved:
+		condition.Status = string(operatorv1.ConditionTrue)
+		condition.Reason = "ResolutionSuccess"
+		condition.Message = "The record resolves to its expected target"
+		delete(r.resolutionRetries, name)
+	case err != nil:
+		condition.Status = string(operatorv1.ConditionFalse)
+		condition.Reason = "ResolutionError"
+		condition.Message = fmt.Sprintf("Failed to resolve the record: %v", err)
+	default:
+		condition.Status = string(operatorv1.ConditionFalse)
+		condition.Reason = "ResolutionPending"
+		condition.Message = "The record was published, but it does not yet resolve to its expected target"
+		// If the record resolved correctly before, it was changed outside
+		// the operator; republish it and report the drift.
+		if corrected := r.correctRecordDrift(record); corrected != 0 {
+			condition.Reason = "DriftCorrected"
+			condition.Message = "The record was changed outside the operator and was republished"
+		}
+	}
+	if !resolved {
+		r.resolutionRetries[name]++
+		if r.resolutionRetries[name] <= maxResolutionCheckRetries && result.RequeueAfter == 0 {
+			result.RequeueAfter = 30 * time.Second
+		}
+	}
+
+	for i := range statuses {
+		published := false
+		for _, cond := range statuses[i].Conditions {
+			if cond.Type == iov1.DNSRecordFailedConditionType && cond.Status == string(operatorv1.ConditionFalse) {
+				published = true
+			}
+		}
+		if !published {
+			continue
+		}
+		statuses[i].Conditions = mergeConditions(statuses[i].Conditions, []iov1.DNSZoneCondition{condition})
+	}
+	return statuses, result
+}
+
+// recordResolves resolves the record's DNS name against the configured
+// resolver and reports whether it resolves to one of the record's targets.
+func (r *reconciler) recordResolves(record *iov1.DNSRecord) (bool, error) {
+	timeout := r.config.ResolutionCheckTimeout
+	if timeout == 0 {
+		timeout = defaultResolutionCheckTimeout
+	}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: timeout}
+			return d.DialContext(ctx, network, r.config.ResolutionCheckResolver)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	name := strings.TrimSuffix(record.Spec.DNSName, ".")
+	switch record.Spec.RecordType {
+	case iov1.CNAMERecordType:
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		for _, target := range record.Spec.Targets {
+			if strings.TrimSuffix(cname, ".") == strings.TrimSuffix(target, ".") {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		addrs, err := resolver.LookupHost(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		for _, addr := range addrs {
+			for _, target := range record.Spec.Targets {
+				if addr == target {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+}
+
 // recordIsAlreadyPublishedToZone returns a Boolean value indicating whether the
 // given DNSRecord is already published to the given zone, as determined from
 // the DNSRecord's status conditions.
@@ -326,6 +540,7 @@ func recordIsAlreadyPublishedToZone(record *iov1.DNSRecord, zoneToPublish *confi
 
 func (r *reconciler) delete(record *iov1.DNSRecord) error {
 	var errs []error
+	var publishedZones []configv1.DNSZone
 	for i := range record.Status.Zones {
 		zone := record.Status.Zones[i].DNSZone
 		// If the record is currently not published in a zone,
@@ -333,13 +548,40 @@ func (r *reconciler) delete(record *iov1.DNSRecord) error {
 		if !recordIsAlreadyPublishedToZone(record, &zone) {
 			continue
 		}
-		err := r.dnsProvider.Delete(record, zone)
+		publishedZones = append(publishedZones, zone)
+	}
+	// Snapshot the record before deleting it so that it can be recreated
+	// manually if the deletion was a mistake.  The snapshot is best-effort:
+	// a failure to record it must not block the deletion, or the finalizer
+	// could wedge the owning ingresscontroller's deletion.
+	if len(publishedZones) != 0 {
+		if err := r.snapshotRecordBeforeDeletion(record, publishedZones); err != nil {
+			log.Error(err, "failed to snapshot dnsrecord before deletion", "dnsrecord", record)
+		} else {
+			r.recorder.Eventf(record, "Normal", "DNSRecordSnapshotted", "Recorded a snapshot of the record in configmap %s/%s before deletion.", r.config.Namespace, deletedRecordsConfigMapName)
+		}
+	}
+	for _, zone := range publishedZones {
+		err := r.dnsProvider.Delete(recordForZone(record, &zone), zone)
 		if err != nil {
 			errs = append(errs, err)
 		} else {
 			log.Info("deleted dnsrecord from DNS provider", "record", record.Spec, "zone", zone)
 		}
 	}
+	// If the provider cleanup has been failing for longer than the deletion
+	// timeout (for example, because the cloud API is unreachable), give up
+	// rather than block the owning ingresscontroller's deletion forever.
+	// The records may be orphaned in the provider; the event leaves a trail
+	// for manual cleanup.
+	if len(errs) != 0 && record.DeletionTimestamp != nil {
+		if elapsed := time.Since(record.DeletionTimestamp.Time); elapsed >= r.deletionTimeout() {
+			err := utilerrors.NewAggregate(errs)
+			log.Error(err, "giving up deleting dnsrecord from DNS provider after timeout; records may be orphaned", "dnsrecord", record, "elapsed", elapsed.String())
+			r.recorder.Eventf(record, "Warning", "DNSRecordOrphaned", "Gave up deleting the record from the DNS provider after %s; records for %s may be orphaned in zones %v: %v", elapsed.Round(time.Second), record.Spec.DNSName, publishedZones, err)
+			errs = nil
+		}
+	}
 	if len(errs) == 0 {
 		updated := record.DeepCopy()
 		if slice.ContainsString(updated.Finalizers, manifests.DNSRecordFinalizer) {
@@ -352,6 +594,18 @@ func (r *reconciler) delete(record *iov1.DNSRecord) error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// defaultDeletionTimeout is the deletion timeout when none is configured.
+const defaultDeletionTimeout = 10 * time.Minute
+
+// deletionTimeout returns the configured deletion timeout, applying the
+// default when none is configured.
+func (r *reconciler) deletionTimeout() time.Duration {
+	if r.config.DeletionTimeout > 0 {
+		return r.config.DeletionTimeout
+	}
+	return defaultDeletionTimeout
+}
+
 // mergeStatuses updates or extends the provided slice of statuses with the
 // provided updates and returns the resulting slice.
 func mergeStatuses(statuses, updates []iov1.DNSZoneStatus) []iov1.DNSZoneStatus {
@@ -447,6 +701,44 @@ func (r *reconciler) ToDNSRecords(o handler.MapObject) []reconcile.Request {
 	return requests
 }
 
+// dnsAnnotationsChanged returns whether any of the ingresscontroller
+// annotations that the dns controller reads differ between the two
+// annotation maps.
+func dnsAnnotationsChanged(oldAnnotations, newAnnotations map[string]string) bool {
+	for _, key := range []string{dnsZonesAnnotation, dnsTargetOverridesAnnotation} {
+		if oldAnnotations[key] != newAnnotations[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// ingressControllerToDNSRecords returns reconciliation requests for the
+// dnsrecords that the given ingresscontroller owns.  It is the inverse of
+// ToDNSRecords, which fans a cluster-scoped event out to all dnsrecords.
+func (r *reconciler) ingressControllerToDNSRecords(o handler.MapObject) []reconcile.Request {
+	var requests []reconcile.Request
+	records := &operatoringressv1.DNSRecordList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(o.Meta.GetNamespace()),
+		client.MatchingLabels{manifests.OwningIngressControllerLabel: o.Meta.GetName()},
+	}
+	if err := r.cache.List(context.Background(), records, listOpts...); err != nil {
+		log.Error(err, "failed to list dnsrecords for ingresscontroller", "ingresscontroller", o.Meta.GetName())
+		return requests
+	}
+	for _, record := range records.Items {
+		log.Info("queueing dnsrecord", "name", record.Name, "related", o.Meta.GetSelfLink())
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: record.Namespace,
+				Name:      record.Name,
+			},
+		})
+	}
+	return requests
+}
+
 // createDNSProvider creates a DNS manager compatible with the given cluster
 // configuration.
 func (r *reconciler) createDNSProvider(dnsConfig *configv1.DNS, platformStatus *configv1.PlatformStatus, creds *corev1.Secret) (dns.Provider, error) {
@@ -1,17 +1,30 @@
 package dns
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	iov1 "github.com/openshift/api/operatoringress/v1"
 	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestPublishRecordToZones(t *testing.T) {
@@ -53,7 +66,7 @@ func TestPublishRecordToZones(t *testing.T) {
 			//TODO To write a fake provider that can return errors and add more test cases.
 			dnsProvider: &dns.FakeProvider{},
 		}
-		actual, _ := r.publishRecordToZones(test.zones, record)
+		actual, _ := r.publishRecordToZones(test.zones, record, false)
 		var conditions []string
 		for _, dnsStatus := range actual {
 			for _, condition := range dnsStatus.Conditions {
@@ -152,7 +165,7 @@ func TestPublishRecordToZonesMergesStatus(t *testing.T) {
 		r := &reconciler{dnsProvider: &dns.FakeProvider{}}
 		zone := []configv1.DNSZone{{ID: "zone2"}}
 		oldStatuses := record.Status.DeepCopy().Zones
-		newStatuses, _ := r.publishRecordToZones(zone, record)
+		newStatuses, _ := r.publishRecordToZones(zone, record, false)
 		if !dnsZoneStatusSlicesEqual(oldStatuses, tc.oldZoneStatuses) {
 			t.Fatalf("%q: publishRecordToZones mutated the record's status conditions\nold: %#v\nnew: %#v", tc.description, oldStatuses, tc.oldZoneStatuses)
 		}
@@ -628,3 +641,346 @@ func TestCustomCABundle(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyRecordResolution(t *testing.T) {
+	publishedStatus := func() []iov1.DNSZoneStatus {
+		return []iov1.DNSZoneStatus{
+			{
+				DNSZone: configv1.DNSZone{ID: "zone1"},
+				Conditions: []iov1.DNSZoneCondition{
+					{
+						Type:   iov1.DNSRecordFailedConditionType,
+						Status: string(operatorv1.ConditionFalse),
+						Reason: "ProviderSuccess",
+					},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		description   string
+		resolved      bool
+		resolveError  error
+		statuses      []iov1.DNSZoneStatus
+		expectCond    bool
+		expectStatus  string
+		expectReason  string
+		expectRequeue bool
+	}{
+		{
+			description:  "record resolves",
+			resolved:     true,
+			statuses:     publishedStatus(),
+			expectCond:   true,
+			expectStatus: string(operatorv1.ConditionTrue),
+			expectReason: "ResolutionSuccess",
+		},
+		{
+			description:   "published but not yet resolvable",
+			statuses:      publishedStatus(),
+			expectCond:    true,
+			expectStatus:  string(operatorv1.ConditionFalse),
+			expectReason:  "ResolutionPending",
+			expectRequeue: true,
+		},
+		{
+			description:   "resolution error",
+			resolveError:  errors.New("connection refused"),
+			statuses:      publishedStatus(),
+			expectCond:    true,
+			expectStatus:  string(operatorv1.ConditionFalse),
+			expectReason:  "ResolutionError",
+			expectRequeue: true,
+		},
+		{
+			description: "not published, no resolution check",
+			statuses: []iov1.DNSZoneStatus{
+				{
+					DNSZone: configv1.DNSZone{ID: "zone1"},
+					Conditions: []iov1.DNSZoneCondition{
+						{
+							Type:   iov1.DNSRecordFailedConditionType,
+							Status: string(operatorv1.ConditionTrue),
+							Reason: "ProviderError",
+						},
+					},
+				},
+			},
+			expectCond: false,
+		},
+	}
+	for _, tc := range testCases {
+		record := &iov1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      "default-wildcard",
+			},
+			Spec: iov1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com.",
+				Targets:    []string{"192.0.2.1"},
+				RecordType: iov1.ARecordType,
+			},
+		}
+		r := &reconciler{
+			config: Config{
+				ResolutionCheckResolver: "192.0.2.53:53",
+			},
+			resolve: func(*iov1.DNSRecord) (bool, error) {
+				return tc.resolved, tc.resolveError
+			},
+			resolutionRetries: map[types.NamespacedName]int{},
+		}
+		statuses, result := r.verifyRecordResolution(record, tc.statuses, reconcile.Result{})
+		var condition *iov1.DNSZoneCondition
+		for i, cond := range statuses[0].Conditions {
+			if cond.Type == dnsRecordResolvableConditionType {
+				condition = &statuses[0].Conditions[i]
+			}
+		}
+		if tc.expectCond != (condition != nil) {
+			t.Errorf("%q: expected condition presence %v, got %v", tc.description, tc.expectCond, condition != nil)
+			continue
+		}
+		if condition != nil {
+			if condition.Status != tc.expectStatus || condition.Reason != tc.expectReason {
+				t.Errorf("%q: expected %s/%s, got %s/%s", tc.description, tc.expectStatus, tc.expectReason, condition.Status, condition.Reason)
+			}
+		}
+		if tc.expectRequeue != (result.RequeueAfter > 0) {
+			t.Errorf("%q: expected requeue %v, got %v", tc.description, tc.expectRequeue, result.RequeueAfter)
+		}
+	}
+}
+
+func TestVerifyRecordResolutionBoundedRetries(t *testing.T) {
+	record := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default-wildcard",
+		},
+	}
+	statuses := []iov1.DNSZoneStatus{
+		{
+			DNSZone: configv1.DNSZone{ID: "zone1"},
+			Conditions: []iov1.DNSZoneCondition{
+				{
+					Type:   iov1.DNSRecordFailedConditionType,
+					Status: string(operatorv1.ConditionFalse),
+				},
+			},
+		},
+	}
+	r := &reconciler{
+		config: Config{
+			ResolutionCheckResolver: "192.0.2.53:53",
+		},
+		resolve: func(*iov1.DNSRecord) (bool, error) {
+			return false, nil
+		},
+		resolutionRetries: map[types.NamespacedName]int{},
+	}
+	for i := 0; i < maxResolutionCheckRetries; i++ {
+		if _, result := r.verifyRecordResolution(record, statuses, reconcile.Result{}); result.RequeueAfter == 0 {
+			t.Fatalf("expected requeue on attempt %d", i+1)
+		}
+	}
+	if _, result := r.verifyRecordResolution(record, statuses, reconcile.Result{}); result.RequeueAfter != 0 {
+		t.Error("expected no requeue after retries are exhausted")
+	}
+}
+
+// failingProvider fails all provider operations.
+type failingProvider struct {
+	dns.FakeProvider
+}
+
+func (p *failingProvider) Delete(record *iov1.DNSRecord, zone configv1.DNSZone) error {
+	return errors.New("cloud API unreachable")
+}
+
+// TestDeleteTimeoutRemovesFinalizer verifies that when the DNS provider
+// cleanup keeps failing, the finalizer is retained until the deletion timeout
+// elapses and then removed with an event recording the orphaned records.
+func TestDeleteTimeoutRemovesFinalizer(t *testing.T) {
+	zone := configv1.DNSZone{ID: "zone1"}
+	newRecord := func(deletedAgo time.Duration) *iov1.DNSRecord {
+		deletionTimestamp := metav1.NewTime(time.Now().Add(-deletedAgo))
+		return &iov1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "openshift-ingress-operator",
+				Name:              "default-wildcard",
+				DeletionTimestamp: &deletionTimestamp,
+				Finalizers:        []string{manifests.DNSRecordFinalizer},
+			},
+			Spec: iov1.DNSRecordSpec{
+				DNSName:    "*.apps.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.1"},
+				RecordTTL:  120,
+			},
+			Status: iov1.DNSRecordStatus{
+				Zones: []iov1.DNSZoneStatus{{
+					DNSZone: zone,
+					Conditions: []iov1.DNSZoneCondition{{
+						Type:   iov1.DNSRecordFailedConditionType,
+						Status: string(corev1.ConditionFalse),
+					}},
+				}},
+			},
+		}
+	}
+	testCases := []struct {
+		description            string
+		deletedAgo             time.Duration
+		expectError            bool
+		expectFinalizerRemoved bool
+	}{
+		{
+			description: "cleanup keeps failing before the timeout",
+			deletedAgo:  time.Minute,
+			expectError: true,
+		},
+		{
+			description:            "cleanup is abandoned after the timeout",
+			deletedAgo:             20 * time.Minute,
+			expectFinalizerRemoved: true,
+		},
+	}
+	for _, tc := range testCases {
+		dnsRecord := newRecord(tc.deletedAgo)
+		client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), dnsRecord)
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			config:      Config{Namespace: "openshift-ingress-operator", DeletionTimeout: 10 * time.Minute},
+			client:      client,
+			dnsProvider: &failingProvider{},
+			recorder:    recorder,
+		}
+		err := r.delete(dnsRecord)
+		if tc.expectError != (err != nil) {
+			t.Errorf("%q: expected error to be %v, got: %v", tc.description, tc.expectError, err)
+			continue
+		}
+		updated := &iov1.DNSRecord{}
+		name := types.NamespacedName{Namespace: dnsRecord.Namespace, Name: dnsRecord.Name}
+		if err := client.Get(context.TODO(), name, updated); err != nil {
+			t.Errorf("%q: failed to get dnsrecord: %v", tc.description, err)
+			continue
+		}
+		if removed := len(updated.Finalizers) == 0; removed != tc.expectFinalizerRemoved {
+			t.Errorf("%q: expected finalizer removed to be %v, got finalizers %v", tc.description, tc.expectFinalizerRemoved, updated.Finalizers)
+		}
+		if tc.expectFinalizerRemoved {
+			orphanEvent := false
+			for len(recorder.Events) > 0 {
+				if event := <-recorder.Events; strings.Contains(event, "DNSRecordOrphaned") {
+					orphanEvent = true
+				}
+			}
+			if !orphanEvent {
+				t.Errorf("%q: expected a DNSRecordOrphaned event", tc.description)
+			}
+		}
+	}
+}
+
+// fakeCache delegates reads to a fake client so that reconciler methods that
+// read through the cache can be tested.
+type fakeCache struct {
+	cache.Cache
+	reader crclient.Reader
+}
+
+func (c *fakeCache) Get(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) error {
+	return c.reader.Get(ctx, key, obj)
+}
+
+func (c *fakeCache) List(ctx context.Context, list runtime.Object, opts ...crclient.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+// TestDNSAnnotationsChanged verifies that only changes to the annotations
+// that the dns controller reads off the ingresscontroller trigger the
+// ingresscontroller watch.
+func TestDNSAnnotationsChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		old, new    map[string]string
+		expected    bool
+	}{
+		{
+			description: "no annotations",
+			expected:    false,
+		},
+		{
+			description: "zone override added",
+			new:         map[string]string{dnsZonesAnnotation: `[{"id":"private-zone"}]`},
+			expected:    true,
+		},
+		{
+			description: "zone override changed",
+			old:         map[string]string{dnsZonesAnnotation: `[{"id":"private-zone"}]`},
+			new:         map[string]string{dnsZonesAnnotation: `[{"id":"other-zone"}]`},
+			expected:    true,
+		},
+		{
+			description: "zone override removed",
+			old:         map[string]string{dnsZonesAnnotation: `[{"id":"private-zone"}]`},
+			expected:    true,
+		},
+		{
+			description: "target overrides added",
+			new:         map[string]string{dnsTargetOverridesAnnotation: `{"private-zone":["10.0.0.1"]}`},
+			expected:    true,
+		},
+		{
+			description: "unrelated annotation changed",
+			old:         map[string]string{"unrelated": "a"},
+			new:         map[string]string{"unrelated": "b"},
+			expected:    false,
+		},
+		{
+			description: "annotations unchanged",
+			old:         map[string]string{dnsZonesAnnotation: `[{"id":"private-zone"}]`},
+			new:         map[string]string{dnsZonesAnnotation: `[{"id":"private-zone"}]`},
+			expected:    false,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := dnsAnnotationsChanged(tc.old, tc.new); actual != tc.expected {
+			t.Errorf("%q: expected %t, got %t", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+// TestIngressControllerToDNSRecords verifies that an ingresscontroller event
+// enqueues the dnsrecords that the ingresscontroller owns, such as its
+// wildcard record, and no others.
+func TestIngressControllerToDNSRecords(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	record := func(name, owner string) *iov1.DNSRecord {
+		return &iov1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      name,
+				Labels: map[string]string{
+					manifests.OwningIngressControllerLabel: owner,
+				},
+			},
+		}
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), record("default-wildcard", "default"), record("internal-wildcard", "internal"))
+	r := &reconciler{
+		cache: &fakeCache{reader: client},
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "default"},
+	}
+	requests := r.ingressControllerToDNSRecords(handler.MapObject{Meta: ic, Object: ic})
+	expected := []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: "default-wildcard"},
+	}}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
@@ -0,0 +1,78 @@
+package dns
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+)
+
+// dnsDriftCorrected counts DNS records that the resolution check found
+// pointing at an unexpected target after having resolved correctly, and that
+// the controller republished.  A steadily increasing counter indicates that
+// something outside the operator is modifying the operator's records.
+var dnsDriftCorrected = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ingress_operator_dns_drift_corrected_total",
+		Help: "A counter tracking DNS records that were changed out-of-band and corrected by the operator",
+	}, []string{"zone", "record"})
+
+func init() {
+	ctrlruntimemetrics.Registry.MustRegister(dnsDriftCorrected)
+}
+
+// zoneLabel returns a label value identifying the given zone for metrics.
+func zoneLabel(zone configv1.DNSZone) string {
+	if len(zone.ID) != 0 {
+		return zone.ID
+	}
+	return fmt.Sprintf("%v", zone.Tags)
+}
+
+// recordWasResolvable returns a Boolean value indicating whether the given
+// DNSRecord's status reports that it previously resolved to its expected
+// target in the given zone.
+func recordWasResolvable(record *iov1.DNSRecord, zone *configv1.DNSZone) bool {
+	for _, zoneInStatus := range record.Status.Zones {
+		if !reflect.DeepEqual(&zoneInStatus.DNSZone, zone) {
+			continue
+		}
+		for _, condition := range zoneInStatus.Conditions {
+			if condition.Type == dnsRecordResolvableConditionType {
+				return condition.Status == string(operatorv1.ConditionTrue)
+			}
+		}
+	}
+	return false
+}
+
+// correctRecordDrift republishes the record to each zone in which it was
+// previously resolvable but no longer resolves to its expected target, which
+// indicates that the record was changed outside the operator.  Each corrected
+// zone is reported through an event and the drift counter metric so that the
+// correction is visible even though the operator reverts the change.
+// correctRecordDrift returns the number of zones that were corrected.
+func (r *reconciler) correctRecordDrift(record *iov1.DNSRecord) int {
+	corrected := 0
+	for i := range record.Status.Zones {
+		zone := record.Status.Zones[i].DNSZone
+		if !recordIsAlreadyPublishedToZone(record, &zone) || !recordWasResolvable(record, &zone) {
+			continue
+		}
+		rec := recordForZone(record, &zone)
+		if err := r.dnsProvider.Replace(rec, zone); err != nil {
+			log.Error(err, "failed to republish drifted DNS record in zone", "record", rec.Spec, "dnszone", zone)
+			continue
+		}
+		log.Info("republished DNS record that was changed out-of-band", "record", rec.Spec, "dnszone", zone)
+		r.recorder.Eventf(record, "Warning", "DNSRecordDriftCorrected", "The record was changed outside the operator and was republished to zone %v.", zone)
+		dnsDriftCorrected.WithLabelValues(zoneLabel(zone), record.Name).Inc()
+		corrected++
+	}
+	return corrected
+}
@@ -0,0 +1,128 @@
+package dns
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// replaceCountingProvider counts Replace calls by zone ID.
+type replaceCountingProvider struct {
+	dns.FakeProvider
+	replaces map[string]int
+}
+
+func (p *replaceCountingProvider) Replace(record *iov1.DNSRecord, zone configv1.DNSZone) error {
+	if p.replaces == nil {
+		p.replaces = map[string]int{}
+	}
+	p.replaces[zone.ID]++
+	return nil
+}
+
+// driftCounterValue returns the current value of the drift counter metric for
+// the given zone and record labels.
+func driftCounterValue(t *testing.T, zone, record string) float64 {
+	t.Helper()
+	families, err := ctrlruntimemetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "ingress_operator_dns_drift_corrected_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["zone"] == zone && labels["record"] == record {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestCorrectRecordDrift(t *testing.T) {
+	driftedZone := configv1.DNSZone{ID: "drifted-zone"}
+	pendingZone := configv1.DNSZone{ID: "pending-zone"}
+	published := iov1.DNSZoneCondition{
+		Type:   iov1.DNSRecordFailedConditionType,
+		Status: string(operatorv1.ConditionFalse),
+	}
+	dnsRecord := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default-wildcard"},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com",
+			RecordType: iov1.ARecordType,
+			Targets:    []string{"10.0.0.1"},
+			RecordTTL:  30,
+		},
+		Status: iov1.DNSRecordStatus{
+			Zones: []iov1.DNSZoneStatus{
+				{
+					// The record resolved correctly in this zone
+					// before, so a failed resolution indicates
+					// drift.
+					DNSZone: driftedZone,
+					Conditions: []iov1.DNSZoneCondition{
+						published,
+						{
+							Type:   dnsRecordResolvableConditionType,
+							Status: string(operatorv1.ConditionTrue),
+						},
+					},
+				},
+				{
+					// The record never resolved in this zone, so
+					// a failed resolution is only propagation
+					// delay, not drift.
+					DNSZone:    pendingZone,
+					Conditions: []iov1.DNSZoneCondition{published},
+				},
+			},
+		},
+	}
+	provider := &replaceCountingProvider{}
+	r := &reconciler{
+		config:      Config{ResolutionCheckResolver: "192.0.2.1:53"},
+		dnsProvider: provider,
+		recorder:    record.NewFakeRecorder(10),
+		resolve: func(_ *iov1.DNSRecord) (bool, error) {
+			return false, nil
+		},
+		resolutionRetries: map[types.NamespacedName]int{},
+	}
+	driftedBefore := driftCounterValue(t, driftedZone.ID, dnsRecord.Name)
+	pendingBefore := driftCounterValue(t, pendingZone.ID, dnsRecord.Name)
+
+	statuses := dnsRecord.Status.DeepCopy().Zones
+	r.verifyRecordResolution(dnsRecord, statuses, reconcile.Result{})
+
+	if got := provider.replaces[driftedZone.ID]; got != 1 {
+		t.Errorf("expected 1 replace in zone %s, got %d", driftedZone.ID, got)
+	}
+	if got := provider.replaces[pendingZone.ID]; got != 0 {
+		t.Errorf("expected no replaces in zone %s, got %d", pendingZone.ID, got)
+	}
+	if got := driftCounterValue(t, driftedZone.ID, dnsRecord.Name); got != driftedBefore+1 {
+		t.Errorf("expected the drift counter for zone %s to increment to %v, got %v", driftedZone.ID, driftedBefore+1, got)
+	}
+	if got := driftCounterValue(t, pendingZone.ID, dnsRecord.Name); got != pendingBefore {
+		t.Errorf("expected the drift counter for zone %s to stay at %v, got %v", pendingZone.ID, pendingBefore, got)
+	}
+}
@@ -0,0 +1,123 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// deletedRecordsConfigMapName is the name of the configmap in the
+	// operator's namespace in which snapshots of deleted DNS records are
+	// kept so that a mistakenly deleted record can be recreated manually.
+	deletedRecordsConfigMapName = "deleted-dnsrecords"
+
+	// maxDeletedRecordSnapshots bounds the number of snapshots kept in the
+	// deleted-dnsrecords configmap.  When the limit is exceeded, the
+	// oldest snapshots are pruned.
+	maxDeletedRecordSnapshots = 20
+)
+
+// recordSnapshot captures enough of a deleted DNS record for it to be
+// recreated manually.
+type recordSnapshot struct {
+	DeletedAt  metav1.Time        `json:"deletedAt"`
+	DNSName    string             `json:"dnsName"`
+	RecordType iov1.DNSRecordType `json:"recordType"`
+	Targets    []string           `json:"targets"`
+	Zones      []configv1.DNSZone `json:"zones"`
+}
+
+// snapshotRecordBeforeDeletion writes the name, type, targets, and zones of a
+// DNS record that is about to be deleted into the deleted-dnsrecords
+// configmap in the operator's namespace, pruning the oldest snapshots to keep
+// the configmap bounded.
+func (r *reconciler) snapshotRecordBeforeDeletion(record *iov1.DNSRecord, zones []configv1.DNSZone) error {
+	snapshot := recordSnapshot{
+		DeletedAt:  metav1.NewTime(clock.Now()),
+		DNSName:    record.Spec.DNSName,
+		RecordType: record.Spec.RecordType,
+		Targets:    record.Spec.Targets,
+		Zones:      zones,
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot of dnsrecord %s: %v", record.Name, err)
+	}
+	key := snapshotKey(record, snapshot.DeletedAt)
+
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: r.config.Namespace, Name: deletedRecordsConfigMapName}
+	switch err := r.client.Get(context.TODO(), name, cm); {
+	case errors.IsNotFound(err):
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: name.Namespace,
+				Name:      name.Name,
+			},
+			Data: map[string]string{key: string(data)},
+		}
+		if err := r.client.Create(context.TODO(), cm); err != nil {
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+
+	updated := cm.DeepCopy()
+	if updated.Data == nil {
+		updated.Data = map[string]string{}
+	}
+	updated.Data[key] = string(data)
+	pruneOldestSnapshots(updated.Data, maxDeletedRecordSnapshots)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	return nil
+}
+
+// snapshotKey returns the configmap key under which a record's snapshot is
+// stored.  The deletion time is included so that successive deletions of
+// records with the same name do not overwrite each other's snapshots.
+func snapshotKey(record *iov1.DNSRecord, deletedAt metav1.Time) string {
+	return fmt.Sprintf("%s.%d", record.Name, deletedAt.Unix())
+}
+
+// pruneOldestSnapshots removes the oldest snapshots from the provided data
+// until at most max remain.  Entries that cannot be parsed are pruned first.
+func pruneOldestSnapshots(data map[string]string, max int) {
+	if len(data) <= max {
+		return
+	}
+	type entry struct {
+		key       string
+		deletedAt time.Time
+	}
+	entries := make([]entry, 0, len(data))
+	for key, value := range data {
+		var snapshot recordSnapshot
+		if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+			entries = append(entries, entry{key: key})
+			continue
+		}
+		entries = append(entries, entry{key: key, deletedAt: snapshot.DeletedAt.Time})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].deletedAt.Before(entries[j].deletedAt)
+	})
+	for _, e := range entries[:len(entries)-max] {
+		delete(data, e.key)
+	}
+}
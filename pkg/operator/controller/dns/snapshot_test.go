@@ -0,0 +1,130 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestSnapshotRecordBeforeDeletion verifies that deleting a DNS record writes
+// a snapshot of the record's published zones, name, type, and targets into
+// the deleted-dnsrecords configmap.
+func TestSnapshotRecordBeforeDeletion(t *testing.T) {
+	publishedZone := configv1.DNSZone{ID: "zone1"}
+	unpublishedZone := configv1.DNSZone{ID: "zone2"}
+	dnsRecord := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default-wildcard",
+		},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.ARecordType,
+			Targets:    []string{"192.0.2.1"},
+			RecordTTL:  120,
+		},
+		Status: iov1.DNSRecordStatus{
+			Zones: []iov1.DNSZoneStatus{
+				{
+					DNSZone: publishedZone,
+					Conditions: []iov1.DNSZoneCondition{{
+						Type:   iov1.DNSRecordFailedConditionType,
+						Status: string(corev1.ConditionFalse),
+					}},
+				},
+				{
+					DNSZone: unpublishedZone,
+					Conditions: []iov1.DNSZoneCondition{{
+						Type:   iov1.DNSRecordFailedConditionType,
+						Status: string(corev1.ConditionTrue),
+					}},
+				},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), dnsRecord)
+	r := &reconciler{
+		config:      Config{Namespace: "openshift-ingress-operator"},
+		client:      client,
+		dnsProvider: &dns.FakeProvider{},
+		recorder:    record.NewFakeRecorder(10),
+	}
+	if err := r.delete(dnsRecord); err != nil {
+		t.Fatalf("unexpected error from delete: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: "openshift-ingress-operator", Name: deletedRecordsConfigMapName}
+	if err := client.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get configmap %s: %v", name, err)
+	}
+	if len(cm.Data) != 1 {
+		t.Fatalf("expected 1 snapshot, got %d: %v", len(cm.Data), cm.Data)
+	}
+	for _, value := range cm.Data {
+		var snapshot recordSnapshot
+		if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+			t.Fatalf("failed to unmarshal snapshot: %v", err)
+		}
+		if snapshot.DNSName != dnsRecord.Spec.DNSName {
+			t.Errorf("expected snapshot DNS name %q, got %q", dnsRecord.Spec.DNSName, snapshot.DNSName)
+		}
+		if snapshot.RecordType != dnsRecord.Spec.RecordType {
+			t.Errorf("expected snapshot record type %q, got %q", dnsRecord.Spec.RecordType, snapshot.RecordType)
+		}
+		if !reflect.DeepEqual(snapshot.Targets, dnsRecord.Spec.Targets) {
+			t.Errorf("expected snapshot targets %v, got %v", dnsRecord.Spec.Targets, snapshot.Targets)
+		}
+		if !reflect.DeepEqual(snapshot.Zones, []configv1.DNSZone{publishedZone}) {
+			t.Errorf("expected snapshot zones %v, got %v", []configv1.DNSZone{publishedZone}, snapshot.Zones)
+		}
+	}
+}
+
+// TestPruneOldestSnapshots verifies that the oldest snapshots are pruned when
+// the configmap exceeds the snapshot limit.
+func TestPruneOldestSnapshots(t *testing.T) {
+	data := map[string]string{}
+	base := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxDeletedRecordSnapshots+5; i++ {
+		snapshot := recordSnapshot{
+			DeletedAt: metav1.NewTime(base.Add(time.Duration(i) * time.Minute)),
+			DNSName:   fmt.Sprintf("record-%d.example.com.", i),
+		}
+		value, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatalf("failed to marshal snapshot: %v", err)
+		}
+		data[fmt.Sprintf("record-%d", i)] = string(value)
+	}
+	pruneOldestSnapshots(data, maxDeletedRecordSnapshots)
+	if len(data) != maxDeletedRecordSnapshots {
+		t.Fatalf("expected %d snapshots after pruning, got %d", maxDeletedRecordSnapshots, len(data))
+	}
+	for i := 0; i < 5; i++ {
+		if _, ok := data[fmt.Sprintf("record-%d", i)]; ok {
+			t.Errorf("expected snapshot record-%d to be pruned", i)
+		}
+	}
+	if _, ok := data[fmt.Sprintf("record-%d", maxDeletedRecordSnapshots+4)]; !ok {
+		t.Error("expected the newest snapshot to be retained")
+	}
+}
@@ -0,0 +1,102 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+)
+
+// dnsTargetOverridesAnnotation can be set on an ingresscontroller to a JSON
+// object mapping zone IDs to arrays of targets.  When the operator publishes
+// the ingresscontroller's DNS records to a zone named in the map, it uses the
+// mapped targets instead of the record's own targets, so that (for example) a
+// split-horizon internal zone can point at an internal IP while the public
+// zone points at the load balancer hostname.  The operator mirrors the
+// overrides onto the DNS record itself so that the record is withdrawn with
+// the same per-zone targets even after the ingresscontroller is gone.
+const dnsTargetOverridesAnnotation = "ingress.operator.openshift.io/dns-target-overrides"
+
+// targetOverridesFromIngressAnnotation returns the per-zone target overrides
+// configured on the ingresscontroller, a boolean indicating whether the
+// ingresscontroller has overrides, and an error if the overrides are invalid.
+func targetOverridesFromIngressAnnotation(ingress *operatorv1.IngressController) (map[string][]string, bool, error) {
+	val, ok := ingress.Annotations[dnsTargetOverridesAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+	var overrides map[string][]string
+	if err := json.Unmarshal([]byte(val), &overrides); err != nil {
+		return nil, true, fmt.Errorf("failed to parse annotation value %q: %v", val, err)
+	}
+	if len(overrides) == 0 {
+		return nil, true, fmt.Errorf("at least one zone override must be specified")
+	}
+	for zoneID, targets := range overrides {
+		if len(zoneID) == 0 {
+			return nil, true, fmt.Errorf("zone ID must be non-empty")
+		}
+		if len(targets) == 0 {
+			return nil, true, fmt.Errorf("zone %q must specify at least one target", zoneID)
+		}
+		for _, target := range targets {
+			if len(target) == 0 {
+				return nil, true, fmt.Errorf("zone %q has an empty target", zoneID)
+			}
+		}
+	}
+	return overrides, true, nil
+}
+
+// syncTargetOverridesToRecord mirrors the ingresscontroller's target
+// overrides onto the DNS record, and returns the record to use along with a
+// boolean indicating whether the record needs to be updated.
+func syncTargetOverridesToRecord(record *iov1.DNSRecord, overrides map[string][]string, haveOverrides bool) (*iov1.DNSRecord, bool) {
+	desired := ""
+	if haveOverrides {
+		// Marshaling a map produces sorted keys, so the value is
+		// canonical.
+		data, err := json.Marshal(overrides)
+		if err != nil {
+			return record, false
+		}
+		desired = string(data)
+	}
+	if record.Annotations[dnsTargetOverridesAnnotation] == desired {
+		return record, false
+	}
+	updated := record.DeepCopy()
+	if len(desired) == 0 {
+		delete(updated.Annotations, dnsTargetOverridesAnnotation)
+	} else {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[dnsTargetOverridesAnnotation] = desired
+	}
+	return updated, true
+}
+
+// recordForZone returns the record to publish to or withdraw from the given
+// zone, applying any per-zone target override mirrored onto the record.
+func recordForZone(record *iov1.DNSRecord, zone *configv1.DNSZone) *iov1.DNSRecord {
+	val, ok := record.Annotations[dnsTargetOverridesAnnotation]
+	if !ok || len(zone.ID) == 0 {
+		return record
+	}
+	var overrides map[string][]string
+	if err := json.Unmarshal([]byte(val), &overrides); err != nil {
+		log.Error(err, "ignoring invalid DNS target overrides on dnsrecord", "dnsrecord", record.Name)
+		return record
+	}
+	targets, ok := overrides[zone.ID]
+	if !ok || len(targets) == 0 {
+		return record
+	}
+	overridden := record.DeepCopy()
+	overridden.Spec.Targets = targets
+	return overridden
+}
@@ -0,0 +1,289 @@
+package dns
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/dns"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestTargetOverridesFromIngressAnnotation(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotation     string
+		haveAnnotation bool
+		expectError    bool
+		expected       map[string][]string
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:    "valid overrides",
+			annotation:     `{"internal-zone":["10.0.0.1"],"public-zone":["lb.example.com"]}`,
+			haveAnnotation: true,
+			expected: map[string][]string{
+				"internal-zone": {"10.0.0.1"},
+				"public-zone":   {"lb.example.com"},
+			},
+		},
+		{
+			description:    "invalid JSON",
+			annotation:     `internal-zone=10.0.0.1`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+		{
+			description:    "empty map",
+			annotation:     `{}`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+		{
+			description:    "empty targets",
+			annotation:     `{"internal-zone":[]}`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+		{
+			description:    "empty target value",
+			annotation:     `{"internal-zone":[""]}`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+	}
+	for _, tc := range testCases {
+		ingress := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if tc.haveAnnotation {
+			ingress.Annotations = map[string]string{dnsTargetOverridesAnnotation: tc.annotation}
+		}
+		overrides, have, err := targetOverridesFromIngressAnnotation(ingress)
+		if have != tc.haveAnnotation {
+			t.Errorf("%q: expected have=%v, got %v", tc.description, tc.haveAnnotation, have)
+		}
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if !reflect.DeepEqual(overrides, tc.expected) {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expected, overrides)
+		}
+	}
+}
+
+func TestSyncTargetOverridesToRecord(t *testing.T) {
+	record := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-wildcard"},
+	}
+	overrides := map[string][]string{"internal-zone": {"10.0.0.1"}}
+
+	updated, changed := syncTargetOverridesToRecord(record, overrides, true)
+	if !changed {
+		t.Fatal("expected the record to be updated with the overrides")
+	}
+	if _, ok := updated.Annotations[dnsTargetOverridesAnnotation]; !ok {
+		t.Fatal("expected the overrides annotation to be set on the record")
+	}
+
+	// The same overrides do not cause another update.
+	if _, changed := syncTargetOverridesToRecord(updated, overrides, true); changed {
+		t.Error("expected no update for unchanged overrides")
+	}
+
+	// Removing the overrides removes the annotation.
+	cleaned, changed := syncTargetOverridesToRecord(updated, nil, false)
+	if !changed {
+		t.Fatal("expected the record to be updated when the overrides are removed")
+	}
+	if _, ok := cleaned.Annotations[dnsTargetOverridesAnnotation]; ok {
+		t.Error("expected the overrides annotation to be removed from the record")
+	}
+}
+
+// targetRecordingProvider records the targets published to each zone.
+type targetRecordingProvider struct {
+	dns.FakeProvider
+	published map[string][]string
+}
+
+func (p *targetRecordingProvider) Ensure(record *iov1.DNSRecord, zone configv1.DNSZone) error {
+	p.published[zone.ID] = record.Spec.Targets
+	return nil
+}
+
+func (p *targetRecordingProvider) Replace(record *iov1.DNSRecord, zone configv1.DNSZone) error {
+	return p.Ensure(record, zone)
+}
+
+// TestPublishRecordToZonesTargetOverrides verifies that distinct targets are
+// published to the internal and public zones when a per-zone override is
+// mirrored onto the record.
+func TestPublishRecordToZonesTargetOverrides(t *testing.T) {
+	record := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default-wildcard",
+			Annotations: map[string]string{
+				dnsTargetOverridesAnnotation: `{"internal-zone":["10.0.0.1"]}`,
+			},
+		},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.CNAMERecordType,
+			Targets:    []string{"lb.example.com"},
+			RecordTTL:  120,
+		},
+	}
+	zones := []configv1.DNSZone{
+		{ID: "internal-zone"},
+		{ID: "public-zone"},
+	}
+	provider := &targetRecordingProvider{published: map[string][]string{}}
+	r := &reconciler{dnsProvider: provider}
+	r.publishRecordToZones(zones, record, false)
+	if expected := []string{"10.0.0.1"}; !reflect.DeepEqual(provider.published["internal-zone"], expected) {
+		t.Errorf("expected targets %v in the internal zone, got %v", expected, provider.published["internal-zone"])
+	}
+	if expected := []string{"lb.example.com"}; !reflect.DeepEqual(provider.published["public-zone"], expected) {
+		t.Errorf("expected targets %v in the public zone, got %v", expected, provider.published["public-zone"])
+	}
+}
+
+// TestReconcileTargetOverrideAnnotationChanges verifies that adding the
+// target overrides annotation to an ingresscontroller causes the next
+// reconciliation of its wildcard dnsrecord to republish the overridden zone
+// with the new targets, and that removing the annotation republishes the
+// record's own targets.  The ingresscontroller watch added in New is what
+// triggers that reconciliation in a live cluster.
+func TestReconcileTargetOverrideAnnotationChanges(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.NonePlatformType,
+			},
+		},
+	}
+	dnsConfig := &configv1.DNS{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.DNSSpec{
+			PrivateZone: &configv1.DNSZone{ID: "internal-zone"},
+			PublicZone:  &configv1.DNSZone{ID: "public-zone"},
+		},
+	}
+	ingress := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "default",
+		},
+	}
+	wildcard := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "default-wildcard",
+			Labels: map[string]string{
+				manifests.OwningIngressControllerLabel: "default",
+			},
+		},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    "*.apps.example.com.",
+			RecordType: iov1.CNAMERecordType,
+			Targets:    []string{"lb.example.com"},
+			RecordTTL:  120,
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, dnsConfig, ingress, wildcard)
+	provider := &targetRecordingProvider{published: map[string][]string{}}
+	r := &reconciler{
+		config:      Config{Namespace: namespace},
+		client:      client,
+		cache:       &fakeCache{reader: client},
+		dnsProvider: provider,
+		// Pre-set the infrastructure config so that
+		// createDNSProviderIfNeeded does not replace the recording
+		// provider.
+		infraConfig: infraConfig,
+		recorder:    record.NewFakeRecorder(10),
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: namespace, Name: "default-wildcard"},
+	}
+
+	// Without the annotation, the record's own targets are published to
+	// both zones.
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	for _, zone := range []string{"internal-zone", "public-zone"} {
+		if expected := []string{"lb.example.com"}; !reflect.DeepEqual(provider.published[zone], expected) {
+			t.Errorf("expected targets %v in zone %s, got %v", expected, zone, provider.published[zone])
+		}
+	}
+
+	// Adding the annotation republishes the overridden zone with the new
+	// targets.
+	ingress.Annotations = map[string]string{
+		dnsTargetOverridesAnnotation: `{"internal-zone":["10.0.0.1"]}`,
+	}
+	if err := client.Update(context.TODO(), ingress); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if expected := []string{"10.0.0.1"}; !reflect.DeepEqual(provider.published["internal-zone"], expected) {
+		t.Errorf("expected targets %v in the internal zone, got %v", expected, provider.published["internal-zone"])
+	}
+	if expected := []string{"lb.example.com"}; !reflect.DeepEqual(provider.published["public-zone"], expected) {
+		t.Errorf("expected targets %v in the public zone, got %v", expected, provider.published["public-zone"])
+	}
+	mirrored := &iov1.DNSRecord{}
+	if err := client.Get(context.TODO(), request.NamespacedName, mirrored); err != nil {
+		t.Fatalf("failed to get dnsrecord: %v", err)
+	}
+	if _, ok := mirrored.Annotations[dnsTargetOverridesAnnotation]; !ok {
+		t.Error("expected the overrides annotation to be mirrored onto the record")
+	}
+
+	// Removing the annotation republishes the record's own targets.
+	ingress.Annotations = nil
+	if err := client.Update(context.TODO(), ingress); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if expected := []string{"lb.example.com"}; !reflect.DeepEqual(provider.published["internal-zone"], expected) {
+		t.Errorf("expected targets %v in the internal zone, got %v", expected, provider.published["internal-zone"])
+	}
+	cleaned := &iov1.DNSRecord{}
+	if err := client.Get(context.TODO(), request.NamespacedName, cleaned); err != nil {
+		t.Fatalf("failed to get dnsrecord: %v", err)
+	}
+	if _, ok := cleaned.Annotations[dnsTargetOverridesAnnotation]; ok {
+		t.Error("expected the overrides annotation to be removed from the record")
+	}
+}
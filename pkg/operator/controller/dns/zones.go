@@ -0,0 +1,61 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+)
+
+// dnsZonesAnnotation can be set on an ingresscontroller to a JSON array of
+// DNS zones (in the configv1.DNSZone format, so each zone is given by ID or
+// by tags) into which the operator publishes the ingresscontroller's DNS
+// records, overriding the zones inferred from the cluster DNS config.  A zone
+// may carry a "domain" tag naming the zone's base domain; if it does, the
+// operator verifies that the record's DNS name is within that domain before
+// publishing.
+const dnsZonesAnnotation = "ingress.operator.openshift.io/dns-zones"
+
+// zonesFromIngressAnnotation returns the DNS zones configured on the
+// ingresscontroller, a boolean indicating whether the ingresscontroller has a
+// zone override, and an error if the override is invalid.
+func zonesFromIngressAnnotation(ingress *operatorv1.IngressController) ([]configv1.DNSZone, bool, error) {
+	val, ok := ingress.Annotations[dnsZonesAnnotation]
+	if !ok {
+		return nil, false, nil
+	}
+	var zones []configv1.DNSZone
+	if err := json.Unmarshal([]byte(val), &zones); err != nil {
+		return nil, true, fmt.Errorf("failed to parse annotation value %q: %v", val, err)
+	}
+	if len(zones) == 0 {
+		return nil, true, fmt.Errorf("at least one zone must be specified")
+	}
+	for i, zone := range zones {
+		if len(zone.ID) == 0 && len(zone.Tags) == 0 {
+			return nil, true, fmt.Errorf("zone %d must specify an ID or tags", i)
+		}
+	}
+	return zones, true, nil
+}
+
+// validateRecordInZones verifies that the record's DNS name is within each
+// zone that declares its base domain with a "domain" tag.  Zones that do not
+// declare a domain cannot be checked and are accepted as-is.
+func validateRecordInZones(zones []configv1.DNSZone, record *iov1.DNSRecord) error {
+	dnsName := strings.TrimSuffix(record.Spec.DNSName, ".")
+	for _, zone := range zones {
+		domain, ok := zone.Tags["domain"]
+		if !ok {
+			continue
+		}
+		if !strings.HasSuffix(dnsName, "."+domain) && dnsName != domain {
+			return fmt.Errorf("record %q is not within zone domain %q", record.Spec.DNSName, domain)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package dns
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestZonesFromIngressAnnotation(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotation     string
+		haveAnnotation bool
+		expectOverride bool
+		expectError    bool
+		expectedZones  []configv1.DNSZone
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:    "zone by ID",
+			annotation:     `[{"id":"Z123456"}]`,
+			haveAnnotation: true,
+			expectOverride: true,
+			expectedZones:  []configv1.DNSZone{{ID: "Z123456"}},
+		},
+		{
+			description:    "zones by ID and by tags",
+			annotation:     `[{"id":"Z123456"},{"tags":{"Name":"private-zone"}}]`,
+			haveAnnotation: true,
+			expectOverride: true,
+			expectedZones: []configv1.DNSZone{
+				{ID: "Z123456"},
+				{Tags: map[string]string{"Name": "private-zone"}},
+			},
+		},
+		{
+			description:    "invalid JSON",
+			annotation:     `Z123456`,
+			haveAnnotation: true,
+			expectOverride: true,
+			expectError:    true,
+		},
+		{
+			description:    "empty zone list",
+			annotation:     `[]`,
+			haveAnnotation: true,
+			expectOverride: true,
+			expectError:    true,
+		},
+		{
+			description:    "zone without ID or tags",
+			annotation:     `[{}]`,
+			haveAnnotation: true,
+			expectOverride: true,
+			expectError:    true,
+		},
+	}
+	for _, tc := range testCases {
+		ingress := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if tc.haveAnnotation {
+			ingress.Annotations = map[string]string{dnsZonesAnnotation: tc.annotation}
+		}
+		zones, haveOverride, err := zonesFromIngressAnnotation(ingress)
+		if tc.expectError && err == nil {
+			t.Errorf("%q: expected error, got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+		if haveOverride != tc.expectOverride {
+			t.Errorf("%q: expected override=%v, got %v", tc.description, tc.expectOverride, haveOverride)
+		}
+		if err != nil {
+			continue
+		}
+		if len(zones) != len(tc.expectedZones) {
+			t.Errorf("%q: expected %d zones, got %d", tc.description, len(tc.expectedZones), len(zones))
+			continue
+		}
+		for i := range zones {
+			if zones[i].ID != tc.expectedZones[i].ID {
+				t.Errorf("%q: zone %d: expected ID %q, got %q", tc.description, i, tc.expectedZones[i].ID, zones[i].ID)
+			}
+		}
+	}
+}
+
+func TestValidateRecordInZones(t *testing.T) {
+	record := func(dnsName string) *iov1.DNSRecord {
+		return &iov1.DNSRecord{
+			Spec: iov1.DNSRecordSpec{DNSName: dnsName},
+		}
+	}
+	testCases := []struct {
+		description string
+		zones       []configv1.DNSZone
+		record      *iov1.DNSRecord
+		expectError bool
+	}{
+		{
+			description: "zone without domain tag is accepted",
+			zones:       []configv1.DNSZone{{ID: "Z123456"}},
+			record:      record("*.apps.openshift.example.com."),
+		},
+		{
+			description: "record within the zone domain",
+			zones:       []configv1.DNSZone{{ID: "Z123456", Tags: map[string]string{"domain": "example.com"}}},
+			record:      record("*.apps.openshift.example.com."),
+		},
+		{
+			description: "record outside the zone domain",
+			zones:       []configv1.DNSZone{{ID: "Z123456", Tags: map[string]string{"domain": "other.test"}}},
+			record:      record("*.apps.openshift.example.com."),
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		err := validateRecordInZones(tc.zones, tc.record)
+		if tc.expectError && err == nil {
+			t.Errorf("%q: expected error, got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+	}
+}
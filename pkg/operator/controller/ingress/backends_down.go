@@ -0,0 +1,140 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RouterBackendsDownPolicyAnnotation can be set on an ingresscontroller
+	// to change what the router returns when all of a route's backends are
+	// down.  The value is either "status=<code>" to return the given
+	// status code (400-599) instead of the default 503, or
+	// "redirect=<url>" to redirect to the given absolute URL.  A route can
+	// override the policy with its own annotations; the router gives the
+	// route-level setting precedence.
+	RouterBackendsDownPolicyAnnotation = "ingress.operator.openshift.io/backends-down-policy"
+
+	// RouterBackendsDownErrorPageAnnotation can be set on an
+	// ingresscontroller to the name of a configmap in the operand
+	// namespace whose "error-page.http" key holds a raw HTTP response that
+	// the router serves when all of a route's backends are down, in place
+	// of the built-in 503 page.
+	RouterBackendsDownErrorPageAnnotation = "ingress.operator.openshift.io/backends-down-error-page"
+
+	// RouterBackendsDownStatusEnvName sets the status code that the router
+	// returns when all of a route's backends are down.
+	RouterBackendsDownStatusEnvName = "ROUTER_BACKENDS_DOWN_STATUS"
+
+	// RouterBackendsDownRedirectEnvName sets a URL to which the router
+	// redirects when all of a route's backends are down.
+	RouterBackendsDownRedirectEnvName = "ROUTER_BACKENDS_DOWN_REDIRECT"
+
+	// RouterErrorFile503EnvName points the router at a file holding a raw
+	// HTTP response to serve in place of the built-in 503 page.
+	RouterErrorFile503EnvName = "ROUTER_ERRORFILE_503"
+
+	// routerErrorPageKey is the configmap key that holds the error page.
+	routerErrorPageKey = "error-page.http"
+
+	// routerErrorPageVolumeName is the name of the volume through which
+	// the error page is mounted into the router pods.
+	routerErrorPageVolumeName = "error-page"
+
+	// routerErrorPageMountPath is where the error page is mounted in the
+	// router pods.
+	routerErrorPageMountPath = "/var/lib/haproxy/conf/error_pages"
+)
+
+// backendsDownPolicyFromAnnotation parses the backends-down policy configured
+// on the ingresscontroller.  It returns a status code or a redirect URL
+// (exactly one is set) and a boolean indicating whether a valid policy is
+// configured.
+func backendsDownPolicyFromAnnotation(ic *operatorv1.IngressController) (int, string, bool) {
+	val, ok := ic.Annotations[RouterBackendsDownPolicyAnnotation]
+	if !ok {
+		return 0, "", false
+	}
+	switch {
+	case strings.HasPrefix(val, "status="):
+		status, err := strconv.Atoi(strings.TrimPrefix(val, "status="))
+		if err != nil || status < 400 || status > 599 {
+			log.Info("ignoring invalid annotation value", "annotation", RouterBackendsDownPolicyAnnotation, "value", val)
+			return 0, "", false
+		}
+		return status, "", true
+	case strings.HasPrefix(val, "redirect="):
+		redirect := strings.TrimPrefix(val, "redirect=")
+		u, err := url.Parse(redirect)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			log.Info("ignoring invalid annotation value", "annotation", RouterBackendsDownPolicyAnnotation, "value", val)
+			return 0, "", false
+		}
+		return 0, redirect, true
+	}
+	log.Info("ignoring invalid annotation value", "annotation", RouterBackendsDownPolicyAnnotation, "value", val)
+	return 0, "", false
+}
+
+// validateErrorPageConfigMap returns an error if the named configmap does not
+// exist in the operand namespace or does not have a non-empty error page key.
+func (r *reconciler) validateErrorPageConfigMap(name string) error {
+	cm := &corev1.ConfigMap{}
+	cmName := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: name}
+	if err := r.client.Get(context.TODO(), cmName, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("configmap %s does not exist", cmName)
+		}
+		return fmt.Errorf("failed to get configmap %s: %v", cmName, err)
+	}
+	if len(strings.TrimSpace(cm.Data[routerErrorPageKey])) == 0 {
+		return fmt.Errorf("configmap %s is missing the %q key", cmName, routerErrorPageKey)
+	}
+	return nil
+}
+
+// applyBackendsDownErrorPage mounts any configured custom error page into the
+// router deployment and points the router at it.  A missing or empty
+// configmap leaves the built-in 503 page in place.
+func (r *reconciler) applyBackendsDownErrorPage(ci *operatorv1.IngressController, deployment *appsv1.Deployment) {
+	name, ok := ci.Annotations[RouterBackendsDownErrorPageAnnotation]
+	if !ok || len(name) == 0 {
+		return
+	}
+	if err := r.validateErrorPageConfigMap(name); err != nil {
+		log.Error(err, "not configuring custom error page for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name)
+		return
+	}
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: routerErrorPageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		},
+	})
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      routerErrorPageVolumeName,
+		MountPath: routerErrorPageMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  RouterErrorFile503EnvName,
+		Value: filepath.Join(routerErrorPageMountPath, routerErrorPageKey),
+	})
+}
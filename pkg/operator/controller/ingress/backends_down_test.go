@@ -0,0 +1,168 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDesiredRouterDeploymentBackendsDownPolicy(t *testing.T) {
+	testCases := []struct {
+		description      string
+		annotation       string
+		expectedStatus   string
+		expectedRedirect string
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:    "valid status",
+			annotation:     "status=404",
+			expectedStatus: "404",
+		},
+		{
+			description:      "valid redirect",
+			annotation:       "redirect=https://maintenance.example.com/down",
+			expectedRedirect: "https://maintenance.example.com/down",
+		},
+		{
+			description: "status outside 400-599 is ignored",
+			annotation:  "status=302",
+		},
+		{
+			description: "unparseable status is ignored",
+			annotation:  "status=teapot",
+		},
+		{
+			description: "relative redirect is ignored",
+			annotation:  "redirect=/down",
+		},
+		{
+			description: "unrecognized policy is ignored",
+			annotation:  "page=fancy",
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ci.Annotations = map[string]string{RouterBackendsDownPolicyAnnotation: tc.annotation}
+		}
+		deployment, err := desiredRouterDeployment(ci, "quay.io/openshift/router:latest", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		if status := findEnv(deployment, RouterBackendsDownStatusEnvName); status != tc.expectedStatus {
+			t.Errorf("%q: expected %s=%q, got %q", tc.description, RouterBackendsDownStatusEnvName, tc.expectedStatus, status)
+		}
+		if redirect := findEnv(deployment, RouterBackendsDownRedirectEnvName); redirect != tc.expectedRedirect {
+			t.Errorf("%q: expected %s=%q, got %q", tc.description, RouterBackendsDownRedirectEnvName, tc.expectedRedirect, redirect)
+		}
+	}
+}
+
+func TestApplyBackendsDownErrorPage(t *testing.T) {
+	errorPageConfigMap := func(data string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: controller.DefaultOperandNamespace,
+				Name:      "custom-error-page",
+			},
+			Data: map[string]string{
+				routerErrorPageKey: data,
+			},
+		}
+	}
+	testCases := []struct {
+		description string
+		annotation  string
+		configmap   *corev1.ConfigMap
+		expectApply bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "valid error page",
+			annotation:  "custom-error-page",
+			configmap:   errorPageConfigMap("HTTP/1.0 503 Service Unavailable\r\n\r\nbe right back\n"),
+			expectApply: true,
+		},
+		{
+			description: "empty error page is rejected",
+			annotation:  "custom-error-page",
+			configmap:   errorPageConfigMap(""),
+		},
+		{
+			description: "missing configmap",
+			annotation:  "custom-error-page",
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ci.Annotations = map[string]string{RouterBackendsDownErrorPageAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.configmap != nil {
+			objs = append(objs, tc.configmap)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		deployment, err := desiredRouterDeployment(ci, "quay.io/openshift/router:latest", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		r.applyBackendsDownErrorPage(ci, deployment)
+		env := findEnv(deployment, RouterErrorFile503EnvName)
+		haveVolume := false
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.Name == routerErrorPageVolumeName {
+				haveVolume = true
+			}
+		}
+		if tc.expectApply {
+			if env == "" {
+				t.Errorf("%q: expected the %s env var to be set", tc.description, RouterErrorFile503EnvName)
+			}
+			if !haveVolume {
+				t.Errorf("%q: expected the %s volume to be added", tc.description, routerErrorPageVolumeName)
+			}
+		} else {
+			if env != "" {
+				t.Errorf("%q: expected no %s env var, got %q", tc.description, RouterErrorFile503EnvName, env)
+			}
+			if haveVolume {
+				t.Errorf("%q: expected no %s volume", tc.description, routerErrorPageVolumeName)
+			}
+		}
+	}
+}
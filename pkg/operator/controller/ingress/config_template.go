@@ -0,0 +1,170 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RouterConfigTemplateAnnotation can be set on an ingresscontroller to
+	// the name of a configmap in the operand namespace whose
+	// "haproxy-config.template" key holds a customized router config
+	// template.  The operator validates the template and mounts it into
+	// the router pods; an invalid template is reported through the
+	// "ConfigTemplateValid" status condition, and the router keeps its
+	// built-in template.
+	RouterConfigTemplateAnnotation = "ingress.operator.openshift.io/config-template"
+
+	// routerConfigTemplateKey is the configmap key that holds the
+	// customized config template.
+	routerConfigTemplateKey = "haproxy-config.template"
+
+	// routerConfigTemplateVolumeName is the name of the volume through
+	// which the customized template is mounted into the router pods.
+	routerConfigTemplateVolumeName = "config-template"
+
+	// routerConfigTemplateMountPath is where the customized template is
+	// mounted in the router pods.
+	routerConfigTemplateMountPath = "/var/lib/haproxy/conf/custom"
+
+	// RouterTemplateFileEnvName points the router at a customized config
+	// template.
+	RouterTemplateFileEnvName = "TEMPLATE_FILE"
+
+	// IngressControllerConfigTemplateValidConditionType is the type of the
+	// status condition that reports whether any configured config template
+	// is valid.
+	IngressControllerConfigTemplateValidConditionType = "ConfigTemplateValid"
+)
+
+// disallowedTemplateDirectives are HAProxy directives that a customized
+// config template must not introduce, because they allow the router process
+// to execute arbitrary programs or code.
+var disallowedTemplateDirectives = []string{
+	"external-check",
+	"insecure-fork-wanted",
+	"lua-load",
+}
+
+// configTemplateFromAnnotation returns the name of the config template
+// configmap configured on the ingresscontroller, and a boolean indicating
+// whether one is configured.
+func configTemplateFromAnnotation(ic *operatorv1.IngressController) (string, bool) {
+	val, ok := ic.Annotations[RouterConfigTemplateAnnotation]
+	if !ok || len(val) == 0 {
+		return "", false
+	}
+	return val, true
+}
+
+// validateConfigTemplate returns an error if the named configmap does not
+// exist in the operand namespace, does not have the template key, or holds a
+// template that is malformed or uses a disallowed directive.
+func (r *reconciler) validateConfigTemplate(name string) error {
+	cm := &corev1.ConfigMap{}
+	cmName := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: name}
+	if err := r.client.Get(context.TODO(), cmName, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("configmap %s does not exist", cmName)
+		}
+		return fmt.Errorf("failed to get configmap %s: %v", cmName, err)
+	}
+	data, ok := cm.Data[routerConfigTemplateKey]
+	if !ok {
+		return fmt.Errorf("configmap %s is missing the %q key", cmName, routerConfigTemplateKey)
+	}
+	return validateConfigTemplateData(data)
+}
+
+// validateConfigTemplateData returns an error if the given template data is
+// malformed or uses a disallowed directive.  The template language the router
+// uses has functions that are not known here, so the syntax check is limited
+// to balanced template actions.
+func validateConfigTemplateData(data string) error {
+	if len(strings.TrimSpace(data)) == 0 {
+		return fmt.Errorf("the template is empty")
+	}
+	if opens, closes := strings.Count(data, "{{"), strings.Count(data, "}}"); opens != closes {
+		return fmt.Errorf("the template has %d \"{{\" but %d \"}}\"", opens, closes)
+	}
+	for _, directive := range disallowedTemplateDirectives {
+		if strings.Contains(data, directive) {
+			return fmt.Errorf("the template uses the disallowed directive %q", directive)
+		}
+	}
+	return nil
+}
+
+// applyConfigTemplate mounts any configured and valid config template into
+// the router deployment and points the router at it.  An invalid template
+// leaves the built-in template in place; the "ConfigTemplateValid" status
+// condition reports the problem.
+func (r *reconciler) applyConfigTemplate(ci *operatorv1.IngressController, deployment *appsv1.Deployment) {
+	name, ok := configTemplateFromAnnotation(ci)
+	if !ok {
+		return
+	}
+	if err := r.validateConfigTemplate(name); err != nil {
+		log.Error(err, "not configuring config template for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name)
+		return
+	}
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: routerConfigTemplateVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			},
+		},
+	})
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      routerConfigTemplateVolumeName,
+		MountPath: routerConfigTemplateMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  RouterTemplateFileEnvName,
+		Value: filepath.Join(routerConfigTemplateMountPath, routerConfigTemplateKey),
+	})
+}
+
+// computeConfigTemplateCondition computes the ingresscontroller's
+// "ConfigTemplateValid" status condition, which reports whether any
+// configured config template is valid.
+func (r *reconciler) computeConfigTemplateCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	name, ok := configTemplateFromAnnotation(ic)
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerConfigTemplateValidConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NotConfigured",
+			Message: "No config template is configured.",
+		}
+	}
+	if err := r.validateConfigTemplate(name); err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerConfigTemplateValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidTemplate",
+			Message: fmt.Sprintf("The configured config template is not used: %v", err),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerConfigTemplateValidConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Valid",
+		Message: fmt.Sprintf("The router uses the config template from configmap %q.", name),
+	}
+}
@@ -0,0 +1,159 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func configTemplateConfigMap(data string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.DefaultOperandNamespace,
+			Name:      "custom-template",
+		},
+		Data: map[string]string{
+			routerConfigTemplateKey: data,
+		},
+	}
+}
+
+func TestApplyConfigTemplate(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  string
+		configmap   *corev1.ConfigMap
+		expectApply bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "accepted template",
+			annotation:  "custom-template",
+			configmap:   configTemplateConfigMap("global\n{{ range .Routes }}{{ end }}\n"),
+			expectApply: true,
+		},
+		{
+			description: "template with a disallowed directive is rejected",
+			annotation:  "custom-template",
+			configmap:   configTemplateConfigMap("global\n  lua-load /tmp/evil.lua\n"),
+		},
+		{
+			description: "template with unbalanced actions is rejected",
+			annotation:  "custom-template",
+			configmap:   configTemplateConfigMap("{{ range .Routes }\n"),
+		},
+		{
+			description: "missing configmap",
+			annotation:  "custom-template",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{RouterConfigTemplateAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.configmap != nil {
+			objs = append(objs, tc.configmap)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		r.applyConfigTemplate(ic, deployment)
+		env := findEnv(deployment, RouterTemplateFileEnvName)
+		haveVolume := false
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.Name == routerConfigTemplateVolumeName {
+				haveVolume = true
+			}
+		}
+		if tc.expectApply {
+			if env == "" {
+				t.Errorf("%q: expected the %s env var to be set", tc.description, RouterTemplateFileEnvName)
+			}
+			if !haveVolume {
+				t.Errorf("%q: expected the %s volume to be added", tc.description, routerConfigTemplateVolumeName)
+			}
+		} else {
+			if env != "" {
+				t.Errorf("%q: expected no %s env var, got %q", tc.description, RouterTemplateFileEnvName, env)
+			}
+			if haveVolume {
+				t.Errorf("%q: expected no %s volume", tc.description, routerConfigTemplateVolumeName)
+			}
+		}
+	}
+}
+
+func TestComputeConfigTemplateCondition(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotation     string
+		configmap      *corev1.ConfigMap
+		expectedStatus operatorv1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			description:    "not configured",
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "NotConfigured",
+		},
+		{
+			description:    "accepted template",
+			annotation:     "custom-template",
+			configmap:      configTemplateConfigMap("global\n"),
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "Valid",
+		},
+		{
+			description:    "rejected template",
+			annotation:     "custom-template",
+			configmap:      configTemplateConfigMap("external-check\n"),
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "InvalidTemplate",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{RouterConfigTemplateAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.configmap != nil {
+			objs = append(objs, tc.configmap)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		condition := r.computeConfigTemplateCondition(ic)
+		if condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+			t.Errorf("%q: expected status %q with reason %q, got %q with reason %q", tc.description, tc.expectedStatus, tc.expectedReason, condition.Status, condition.Reason)
+		}
+	}
+}
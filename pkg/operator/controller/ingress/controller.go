@@ -3,8 +3,11 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	iov1 "github.com/openshift/api/operatoringress/v1"
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
@@ -30,8 +33,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -42,12 +47,14 @@ const (
 
 // TODO: consider moving these to openshift/api
 const (
-	IngressControllerAdmittedConditionType                       = "Admitted"
-	IngressControllerPodsScheduledConditionType                  = "PodsScheduled"
-	IngressControllerDeploymentAvailableConditionType            = "DeploymentAvailable"
-	IngressControllerDeploymentReplicasMinAvailableConditionType = "DeploymentReplicasMinAvailable"
-	IngressControllerDeploymentReplicasAllAvailableConditionType = "DeploymentReplicasAllAvailable"
-	IngressControllerCanaryCheckSuccessConditionType             = "CanaryChecksSucceeding"
+	IngressControllerAdmittedConditionType                                = "Admitted"
+	IngressControllerPodsScheduledConditionType                           = "PodsScheduled"
+	IngressControllerDeploymentAvailableConditionType                     = "DeploymentAvailable"
+	IngressControllerDeploymentReplicasMinAvailableConditionType          = "DeploymentReplicasMinAvailable"
+	IngressControllerDeploymentReplicasAllAvailableConditionType          = "DeploymentReplicasAllAvailable"
+	IngressControllerCanaryCheckSuccessConditionType                      = "CanaryChecksSucceeding"
+	IngressControllerExternalEndpointCheckSuccessConditionType            = "ExternalEndpointChecksSucceeding"
+	IngressControllerLoadBalancerProviderParametersSupportedConditionType = "LoadBalancerProviderParametersSupported"
 )
 
 var (
@@ -66,17 +73,22 @@ var (
 // The controller will be pre-configured to watch for IngressController resources
 // in the manager namespace.
 func New(mgr manager.Manager, config Config) (controller.Controller, error) {
+	if err := validateMinimumTLSVersion(config.MinimumTLSVersion); err != nil {
+		return nil, err
+	}
 	reconciler := &reconciler{
-		config:   config,
-		client:   mgr.GetClient(),
-		cache:    mgr.GetCache(),
-		recorder: mgr.GetEventRecorderFor(controllerName),
+		config:           config,
+		client:           mgr.GetClient(),
+		cache:            mgr.GetCache(),
+		recorder:         mgr.GetEventRecorderFor(controllerName),
+		createCooldowns:  map[string]time.Time{},
+		deferredRollouts: map[string]time.Time{},
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: reconciler})
 	if err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(&source.Kind{Type: &operatorv1.IngressController{}}, &handler.EnqueueRequestForObject{}, desiredStateChangedPredicate); err != nil {
 		return nil, err
 	}
 	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, enqueueRequestForOwningIngressController(config.Namespace)); err != nil {
@@ -88,12 +100,37 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 	if err := c.Watch(&source.Kind{Type: &iov1.DNSRecord{}}, &handler.EnqueueRequestForOwner{OwnerType: &operatorv1.IngressController{}}); err != nil {
 		return nil, err
 	}
-	if err := c.Watch(&source.Kind{Type: &configv1.Ingress{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ingressConfigToIngressController)}); err != nil {
+	if err := c.Watch(&source.Kind{Type: &configv1.Ingress{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(reconciler.ingressConfigToIngressController)}, desiredStateChangedPredicate); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
+// desiredStateChangedPredicate filters out update events that cannot affect
+// the desired state of the operands, such as status-only updates.  Only
+// updates that change the object's generation (that is, its spec),
+// annotations, labels, or deletion timestamp are propagated.  Operand watches
+// must not use this predicate because operand status feeds the
+// ingresscontroller's status conditions.
+var desiredStateChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld == nil || e.MetaNew == nil {
+			return true
+		}
+		switch {
+		case e.MetaNew.GetGeneration() != e.MetaOld.GetGeneration():
+			return true
+		case !reflect.DeepEqual(e.MetaNew.GetAnnotations(), e.MetaOld.GetAnnotations()):
+			return true
+		case !reflect.DeepEqual(e.MetaNew.GetLabels(), e.MetaOld.GetLabels()):
+			return true
+		case !e.MetaNew.GetDeletionTimestamp().Equal(e.MetaOld.GetDeletionTimestamp()):
+			return true
+		}
+		return false
+	},
+}
+
 func (r *reconciler) ingressConfigToIngressController(o handler.MapObject) []reconcile.Request {
 	var requests []reconcile.Request
 	controllers := &operatorv1.IngressControllerList{}
@@ -139,6 +176,17 @@ func enqueueRequestForOwningIngressController(namespace string) handler.EventHan
 type Config struct {
 	Namespace              string
 	IngressControllerImage string
+	// RouteMetricsChunkSize is the page size used when listing routes for
+	// route metrics.  If zero, routeMetricsDefaultChunkSize is used.
+	RouteMetricsChunkSize int64
+	// AdditionalOwnerReference, if set, names an object ("Kind/name" in
+	// the operand namespace) that is added as an additional owner of the
+	// router deployments that the reconciler creates.
+	AdditionalOwnerReference string
+	// MinimumTLSVersion, if set, is a floor on the minimum TLS version of
+	// the router's effective TLS profile.  An ingresscontroller can tighten
+	// its profile beyond the floor but not loosen it.
+	MinimumTLSVersion configv1.TLSProtocolVersion
 }
 
 // reconciler handles the actual ingress reconciliation logic in response to
@@ -149,6 +197,15 @@ type reconciler struct {
 	client   client.Client
 	cache    cache.Cache
 	recorder record.EventRecorder
+
+	// createCooldowns tracks, per managed object, when a failed create
+	// may be retried.  See createManagedObject.
+	createCooldowns map[string]time.Time
+
+	// deferredRollouts tracks, per ingresscontroller, the next maintenance
+	// window start at which a deferred router deployment update will be
+	// applied.  See updateRouterDeployment.
+	deferredRollouts map[string]time.Time
 }
 
 // admissionRejection is an error type for ingresscontroller admission
@@ -569,6 +626,13 @@ func (r *reconciler) ensureIngressDeleted(ingress *operatorv1.IngressController)
 		errs = append(errs, fmt.Errorf("load balancer service exists for ingress %s/%s", ingress.Namespace, ingress.Name))
 	}
 
+	// Remove the ingresscontroller from the shared wildcard DNS record's
+	// owners.  The shared record is deleted only after its last owner is
+	// removed.
+	if err := r.releaseSharedWildcardDNSRecord(ingress); err != nil {
+		errs = append(errs, fmt.Errorf("failed to release shared wildcard dnsrecord for ingress %s/%s: %v", ingress.Namespace, ingress.Name, err))
+	}
+
 	// Delete the wildcard DNS record, and block ingresscontroller finalization
 	// until the dnsrecord has been finalized.
 	if err := r.deleteWildcardDNSRecord(ingress); err != nil {
@@ -667,28 +731,84 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		errs = append(errs, fmt.Errorf("failed to ensure load balancer service for %s: %v", ci.Name, err))
 	} else {
 		lbService = lb
-		if _, record, err := r.ensureWildcardDNSRecord(ci, lbService, haveLB); err != nil {
-			errs = append(errs, fmt.Errorf("failed to ensure wildcard dnsrecord for %s: %v", ci.Name, err))
+		if target, ok := sharedWildcardDNSTarget(ci); ok {
+			// The ingresscontroller publishes through the shared
+			// wildcard record instead of a per-controller record,
+			// but only if the shared record covers its domain.  On
+			// a conflict, keep the per-controller record so the
+			// shard's DNS keeps working, and flag the conflict via
+			// an event and the SharedWildcardDNSRecordReady status
+			// condition.
+			if conflict, existingDNSName, err := r.sharedWildcardDNSRecordConflict(ci); err != nil {
+				errs = append(errs, fmt.Errorf("failed to check the shared wildcard dnsrecord for %s: %v", ci.Name, err))
+			} else if conflict {
+				r.recorder.Eventf(ci, "Warning", "SharedWildcardDNSRecordConflict", "The shared wildcard dnsrecord has DNS name %q, which does not cover domain %q; keeping the per-controller wildcard dnsrecord.", existingDNSName, ci.Status.Domain)
+				if _, record, err := r.ensureWildcardDNSRecord(ci, lbService, haveLB); err != nil {
+					errs = append(errs, fmt.Errorf("failed to ensure wildcard dnsrecord for %s: %v", ci.Name, err))
+				} else {
+					wildcardRecord = record
+				}
+			} else {
+				if err := r.deleteWildcardDNSRecord(ci); err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete wildcard dnsrecord for %s: %v", ci.Name, err))
+				}
+				if _, record, err := r.ensureSharedWildcardDNSRecord(ci, target); err != nil {
+					errs = append(errs, fmt.Errorf("failed to ensure shared wildcard dnsrecord for %s: %v", ci.Name, err))
+				} else {
+					wildcardRecord = record
+				}
+			}
 		} else {
-			wildcardRecord = record
+			if err := r.releaseSharedWildcardDNSRecord(ci); err != nil {
+				errs = append(errs, fmt.Errorf("failed to release shared wildcard dnsrecord for %s: %v", ci.Name, err))
+			}
+			if _, record, err := r.ensureWildcardDNSRecord(ci, lbService, haveLB); err != nil {
+				errs = append(errs, fmt.Errorf("failed to ensure wildcard dnsrecord for %s: %v", ci.Name, err))
+			} else {
+				wildcardRecord = record
+			}
 		}
 	}
 
-	if _, _, err := r.ensureNodePortService(ci, deploymentRef); err != nil {
-		errs = append(errs, err)
-	}
-
-	if internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef); err != nil {
-		errs = append(errs, fmt.Errorf("failed to create internal router service for ingresscontroller %s: %v", ci.Name, err))
-	} else if err := r.ensureMetricsIntegration(ci, internalSvc, deploymentRef); err != nil {
-		errs = append(errs, fmt.Errorf("failed to integrate metrics with openshift-monitoring for ingresscontroller %s: %v", ci.Name, err))
-	}
-
-	if _, _, err := r.ensureRsyslogConfigMap(ci, deploymentRef, ingressConfig); err != nil {
-		errs = append(errs, err)
-	}
-
-	if _, _, err := r.ensureRouterPodDisruptionBudget(ci, deploymentRef); err != nil {
+	// The remaining resources are independent of one another, so run them
+	// as an orchestration that attempts every step and aggregates the
+	// failures rather than hiding later problems behind the first one.
+	if err := runEnsures([]ensureStep{
+		{
+			name: fmt.Sprintf("nodeport service for ingresscontroller %s", ci.Name),
+			run: func() error {
+				_, _, err := r.ensureNodePortService(ci, deploymentRef)
+				return err
+			},
+		},
+		{
+			name: fmt.Sprintf("internal router service and metrics integration for ingresscontroller %s", ci.Name),
+			run: func() error {
+				internalSvc, err := r.ensureInternalIngressControllerService(ci, deploymentRef)
+				if err != nil {
+					return fmt.Errorf("failed to create internal router service: %v", err)
+				}
+				if err := r.ensureMetricsIntegration(ci, internalSvc, deploymentRef); err != nil {
+					return fmt.Errorf("failed to integrate metrics with openshift-monitoring: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: fmt.Sprintf("rsyslog configmap for ingresscontroller %s", ci.Name),
+			run: func() error {
+				_, _, err := r.ensureRsyslogConfigMap(ci, deploymentRef, ingressConfig)
+				return err
+			},
+		},
+		{
+			name: fmt.Sprintf("pod disruption budget for ingresscontroller %s", ci.Name),
+			run: func() error {
+				_, _, err := r.ensureRouterPodDisruptionBudget(ci, deploymentRef)
+				return err
+			},
+		},
+	}); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -702,11 +822,37 @@ func (r *reconciler) ensureIngressController(ci *operatorv1.IngressController, d
 		errs = append(errs, fmt.Errorf("failed to list pods in namespace %q: %v", operatorcontroller.DefaultOperatorNamespace, err))
 	}
 
+	if err := r.updateRouteInsecurePolicyMetrics(ci); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update route insecure-policy metrics for %s: %v", ci.Name, err))
+	}
+
 	errs = append(errs, r.syncIngressControllerStatus(ci, deployment, pods.Items, lbService, operandEvents.Items, wildcardRecord, dnsConfig))
 
 	return retryable.NewMaybeRetryableAggregate(errs)
 }
 
+// UnmanagedDefaultCertificateAnnotation can be set to "true" on an
+// ingresscontroller to disable operator management of the default
+// certificate entirely.  The certificate controller then neither generates,
+// rotates, nor publishes a default certificate for the ingresscontroller;
+// the serving certificate is expected to be provisioned out-of-band.
+const UnmanagedDefaultCertificateAnnotation = "ingress.operator.openshift.io/unmanaged-default-certificate"
+
+// DefaultCertificateIsUnmanaged returns true if operator management of the
+// ingresscontroller's default certificate is disabled by annotation.
+func DefaultCertificateIsUnmanaged(ic *operatorv1.IngressController) bool {
+	val, ok := ic.Annotations[UnmanagedDefaultCertificateAnnotation]
+	if !ok {
+		return false
+	}
+	enabled, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Info("ignoring invalid annotation value", "annotation", UnmanagedDefaultCertificateAnnotation, "value", val)
+		return false
+	}
+	return enabled
+}
+
 // IsStatusDomainSet checks whether status.domain of ingress is set.
 func IsStatusDomainSet(ingress *operatorv1.IngressController) bool {
 	if len(ingress.Status.Domain) == 0 {
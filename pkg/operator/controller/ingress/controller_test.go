@@ -6,6 +6,10 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 func TestTLSProfileSpecForSecurityProfile(t *testing.T) {
@@ -317,3 +321,63 @@ func TestTLSProfileSpecForIngressController(t *testing.T) {
 		}
 	}
 }
+
+func TestDesiredStateChangedPredicate(t *testing.T) {
+	base := func() *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "default",
+				Generation: 1,
+			},
+		}
+	}
+	testCases := []struct {
+		description   string
+		mutate        func(*operatorv1.IngressController)
+		expectTrigger bool
+	}{
+		{
+			description: "status-only update",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Status.AvailableReplicas = 2
+			},
+			expectTrigger: false,
+		},
+		{
+			description: "generation change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Generation = 2
+			},
+			expectTrigger: true,
+		},
+		{
+			description: "annotation change",
+			mutate: func(ic *operatorv1.IngressController) {
+				ic.Annotations = map[string]string{"foo": "bar"}
+			},
+			expectTrigger: true,
+		},
+		{
+			description: "deletion timestamp set",
+			mutate: func(ic *operatorv1.IngressController) {
+				now := metav1.Now()
+				ic.DeletionTimestamp = &now
+			},
+			expectTrigger: true,
+		},
+	}
+	for _, tc := range testCases {
+		old := base()
+		new := base()
+		tc.mutate(new)
+		e := event.UpdateEvent{
+			MetaOld:   old,
+			ObjectOld: old,
+			MetaNew:   new,
+			ObjectNew: new,
+		}
+		if actual := desiredStateChangedPredicate.Update(e); actual != tc.expectTrigger {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expectTrigger, actual)
+		}
+	}
+}
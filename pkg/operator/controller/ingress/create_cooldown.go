@@ -0,0 +1,42 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	retryable "github.com/openshift/cluster-ingress-operator/pkg/util/retryableerror"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// createCooldown is the base interval that the reconciler waits after a
+// failed create of a managed object before trying to create it again.  The
+// actual wait is jittered up to 50% beyond the base so that repeated failures
+// do not synchronize.
+const createCooldown = 10 * time.Second
+
+// createManagedObject wraps client.Create with a short, jittered cooldown
+// after a failed create, so that a create that an admission webhook or quota
+// transiently rejects is retried at a measured pace instead of hot-looping.
+// The cooldown resets as soon as a create succeeds.
+func (r *reconciler) createManagedObject(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%T/%s/%s", obj, accessor.GetNamespace(), accessor.GetName())
+	if until, ok := r.createCooldowns[key]; ok {
+		if remaining := until.Sub(clock.Now()); remaining > 0 {
+			return retryable.New(fmt.Errorf("creation of %s is in cooldown after a failed create", key), remaining)
+		}
+	}
+	if err := r.client.Create(context.TODO(), obj); err != nil {
+		r.createCooldowns[key] = clock.Now().Add(wait.Jitter(createCooldown, 0.5))
+		return err
+	}
+	delete(r.createCooldowns, key)
+	return nil
+}
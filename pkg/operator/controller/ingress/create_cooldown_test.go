@@ -0,0 +1,96 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	retryable "github.com/openshift/cluster-ingress-operator/pkg/util/retryableerror"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// createFailingClient counts create attempts and fails them on demand.
+type createFailingClient struct {
+	crclient.Client
+	attempts int
+	fail     bool
+}
+
+func (c *createFailingClient) Create(ctx context.Context, obj runtime.Object, opts ...crclient.CreateOption) error {
+	c.attempts++
+	if c.fail {
+		return fmt.Errorf("admission webhook denied the request")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// TestCreateManagedObjectCooldown verifies that repeated create failures are
+// spaced out by a cooldown rather than retried in a tight loop, and that a
+// successful create resets the cooldown.
+func TestCreateManagedObjectCooldown(t *testing.T) {
+	fakeClock := utilclock.NewFakeClock(time.Now())
+	clock = fakeClock
+	defer func() { clock = utilclock.RealClock{} }()
+
+	client := &createFailingClient{
+		Client: fake.NewFakeClientWithScheme(operatorclient.GetScheme()),
+		fail:   true,
+	}
+	r := &reconciler{
+		client:          client,
+		createCooldowns: map[string]time.Time{},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "test"},
+	}
+
+	// The first attempt reaches the API and fails.
+	if err := r.createManagedObject(cm.DeepCopy()); err == nil {
+		t.Fatal("expected the first create to fail")
+	}
+	if client.attempts != 1 {
+		t.Fatalf("expected 1 create attempt, got %d", client.attempts)
+	}
+
+	// An immediate retry is held back by the cooldown and does not reach
+	// the API.
+	err := r.createManagedObject(cm.DeepCopy())
+	if err == nil {
+		t.Fatal("expected an error while the cooldown is in effect")
+	}
+	if _, ok := err.(retryable.Error); !ok {
+		t.Fatalf("expected a retryable error while the cooldown is in effect, got %v", err)
+	}
+	if client.attempts != 1 {
+		t.Fatalf("expected no create attempt during the cooldown, got %d", client.attempts)
+	}
+
+	// After the cooldown elapses, the create is attempted again.
+	fakeClock.Step(2 * createCooldown)
+	if err := r.createManagedObject(cm.DeepCopy()); err == nil {
+		t.Fatal("expected the create to fail again after the cooldown")
+	}
+	if client.attempts != 2 {
+		t.Fatalf("expected 2 create attempts, got %d", client.attempts)
+	}
+
+	// A successful create resets the cooldown.
+	fakeClock.Step(2 * createCooldown)
+	client.fail = false
+	if err := r.createManagedObject(cm.DeepCopy()); err != nil {
+		t.Fatalf("expected the create to succeed: %v", err)
+	}
+	if len(r.createCooldowns) != 0 {
+		t.Fatalf("expected the cooldown to be reset after a successful create, got %v", r.createCooldowns)
+	}
+}
@@ -0,0 +1,72 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RouterDefaultBackendServiceAnnotation can be set on an
+	// ingresscontroller to the "namespace/name" of a service to which the
+	// router sends requests for hosts that no route matches, instead of
+	// serving the built-in default backend response.  Removing the
+	// annotation restores the built-in default backend.
+	RouterDefaultBackendServiceAnnotation = "ingress.operator.openshift.io/default-backend-service"
+	RouterDefaultBackendServiceEnvName    = "ROUTER_DEFAULT_BACKEND_SERVICE"
+)
+
+// defaultBackendNamespaceAllowed returns true if a service in the given
+// namespace may be used as the router's default backend.  System namespaces
+// other than the operand namespace are disallowed so that the router cannot
+// be pointed at platform services.
+func defaultBackendNamespaceAllowed(namespace string) bool {
+	if namespace == controller.DefaultOperandNamespace {
+		return true
+	}
+	return !strings.HasPrefix(namespace, "openshift-") && !strings.HasPrefix(namespace, "kube-")
+}
+
+// defaultBackendService returns the "namespace/name" of the custom default
+// backend service configured on the ingresscontroller, after verifying that
+// the annotation is well formed, the namespace is allowed, and the service
+// exists.  An empty string is returned when no service is configured.
+func (r *reconciler) defaultBackendService(ci *operatorv1.IngressController) (string, error) {
+	val, ok := ci.Annotations[RouterDefaultBackendServiceAnnotation]
+	if !ok || len(val) == 0 {
+		return "", nil
+	}
+	parts := strings.SplitN(val, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", fmt.Errorf("the %s annotation must name a service as \"namespace/name\": %q", RouterDefaultBackendServiceAnnotation, val)
+	}
+	namespace, name := parts[0], parts[1]
+	if !defaultBackendNamespaceAllowed(namespace) {
+		return "", fmt.Errorf("namespace %q is not allowed for the default backend service", namespace)
+	}
+	service := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, service); err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("the default backend service %s/%s does not exist", namespace, name)
+		}
+		return "", fmt.Errorf("failed to get the default backend service %s/%s: %v", namespace, name, err)
+	}
+	return val, nil
+}
+
+// applyDefaultBackendService points the router deployment's default backend
+// at the given service.
+func applyDefaultBackendService(deployment *appsv1.Deployment, service string) {
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{Name: RouterDefaultBackendServiceEnvName, Value: service})
+}
@@ -0,0 +1,118 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultBackendService(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  string
+		service     *corev1.Service
+		expected    string
+		expectError bool
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "existing service in an allowed namespace",
+			annotation:  "landing-page/backend",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "landing-page",
+					Name:      "backend",
+				},
+			},
+			expected: "landing-page/backend",
+		},
+		{
+			description: "missing service",
+			annotation:  "landing-page/backend",
+			expectError: true,
+		},
+		{
+			description: "system namespace is disallowed",
+			annotation:  "openshift-monitoring/prometheus-k8s",
+			expectError: true,
+		},
+		{
+			description: "malformed annotation",
+			annotation:  "backend",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{RouterDefaultBackendServiceAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.service != nil {
+			objs = append(objs, tc.service)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		service, err := r.defaultBackendService(ic)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if service != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.description, tc.expected, service)
+		}
+	}
+}
+
+func TestApplyDefaultBackendService(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+	if err != nil {
+		t.Fatalf("failed to build router deployment: %v", err)
+	}
+	if env := findEnv(deployment, RouterDefaultBackendServiceEnvName); env != "" {
+		t.Fatalf("expected no %s env var by default, got %q", RouterDefaultBackendServiceEnvName, env)
+	}
+	applyDefaultBackendService(deployment, "landing-page/backend")
+	if env := findEnv(deployment, RouterDefaultBackendServiceEnvName); env != "landing-page/backend" {
+		t.Errorf("expected %s=%q, got %q", RouterDefaultBackendServiceEnvName, "landing-page/backend", env)
+	}
+}
+
+func findEnv(deployment *appsv1.Deployment, name string) string {
+	for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+		if envVar.Name == name {
+			return envVar.Value
+		}
+	}
+	return ""
+}
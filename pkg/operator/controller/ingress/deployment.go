@@ -2,11 +2,13 @@ package ingress
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"hash"
 	"hash/fnv"
 	"net"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -30,6 +32,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 
 	configv1 "github.com/openshift/api/config/v1"
 )
@@ -59,7 +62,57 @@ const (
 	RouterHardStopAfterEnvName    = "ROUTER_HARD_STOP_AFTER"
 	RouterHardStopAfterAnnotation = "ingress.operator.openshift.io/hard-stop-after"
 
+	// RouterBackendCheckIntervalAnnotation can be set on an
+	// ingresscontroller to a duration value to configure how often the
+	// router health-checks route backends.  A route can override the
+	// interval for its own backends with the
+	// "router.openshift.io/haproxy.health.check.interval" route
+	// annotation, which the router gives precedence.
+	RouterBackendCheckIntervalAnnotation = "ingress.operator.openshift.io/backend-check-interval"
+	RouterBackendCheckIntervalEnvName    = "ROUTER_BACKEND_CHECK_INTERVAL"
+
+	// RouterBackendCheckRiseAnnotation and
+	// RouterBackendCheckFallAnnotation can be set on an ingresscontroller
+	// to configure how many consecutive successful or failed health checks
+	// it takes for the router to mark a backend endpoint up or down,
+	// respectively.
+	RouterBackendCheckRiseAnnotation = "ingress.operator.openshift.io/backend-check-rise"
+	RouterBackendCheckRiseEnvName    = "ROUTER_BACKEND_CHECK_RISE"
+	RouterBackendCheckFallAnnotation = "ingress.operator.openshift.io/backend-check-fall"
+	RouterBackendCheckFallEnvName    = "ROUTER_BACKEND_CHECK_FALL"
+
+	// RouterMaxConnectionsAnnotation can be set on an ingresscontroller to
+	// configure the maximum number of simultaneous connections (HAProxy
+	// maxconn) that the router allows.  The router raises its
+	// file-descriptor limit to accommodate the configured value, so
+	// raising it also raises the effective nofile ulimit of the HAProxy
+	// process.  When the annotation is not set, the router's built-in
+	// default is used.
+	RouterMaxConnectionsAnnotation = "ingress.operator.openshift.io/max-connections"
+	RouterMaxConnectionsEnvName    = "ROUTER_MAX_CONNECTIONS"
+
 	LivenessGracePeriodSecondsAnnotation = "unsupported.do-not-use.openshift.io/override-liveness-grace-period-seconds"
+
+	// UnsupportedConfigOverridesAnnotation can be set on an
+	// ingresscontroller to a raw JSON strategic merge patch that the
+	// operator applies to the router deployment it generates.  Using this
+	// annotation is unsupported; it exists as a field-level escape hatch
+	// for configuration the operator does not expose.
+	UnsupportedConfigOverridesAnnotation = "unsupported.do-not-use.openshift.io/override-deployment"
+
+	// RouterReadinessGateAnnotation can be set to "true" on an
+	// ingresscontroller to add a readiness gate to the router pods that is
+	// tied to the router's own health rather than the basic readiness
+	// probe.  The pod-health controller maintains the gate's condition, so
+	// a pod whose router is unhealthy (for example, because a config
+	// reload failed) is removed from service endpoints until it recovers.
+	// Note that while the gate's condition is False, the pod also does not
+	// count as available for rolling updates.
+	RouterReadinessGateAnnotation = "ingress.operator.openshift.io/enable-router-readiness-gate"
+
+	// RouterHealthyConditionType is the pod condition type of the
+	// readiness gate that RouterReadinessGateAnnotation enables.
+	RouterHealthyConditionType corev1.PodConditionType = "ingress.operator.openshift.io/router-healthy"
 )
 
 // ensureRouterDeployment ensures the router deployment exists for a given
@@ -77,10 +130,44 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to determine if proxy protocol is needed for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
 	}
-	desired, err := desiredRouterDeployment(ci, r.config.IngressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded)
+	if certs, err := sniCertificates(ci, controller.RouterEffectiveDefaultCertificateSecretName(ci, controller.DefaultOperandNamespace).Name); err != nil {
+		return haveDepl, current, fmt.Errorf("invalid SNI certificates for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	} else if err := r.validateSNICertificates(ci, certs); err != nil {
+		return haveDepl, current, fmt.Errorf("invalid SNI certificates for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	}
+	desired, err := desiredRouterDeployment(ci, r.config.IngressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded, r.config.MinimumTLSVersion)
 	if err != nil {
 		return haveDepl, current, fmt.Errorf("failed to build router deployment: %v", err)
 	}
+	desired, err = applyUnsupportedConfigOverrides(ci, desired)
+	if err != nil {
+		return haveDepl, current, fmt.Errorf("failed to apply unsupported config overrides for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	}
+	if secretName, ok := tlsSessionTicketKeysSecret(ci); ok {
+		if err := r.validateTLSSessionTicketKeysSecret(secretName); err != nil {
+			// Keep the invalid key material out of the router; the
+			// "TLSSessionTicketKeysValid" status condition reports
+			// the problem.
+			log.Error(err, "not configuring TLS session ticket keys for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name)
+			removeTLSSessionTicketKeyConfig(desired)
+		}
+	}
+	r.applyPriorityClass(ci, desired)
+	r.applyConfigTemplate(ci, desired)
+	r.applyBackendsDownErrorPage(ci, desired)
+	if err := r.applyTrustedCABundleHash(desired); err != nil {
+		return haveDepl, current, fmt.Errorf("failed to apply trusted CA bundle hash for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	}
+	if ref, err := r.additionalOwnerReference(); err != nil {
+		return haveDepl, current, fmt.Errorf("invalid additional owner reference for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	} else if ref != nil {
+		setAdditionalOwnerReference(&desired.ObjectMeta, ref)
+	}
+	if service, err := r.defaultBackendService(ci); err != nil {
+		return haveDepl, current, fmt.Errorf("invalid default backend service for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
+	} else if len(service) != 0 {
+		applyDefaultBackendService(desired, service)
+	}
 	switch {
 	case !haveDepl:
 		if err := r.createRouterDeployment(desired); err != nil {
@@ -88,7 +175,7 @@ func (r *reconciler) ensureRouterDeployment(ci *operatorv1.IngressController, in
 		}
 		return r.currentRouterDeployment(ci)
 	case haveDepl:
-		if updated, err := r.updateRouterDeployment(current, desired); err != nil {
+		if updated, err := r.updateRouterDeployment(ci, current, desired); err != nil {
 			return true, current, err
 		} else if updated {
 			return r.currentRouterDeployment(ci)
@@ -115,6 +202,32 @@ func (r *reconciler) ensureRouterDeleted(ci *operatorv1.IngressController) error
 	return nil
 }
 
+// applyUnsupportedConfigOverrides applies the strategic merge patch from the
+// ingresscontroller's unsupported config overrides annotation, if present, to
+// the given deployment.  The merge result is validated by round-tripping it
+// through the deployment type so that a bad override produces an error
+// instead of a broken deployment.
+func applyUnsupportedConfigOverrides(ci *operatorv1.IngressController, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	overrides, ok := ci.Annotations[UnsupportedConfigOverridesAnnotation]
+	if !ok || len(overrides) == 0 {
+		return deployment, nil
+	}
+	log.Info("applying unsupported config overrides to router deployment; this is not a supported configuration", "ingresscontroller", ci.Name, "overrides", overrides)
+	original, err := json.Marshal(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal deployment: %v", err)
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, []byte(overrides), appsv1.Deployment{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply overrides %q: %v", overrides, err)
+	}
+	updated := &appsv1.Deployment{}
+	if err := json.Unmarshal(patched, updated); err != nil {
+		return nil, fmt.Errorf("overrides %q produced an invalid deployment: %v", overrides, err)
+	}
+	return updated, nil
+}
+
 // HTTP2IsEnabledByAnnotation returns true if the map m has the key
 // RouterDisableHTTP2Annotation present and true|false depending on
 // the annotation's value that is parsed by strconv.ParseBool.
@@ -169,8 +282,70 @@ func HardStopAfterIsEnabled(ic *operatorv1.IngressController, ingressConfig *con
 	return HardStopAfterIsEnabledByAnnotation(ingressConfig.Annotations)
 }
 
+// backendCheckIntervalFromAnnotation returns the backend health-check
+// interval configured on the ingresscontroller, and a boolean indicating
+// whether a valid interval is configured.  The interval must be between 100
+// milliseconds and 5 minutes; values outside those bounds, and values that do
+// not parse as durations, are logged and ignored.
+func backendCheckIntervalFromAnnotation(ic *operatorv1.IngressController) (string, bool) {
+	const (
+		minInterval = 100 * time.Millisecond
+		maxInterval = 5 * time.Minute
+	)
+	val, ok := ic.Annotations[RouterBackendCheckIntervalAnnotation]
+	if !ok || len(val) == 0 {
+		return "", false
+	}
+	interval, err := time.ParseDuration(val)
+	if err != nil || interval < minInterval || interval > maxInterval {
+		log.Info("ignoring invalid annotation value", "annotation", RouterBackendCheckIntervalAnnotation, "value", val)
+		return "", false
+	}
+	return val, true
+}
+
+// backendCheckThresholdFromAnnotation returns the backend health-check
+// threshold configured by the given annotation on the ingresscontroller, and
+// a boolean indicating whether a valid threshold is configured.  The
+// threshold must be between 1 and 10; other values are logged and ignored.
+func backendCheckThresholdFromAnnotation(ic *operatorv1.IngressController, annotation string) (int, bool) {
+	const maxThreshold = 10
+	val, ok := ic.Annotations[annotation]
+	if !ok {
+		return 0, false
+	}
+	threshold, err := strconv.Atoi(val)
+	if err != nil || threshold < 1 || threshold > maxThreshold {
+		log.Info("ignoring invalid annotation value", "annotation", annotation, "value", val)
+		return 0, false
+	}
+	return threshold, true
+}
+
+// maxConnectionsFromAnnotation returns the maximum connection count
+// configured on the ingresscontroller, and a boolean indicating whether a
+// valid count is configured.  The count must be between 2000 and 2000000 so
+// that the router's derived file-descriptor limit stays within the limits the
+// kernel allows the router to request; other values are logged and ignored.
+func maxConnectionsFromAnnotation(ic *operatorv1.IngressController) (int, bool) {
+	const (
+		minMaxConnections = 2000
+		maxMaxConnections = 2000000
+	)
+	val, ok := ic.Annotations[RouterMaxConnectionsAnnotation]
+	if !ok {
+		return 0, false
+	}
+	maxConnections, err := strconv.Atoi(val)
+	if err != nil || maxConnections < minMaxConnections || maxConnections > maxMaxConnections {
+		log.Info("ignoring invalid annotation value", "annotation", RouterMaxConnectionsAnnotation, "value", val)
+		return 0, false
+	}
+	return maxConnections, true
+}
+
 // desiredRouterDeployment returns the desired router deployment.
-func desiredRouterDeployment(ci *operatorv1.IngressController, ingressControllerImage string, ingressConfig *configv1.Ingress, apiConfig *configv1.APIServer, networkConfig *configv1.Network, proxyNeeded bool) (*appsv1.Deployment, error) {
+func desiredRouterDeployment(ci *operatorv1.IngressController, ingressControllerImage string, ingressConfig *configv1.Ingress, apiConfig *configv1.APIServer, networkConfig *configv1.Network, proxyNeeded bool, minimumTLSVersion configv1.TLSProtocolVersion) (*appsv1.Deployment, error) {
 	deployment := manifests.RouterDeployment()
 	name := controller.RouterDeploymentName(ci)
 	deployment.Name = name.Name
@@ -189,6 +364,23 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	gracePeriod := int64(60 * 60)
 	deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &gracePeriod
 
+	// Optionally add the router-health readiness gate.
+	if val, ok := ci.Annotations[RouterReadinessGateAnnotation]; ok {
+		if enabled, _ := strconv.ParseBool(val); enabled {
+			deployment.Spec.Template.Spec.ReadinessGates = append(deployment.Spec.Template.Spec.ReadinessGates, corev1.PodReadinessGate{
+				ConditionType: RouterHealthyConditionType,
+			})
+		}
+	}
+
+	// Optionally add configured pod template annotations for external
+	// tooling such as sidecar injectors.
+	if annotations, err := podTemplateAnnotations(ci); err != nil {
+		log.Info("ignoring invalid annotation value", "annotation", PodTemplateAnnotationsAnnotation, "error", err.Error())
+	} else {
+		applyPodTemplateAnnotations(deployment, annotations)
+	}
+
 	volumes := deployment.Spec.Template.Spec.Volumes
 	routerVolumeMounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
 
@@ -354,6 +546,61 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	volumes = append(volumes, certsVolume)
 	routerVolumeMounts = append(routerVolumeMounts, certsVolumeMount)
 
+	// Mount any SNI certificates referenced by the ingresscontroller and
+	// point the router at the mounted directories.
+	defaultCertificateSecretName := controller.RouterEffectiveDefaultCertificateSecretName(ci, deployment.Namespace).Name
+	sniCerts, err := sniCertificates(ci, defaultCertificateSecretName)
+	if err != nil {
+		return nil, err
+	}
+	if len(sniCerts) != 0 {
+		dirs := make([]string, 0, len(sniCerts))
+		for i, cert := range sniCerts {
+			volume := corev1.Volume{
+				Name: sniCertificateVolumeName(i),
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: cert.SecretName,
+					},
+				},
+			}
+			mount := corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: sniCertificateMountPath(cert.Host),
+				ReadOnly:  true,
+			}
+			volumes = append(volumes, volume)
+			routerVolumeMounts = append(routerVolumeMounts, mount)
+			dirs = append(dirs, sniCertificateMountPath(cert.Host))
+		}
+		env = append(env, corev1.EnvVar{Name: RouterSNICertificateDirsEnvName, Value: strings.Join(dirs, ",")})
+	}
+
+	// Wire TLS session resumption settings.  Ticket keys are mounted from a
+	// secret so that rotating the secret rotates the keys without restarting
+	// the router.
+	if secretName, ok := tlsSessionTicketKeysSecret(ci); ok {
+		volume := corev1.Volume{
+			Name: tlsSessionTicketKeysVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+				},
+			},
+		}
+		mount := corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: tlsSessionTicketKeysVolumeMountPath,
+			ReadOnly:  true,
+		}
+		volumes = append(volumes, volume)
+		routerVolumeMounts = append(routerVolumeMounts, mount)
+		env = append(env, corev1.EnvVar{Name: RouterTLSSessionTicketKeyFileEnvName, Value: filepath.Join(tlsSessionTicketKeysVolumeMountPath, tlsSessionTicketKeysSecretKey)})
+	}
+	if size, ok := tlsSessionCacheSize(ci); ok {
+		env = append(env, corev1.EnvVar{Name: RouterTLSSessionCacheSizeEnvName, Value: strconv.Itoa(size)})
+	}
+
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TYPE", Value: "haproxy"})
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_CERT_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.crt")})
 	env = append(env, corev1.EnvVar{Name: "ROUTER_METRICS_TLS_KEY_FILE", Value: filepath.Join(certsVolumeMountPath, "tls.key")})
@@ -546,6 +793,7 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 	}
 
 	tlsProfileSpec := tlsProfileSpecForIngressController(ci, apiConfig)
+	tlsProfileSpec, _ = clampTLSProfileSpec(tlsProfileSpec, minimumTLSVersion)
 
 	ciphers := strings.Join(tlsProfileSpec.Ciphers, ":")
 	env = append(env, corev1.EnvVar{Name: "ROUTER_CIPHERS", Value: ciphers})
@@ -660,6 +908,32 @@ func desiredRouterDeployment(ci *operatorv1.IngressController, ingressController
 		env = append(env, corev1.EnvVar{Name: RouterHardStopAfterEnvName, Value: value})
 	}
 
+	if interval, ok := backendCheckIntervalFromAnnotation(ci); ok {
+		env = append(env, corev1.EnvVar{Name: RouterBackendCheckIntervalEnvName, Value: interval})
+	}
+	if rise, ok := backendCheckThresholdFromAnnotation(ci, RouterBackendCheckRiseAnnotation); ok {
+		env = append(env, corev1.EnvVar{Name: RouterBackendCheckRiseEnvName, Value: strconv.Itoa(rise)})
+	}
+	if fall, ok := backendCheckThresholdFromAnnotation(ci, RouterBackendCheckFallAnnotation); ok {
+		env = append(env, corev1.EnvVar{Name: RouterBackendCheckFallEnvName, Value: strconv.Itoa(fall)})
+	}
+
+	if maxConnections, ok := maxConnectionsFromAnnotation(ci); ok {
+		env = append(env, corev1.EnvVar{Name: RouterMaxConnectionsEnvName, Value: strconv.Itoa(maxConnections)})
+	}
+
+	if status, redirect, ok := backendsDownPolicyFromAnnotation(ci); ok {
+		if len(redirect) != 0 {
+			env = append(env, corev1.EnvVar{Name: RouterBackendsDownRedirectEnvName, Value: redirect})
+		} else {
+			env = append(env, corev1.EnvVar{Name: RouterBackendsDownStatusEnvName, Value: strconv.Itoa(status)})
+		}
+	}
+
+	if policy, ok := defaultInsecurePolicy(ci); ok {
+		env = append(env, corev1.EnvVar{Name: RouterDefaultInsecurePolicyEnvName, Value: string(policy)})
+	}
+
 	deployment.Spec.Template.Spec.Volumes = volumes
 	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = routerVolumeMounts
 	deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, env...)
@@ -913,6 +1187,7 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 	hashableDeployment.Spec.Template.Spec.Containers = containers
 	hashableDeployment.Spec.Template.Spec.DNSPolicy = deployment.Spec.Template.Spec.DNSPolicy
 	hashableDeployment.Spec.Template.Spec.HostNetwork = deployment.Spec.Template.Spec.HostNetwork
+	hashableDeployment.Spec.Template.Spec.PriorityClassName = deployment.Spec.Template.Spec.PriorityClassName
 	volumes := make([]corev1.Volume, len(deployment.Spec.Template.Spec.Volumes))
 	for i, vol := range deployment.Spec.Template.Spec.Volumes {
 		volumes[i] = *vol.DeepCopy()
@@ -933,6 +1208,16 @@ func hashableDeployment(deployment *appsv1.Deployment, onlyTemplate bool) *appsv
 	if val, ok := deployment.Spec.Template.Annotations[LivenessGracePeriodSecondsAnnotation]; ok && len(val) > 0 {
 		hashableDeployment.Spec.Template.Annotations[LivenessGracePeriodSecondsAnnotation] = val
 	}
+	// Include the configured pod template annotations so that changing or
+	// removing them rolls the deployment, but an unchanged set does not.
+	if val, ok := deployment.Spec.Template.Annotations[managedPodTemplateAnnotationsAnnotation]; ok && len(val) > 0 {
+		hashableDeployment.Spec.Template.Annotations[managedPodTemplateAnnotationsAnnotation] = val
+		for _, key := range managedPodTemplateAnnotationKeys(deployment.Spec.Template.Annotations) {
+			if val, ok := deployment.Spec.Template.Annotations[key]; ok {
+				hashableDeployment.Spec.Template.Annotations[key] = val
+			}
+		}
+	}
 
 	if onlyTemplate {
 		return &hashableDeployment
@@ -1005,29 +1290,89 @@ func (r *reconciler) currentRouterDeployment(ci *operatorv1.IngressController) (
 
 // createRouterDeployment creates a router deployment.
 func (r *reconciler) createRouterDeployment(deployment *appsv1.Deployment) error {
-	if err := r.client.Create(context.TODO(), deployment); err != nil {
+	if err := r.createManagedObject(deployment); err != nil {
 		return fmt.Errorf("failed to create router deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
 	}
 	log.Info("created router deployment", "namespace", deployment.Namespace, "name", deployment.Name)
 	return nil
 }
 
-// updateRouterDeployment updates a router deployment.
-func (r *reconciler) updateRouterDeployment(current, desired *appsv1.Deployment) (bool, error) {
+// updateRouterDeployment updates a router deployment.  If the
+// ingresscontroller has a maintenance window configured, updates that are
+// not urgent are deferred until the window opens.
+func (r *reconciler) updateRouterDeployment(ci *operatorv1.IngressController, current, desired *appsv1.Deployment) (bool, error) {
 	changed, updated := deploymentConfigChanged(current, desired)
 	if !changed {
+		delete(r.deferredRollouts, deferredRolloutKey(ci))
+		return false, nil
+	}
+
+	if window, ok := maintenanceWindowFromAnnotation(ci); ok && !window.contains(clock.Now()) && !deploymentChangeIsUrgent(current, updated) {
+		next := window.nextStart(clock.Now())
+		r.deferredRollouts[deferredRolloutKey(ci)] = next
+		log.Info("deferring router deployment update until the maintenance window", "namespace", updated.Namespace, "name", updated.Name, "until", next.Format(time.RFC3339))
 		return false, nil
 	}
+	delete(r.deferredRollouts, deferredRolloutKey(ci))
 
 	// Diff before updating because the client may mutate the object.
 	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	summary := deploymentChangeDescription(current, updated)
 	if err := r.client.Update(context.TODO(), updated); err != nil {
 		return false, fmt.Errorf("failed to update router deployment %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	log.Info("updated router deployment", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
+	r.recorder.Eventf(ci, "Normal", "UpdatedDeployment", "Updated deployment %s/%s: %s", updated.Namespace, updated.Name, summary)
 	return true, nil
 }
 
+// maxDeploymentChangeDescriptionLength bounds the size of the change
+// description included in the event emitted when the router deployment is
+// updated.
+const maxDeploymentChangeDescriptionLength = 500
+
+// deploymentChangeDescription returns a short, bounded description of the
+// material differences between the current and updated router deployments,
+// suitable for use in an event message.
+func deploymentChangeDescription(current, updated *appsv1.Deployment) string {
+	var changes []string
+	currentReplicas, updatedReplicas := int32(1), int32(1)
+	if current.Spec.Replicas != nil {
+		currentReplicas = *current.Spec.Replicas
+	}
+	if updated.Spec.Replicas != nil {
+		updatedReplicas = *updated.Spec.Replicas
+	}
+	if currentReplicas != updatedReplicas {
+		changes = append(changes, fmt.Sprintf("replicas changed from %d to %d", currentReplicas, updatedReplicas))
+	}
+	currentContainer := current.Spec.Template.Spec.Containers[0]
+	updatedContainer := updated.Spec.Template.Spec.Containers[0]
+	if currentContainer.Image != updatedContainer.Image {
+		changes = append(changes, fmt.Sprintf("image changed from %q to %q", currentContainer.Image, updatedContainer.Image))
+	}
+	if !reflect.DeepEqual(currentContainer.Env, updatedContainer.Env) {
+		changes = append(changes, "environment changed")
+	}
+	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, updated.Spec.Template.Spec.Volumes) ||
+		!reflect.DeepEqual(currentContainer.VolumeMounts, updatedContainer.VolumeMounts) {
+		changes = append(changes, "volumes changed")
+	}
+	if !reflect.DeepEqual(current.Spec.Template.Spec.NodeSelector, updated.Spec.Template.Spec.NodeSelector) ||
+		!reflect.DeepEqual(current.Spec.Template.Spec.Tolerations, updated.Spec.Template.Spec.Tolerations) ||
+		!reflect.DeepEqual(current.Spec.Template.Spec.Affinity, updated.Spec.Template.Spec.Affinity) {
+		changes = append(changes, "node placement changed")
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "other fields changed")
+	}
+	description := strings.Join(changes, ", ")
+	if len(description) > maxDeploymentChangeDescriptionLength {
+		description = description[:maxDeploymentChangeDescriptionLength] + "..."
+	}
+	return description
+}
+
 // deepHashObject writes a specified object to a hash using the spew library
 // which follows pointers and prints actual values of the nested objects
 // ensuring the hash does not change when a pointer changes.
@@ -1070,6 +1415,8 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 		updated.Spec.Template.Annotations[LivenessGracePeriodSecondsAnnotation] = val
 	}
 
+	updated.Spec.Template.Annotations = syncManagedPodTemplateAnnotations(updated.Spec.Template.Annotations, expected.Spec.Template.Annotations)
+
 	updated.Spec.Strategy = expected.Spec.Strategy
 	volumes := make([]corev1.Volume, len(expected.Spec.Template.Spec.Volumes))
 	for i, vol := range expected.Spec.Template.Spec.Volumes {
@@ -1084,6 +1431,7 @@ func deploymentConfigChanged(current, expected *appsv1.Deployment) (bool, *appsv
 	updated.Spec.Template.Spec.Containers[0].VolumeMounts = expected.Spec.Template.Spec.Containers[0].VolumeMounts
 	updated.Spec.Template.Spec.Tolerations = expected.Spec.Template.Spec.Tolerations
 	updated.Spec.Template.Spec.Affinity = expected.Spec.Template.Spec.Affinity
+	updated.Spec.Template.Spec.PriorityClassName = expected.Spec.Template.Spec.PriorityClassName
 	replicas := int32(1)
 	if expected.Spec.Replicas != nil {
 		replicas = *expected.Spec.Replicas
@@ -3,11 +3,13 @@ package ingress
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -15,6 +17,10 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var toleration = corev1.Toleration{
@@ -175,7 +181,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to determine infrastructure platform status for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
 	}
-	deployment, err := desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded)
+	deployment, err := desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded, "")
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -315,7 +321,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to determine infrastructure platform status for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
 	}
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded)
+	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded, "")
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -378,7 +384,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to determine infrastructure platform status for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
 	}
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded)
+	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded, "")
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -462,7 +468,7 @@ func TestDesiredRouterDeployment(t *testing.T) {
 	if err != nil {
 		t.Errorf("failed to determine infrastructure platform status for ingresscontroller %s/%s: %v", ci.Namespace, ci.Name, err)
 	}
-	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded)
+	deployment, err = desiredRouterDeployment(ci, ingressControllerImage, ingressConfig, apiConfig, networkConfig, proxyNeeded, "")
 	if err != nil {
 		t.Errorf("invalid router Deployment: %v", err)
 	}
@@ -1078,3 +1084,434 @@ func TestDeploymentConfigChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateRouterDeploymentEventEmission(t *testing.T) {
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress",
+				Name:      "router-default",
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "router",
+							Image: "registry.example.com/router:v1",
+						}},
+					},
+				},
+			},
+		}
+	}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	testCases := []struct {
+		description string
+		mutate      func(*appsv1.Deployment)
+		expectEvent bool
+	}{
+		{
+			description: "no-op update emits no event",
+			mutate:      func(_ *appsv1.Deployment) {},
+			expectEvent: false,
+		},
+		{
+			description: "image change emits an event",
+			mutate: func(deployment *appsv1.Deployment) {
+				deployment.Spec.Template.Spec.Containers[0].Image = "registry.example.com/router:v2"
+			},
+			expectEvent: true,
+		},
+	}
+	for _, tc := range testCases {
+		current := newDeployment()
+		desired := newDeployment()
+		tc.mutate(desired)
+		client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), current)
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			client:   client,
+			recorder: recorder,
+		}
+		if _, err := r.updateRouterDeployment(ic, current, desired); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		select {
+		case event := <-recorder.Events:
+			if !tc.expectEvent {
+				t.Errorf("%q: unexpected event: %s", tc.description, event)
+			}
+		default:
+			if tc.expectEvent {
+				t.Errorf("%q: expected an event, got none", tc.description)
+			}
+		}
+	}
+}
+
+func TestDeploymentChangeDescription(t *testing.T) {
+	pointerTo := func(replicas int32) *int32 { return &replicas }
+	current := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointerTo(2),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "router",
+						Image: "registry.example.com/router:v1",
+					}},
+				},
+			},
+		},
+	}
+	updated := current.DeepCopy()
+	updated.Spec.Replicas = pointerTo(3)
+	updated.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "ROUTER_FOO", Value: "bar"}}
+	description := deploymentChangeDescription(current, updated)
+	for _, expected := range []string{"replicas changed from 2 to 3", "environment changed"} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("expected description to contain %q, got: %s", expected, description)
+		}
+	}
+	if len(description) > maxDeploymentChangeDescriptionLength+3 {
+		t.Errorf("description exceeds bound: %d characters", len(description))
+	}
+}
+
+func TestApplyUnsupportedConfigOverrides(t *testing.T) {
+	newIC := func(overrides string) *operatorv1.IngressController {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      "default",
+			},
+		}
+		if len(overrides) != 0 {
+			ic.Annotations = map[string]string{
+				UnsupportedConfigOverridesAnnotation: overrides,
+			}
+		}
+		return ic
+	}
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "router",
+						Image: "registry.example.com/router:v1",
+					}},
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		description string
+		overrides   string
+		expectError bool
+		check       func(*appsv1.Deployment) error
+	}{
+		{
+			description: "no overrides leaves the deployment unchanged",
+			overrides:   "",
+			check: func(d *appsv1.Deployment) error {
+				if !reflect.DeepEqual(d, deployment) {
+					return fmt.Errorf("deployment was mutated")
+				}
+				return nil
+			},
+		},
+		{
+			description: "valid override patches the pod spec",
+			overrides:   `{"spec":{"template":{"spec":{"terminationGracePeriodSeconds":600}}}}`,
+			check: func(d *appsv1.Deployment) error {
+				if d.Spec.Template.Spec.TerminationGracePeriodSeconds == nil || *d.Spec.Template.Spec.TerminationGracePeriodSeconds != 600 {
+					return fmt.Errorf("expected terminationGracePeriodSeconds 600, got %v", d.Spec.Template.Spec.TerminationGracePeriodSeconds)
+				}
+				if d.Spec.Template.Spec.Containers[0].Image != "registry.example.com/router:v1" {
+					return fmt.Errorf("image was unexpectedly changed")
+				}
+				return nil
+			},
+		},
+		{
+			description: "invalid JSON is rejected",
+			overrides:   `{"spec":`,
+			expectError: true,
+		},
+		{
+			description: "override with mistyped field is rejected",
+			overrides:   `{"spec":{"replicas":"three"}}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		result, err := applyUnsupportedConfigOverrides(newIC(tc.overrides), deployment.DeepCopy())
+		switch {
+		case err != nil && !tc.expectError:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case err == nil && tc.expectError:
+			t.Errorf("%q: expected error, got none", tc.description)
+		case err == nil && tc.check != nil:
+			if err := tc.check(result); err != nil {
+				t.Errorf("%q: %v", tc.description, err)
+			}
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentSNICertificates(t *testing.T) {
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				SNICertificatesAnnotation: `{"www.example.com":"example-cert","api.other.test":"other-cert"}`,
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	ingressConfig := &configv1.Ingress{}
+	apiConfig := &configv1.APIServer{}
+	networkConfig := &configv1.Network{}
+	deployment, err := desiredRouterDeployment(ci, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("failed to build router deployment: %v", err)
+	}
+
+	// Certificates are ordered by hostname, so "api.other.test" comes
+	// first.
+	expectedMounts := map[string]string{
+		"sni-certificate-0": "/etc/pki/tls/sni-certificates/api.other.test",
+		"sni-certificate-1": "/etc/pki/tls/sni-certificates/www.example.com",
+	}
+	expectedSecrets := map[string]string{
+		"sni-certificate-0": "other-cert",
+		"sni-certificate-1": "example-cert",
+	}
+	for name, secret := range expectedSecrets {
+		found := false
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.Name == name {
+				found = true
+				if volume.Secret == nil || volume.Secret.SecretName != secret {
+					t.Errorf("expected volume %q to reference secret %q, got %+v", name, secret, volume.VolumeSource)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected volume %q", name)
+		}
+	}
+	for name, path := range expectedMounts {
+		found := false
+		for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+			if mount.Name == name {
+				found = true
+				if mount.MountPath != path || !mount.ReadOnly {
+					t.Errorf("expected mount %q at %q read-only, got %+v", name, path, mount)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected volume mount %q", name)
+		}
+	}
+	checkDeploymentHasEnvVar(t, deployment, RouterSNICertificateDirsEnvName, true, "/etc/pki/tls/sni-certificates/api.other.test,/etc/pki/tls/sni-certificates/www.example.com")
+}
+
+func TestSNICertificatesValidation(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  string
+		expectError bool
+		expectCerts int
+	}{
+		{
+			description: "no annotation",
+			expectCerts: 0,
+		},
+		{
+			description: "two valid certificates",
+			annotation:  `{"a.example.com":"cert-a","b.example.com":"cert-b"}`,
+			expectCerts: 2,
+		},
+		{
+			description: "invalid JSON",
+			annotation:  `not-json`,
+			expectError: true,
+		},
+		{
+			description: "empty secret name",
+			annotation:  `{"a.example.com":""}`,
+			expectError: true,
+		},
+		{
+			description: "duplicates the default certificate",
+			annotation:  `{"a.example.com":"router-certs-default"}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if len(tc.annotation) != 0 {
+			ci.Annotations = map[string]string{SNICertificatesAnnotation: tc.annotation}
+		}
+		certs, err := sniCertificates(ci, "router-certs-default")
+		switch {
+		case err != nil && !tc.expectError:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case err == nil && tc.expectError:
+			t.Errorf("%q: expected error, got none", tc.description)
+		case err == nil && len(certs) != tc.expectCerts:
+			t.Errorf("%q: expected %d certificates, got %d", tc.description, tc.expectCerts, len(certs))
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentBackendCheck(t *testing.T) {
+	testCases := []struct {
+		description      string
+		annotations      map[string]string
+		expectedInterval string
+		expectedRise     string
+		expectedFall     string
+	}{
+		{
+			description: "no annotations",
+		},
+		{
+			description: "valid interval and thresholds",
+			annotations: map[string]string{
+				RouterBackendCheckIntervalAnnotation: "2s",
+				RouterBackendCheckRiseAnnotation:     "2",
+				RouterBackendCheckFallAnnotation:     "3",
+			},
+			expectedInterval: "2s",
+			expectedRise:     "2",
+			expectedFall:     "3",
+		},
+		{
+			description: "out-of-bounds values are ignored",
+			annotations: map[string]string{
+				RouterBackendCheckIntervalAnnotation: "10m",
+				RouterBackendCheckRiseAnnotation:     "0",
+				RouterBackendCheckFallAnnotation:     "11",
+			},
+		},
+		{
+			description: "unparseable values are ignored",
+			annotations: map[string]string{
+				RouterBackendCheckIntervalAnnotation: "often",
+				RouterBackendCheckRiseAnnotation:     "two",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: tc.annotations,
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		ingressConfig := &configv1.Ingress{}
+		apiConfig := &configv1.APIServer{}
+		networkConfig := &configv1.Network{}
+		deployment, err := desiredRouterDeployment(ci, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		checks := map[string]string{
+			RouterBackendCheckIntervalEnvName: tc.expectedInterval,
+			RouterBackendCheckRiseEnvName:     tc.expectedRise,
+			RouterBackendCheckFallEnvName:     tc.expectedFall,
+		}
+		for name, expected := range checks {
+			found := ""
+			for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+				if envVar.Name == name {
+					found = envVar.Value
+				}
+			}
+			if found != expected {
+				t.Errorf("%q: expected %s=%q, got %q", tc.description, name, expected, found)
+			}
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentMaxConnections(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotation  string
+		expected    string
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description: "valid count",
+			annotation:  "40000",
+			expected:    "40000",
+		},
+		{
+			description: "count below the minimum is ignored",
+			annotation:  "100",
+		},
+		{
+			description: "count above the maximum is ignored",
+			annotation:  "2000001",
+		},
+		{
+			description: "unparseable count is ignored",
+			annotation:  "lots",
+		},
+	}
+	for _, tc := range testCases {
+		ci := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ci.Annotations = map[string]string{RouterMaxConnectionsAnnotation: tc.annotation}
+		}
+		ingressConfig := &configv1.Ingress{}
+		apiConfig := &configv1.APIServer{}
+		networkConfig := &configv1.Network{}
+		deployment, err := desiredRouterDeployment(ci, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		found := ""
+		for _, envVar := range deployment.Spec.Template.Spec.Containers[0].Env {
+			if envVar.Name == RouterMaxConnectionsEnvName {
+				found = envVar.Value
+			}
+		}
+		if found != tc.expected {
+			t.Errorf("%q: expected %s=%q, got %q", tc.description, RouterMaxConnectionsEnvName, tc.expected, found)
+		}
+	}
+}
@@ -27,6 +27,14 @@ import (
 // [1] https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resource-record-sets-choosing-alias-non-alias.html
 const defaultRecordTTL int64 = 30
 
+// RecordTypeOverrideAnnotation is an annotation that can be set on an
+// ingresscontroller to override the record type of the wildcard DNS record
+// that the operator manages for the ingresscontroller.  The value must be a
+// record type that the platform's DNS provider supports ("A" or "CNAME");
+// other values are ignored.  For example, a CNAME override can be used to
+// point the wildcard domain at an external CDN hostname.
+const RecordTypeOverrideAnnotation = "ingress.operator.openshift.io/dns-record-type"
+
 // ensureWildcardDNSRecord will create DNS records for the given LB service.
 // If service is nil (haveLBS is false), nothing is done.
 func (r *reconciler) ensureWildcardDNSRecord(ic *operatorv1.IngressController, service *corev1.Service, haveLBS bool) (bool, *iov1.DNSRecord, error) {
@@ -48,6 +56,19 @@ func (r *reconciler) ensureWildcardDNSRecord(ic *operatorv1.IngressController, s
 		log.Info("created dnsrecord", "dnsrecord", desired)
 		return r.currentWildcardDNSRecord(ic)
 	case wantWC && haveWC:
+		if current.Spec.RecordType != desired.Spec.RecordType {
+			// Most DNS providers cannot change the type of an existing
+			// record in place, so replace the record by deleting the
+			// old-type record and creating the new one.
+			if err := r.client.Delete(context.TODO(), current); err != nil {
+				return true, current, fmt.Errorf("failed to delete dnsrecord %s/%s for record type change: %v", current.Namespace, current.Name, err)
+			}
+			if err := r.client.Create(context.TODO(), desired); err != nil {
+				return false, nil, fmt.Errorf("failed to create dnsrecord %s/%s for record type change: %v", desired.Namespace, desired.Name, err)
+			}
+			log.Info("replaced dnsrecord for record type change", "namespace", desired.Namespace, "name", desired.Name, "old record type", current.Spec.RecordType, "new record type", desired.Spec.RecordType)
+			return r.currentWildcardDNSRecord(ic)
+		}
 		if updated, err := r.updateDNSRecord(current, desired); err != nil {
 			return true, current, fmt.Errorf("failed to update dnsrecord %s/%s: %v", desired.Namespace, desired.Name, err)
 		} else if updated {
@@ -106,6 +127,15 @@ func desiredWildcardDNSRecord(ic *operatorv1.IngressController, service *corev1.
 		target = ingress.IP
 	}
 
+	if override, ok := ic.Annotations[RecordTypeOverrideAnnotation]; ok {
+		switch overrideType := iov1.DNSRecordType(override); overrideType {
+		case iov1.ARecordType, iov1.CNAMERecordType:
+			recordType = overrideType
+		default:
+			log.Info("ignoring unsupported DNS record type override", "ingresscontroller", ic.Name, "record type", override)
+		}
+	}
+
 	trueVar := true
 	return true, &iov1.DNSRecord{
 		ObjectMeta: metav1.ObjectMeta{
@@ -11,15 +11,20 @@ import (
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
 	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredWildcardDNSRecord(t *testing.T) {
 	tests := []struct {
 		description string
 		domain      string
+		annotations map[string]string
 		publish     operatorv1.EndpointPublishingStrategyType
 		ingresses   []corev1.LoadBalancerIngress
 		expect      *iov1.DNSRecordSpec
@@ -74,13 +79,48 @@ func TestDesiredWildcardDNSRecord(t *testing.T) {
 				RecordTTL:  defaultRecordTTL,
 			},
 		},
+		{
+			description: "record type override to CNAME",
+			publish:     operatorv1.LoadBalancerServiceStrategyType,
+			domain:      "apps.openshift.example.com",
+			annotations: map[string]string{
+				RecordTypeOverrideAnnotation: "CNAME",
+			},
+			ingresses: []corev1.LoadBalancerIngress{
+				{Hostname: "cdn.cloud.example.com"},
+			},
+			expect: &iov1.DNSRecordSpec{
+				DNSName:    "*.apps.openshift.example.com.",
+				RecordType: iov1.CNAMERecordType,
+				Targets:    []string{"cdn.cloud.example.com"},
+				RecordTTL:  defaultRecordTTL,
+			},
+		},
+		{
+			description: "unsupported record type override is ignored",
+			publish:     operatorv1.LoadBalancerServiceStrategyType,
+			domain:      "apps.openshift.example.com",
+			annotations: map[string]string{
+				RecordTypeOverrideAnnotation: "TXT",
+			},
+			ingresses: []corev1.LoadBalancerIngress{
+				{IP: "192.0.2.1"},
+			},
+			expect: &iov1.DNSRecordSpec{
+				DNSName:    "*.apps.openshift.example.com.",
+				RecordType: iov1.ARecordType,
+				Targets:    []string{"192.0.2.1"},
+				RecordTTL:  defaultRecordTTL,
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Logf("testing %s", test.description)
 		controller := &operatorv1.IngressController{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "default",
+				Name:        "default",
+				Annotations: test.annotations,
 			},
 			Status: operatorv1.IngressControllerStatus{
 				Domain: test.domain,
@@ -109,6 +149,52 @@ func TestDesiredWildcardDNSRecord(t *testing.T) {
 	}
 }
 
+// TestEnsureWildcardDNSRecordTypeTransition verifies that when the desired
+// record type changes (for example, from an A record for a load balancer IP to
+// a CNAME record for a load balancer hostname), the old-type record is deleted
+// and a record of the new type is created.
+func TestEnsureWildcardDNSRecordTypeTransition(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Domain: "apps.openshift.example.com",
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.LoadBalancerServiceStrategyType,
+			},
+		},
+	}
+	service := &corev1.Service{}
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme())
+	r := &reconciler{client: client}
+
+	if _, record, err := r.ensureWildcardDNSRecord(ic, service, true); err != nil {
+		t.Fatalf("failed to ensure initial dnsrecord: %v", err)
+	} else if record.Spec.RecordType != iov1.ARecordType {
+		t.Fatalf("expected initial record type %q, got %q", iov1.ARecordType, record.Spec.RecordType)
+	}
+
+	// The load balancer switches from an IP to a hostname, so the desired
+	// record becomes a CNAME.
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.cloud.example.com"}}
+	have, record, err := r.ensureWildcardDNSRecord(ic, service, true)
+	if err != nil {
+		t.Fatalf("failed to ensure dnsrecord after record type change: %v", err)
+	}
+	if !have {
+		t.Fatal("expected a dnsrecord after record type change")
+	}
+	if record.Spec.RecordType != iov1.CNAMERecordType {
+		t.Errorf("expected record type %q, got %q", iov1.CNAMERecordType, record.Spec.RecordType)
+	}
+	if expected := []string{"lb.cloud.example.com"}; !cmp.Equal(record.Spec.Targets, expected) {
+		t.Errorf("expected targets %v, got %v", expected, record.Spec.Targets)
+	}
+}
+
 func toYaml(obj interface{}) string {
 	yml, _ := yaml.Marshal(obj)
 	return string(yml)
@@ -0,0 +1,39 @@
+package ingress
+
+import (
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ensureStep is one step of an ensure orchestration.
+type ensureStep struct {
+	// name describes the resource the step ensures, for error wrapping.
+	name string
+
+	// run performs the step.
+	run func() error
+
+	// fatal indicates that a failure of this step makes the remaining
+	// steps pointless (for example, the resource they depend on could
+	// not be created), so the orchestration stops early.
+	fatal bool
+}
+
+// runEnsures runs the given ensure steps in order, collecting every error
+// rather than aborting at the first, so that a single reconcile pass
+// surfaces all problems with a broken ingresscontroller instead of one per
+// pass.  A failing fatal step stops the orchestration; its error is
+// aggregated with any errors already collected.
+func runEnsures(steps []ensureStep) error {
+	var errs []error
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to ensure %s: %v", step.name, err))
+			if step.fatal {
+				break
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,65 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRunEnsures(t *testing.T) {
+	step := func(name string, err error, fatal bool, ran *[]string) ensureStep {
+		return ensureStep{
+			name:  name,
+			fatal: fatal,
+			run: func() error {
+				*ran = append(*ran, name)
+				return err
+			},
+		}
+	}
+	t.Run("all steps run and all errors are aggregated", func(t *testing.T) {
+		var ran []string
+		err := runEnsures([]ensureStep{
+			step("first", fmt.Errorf("first failed"), false, &ran),
+			step("second", nil, false, &ran),
+			step("third", fmt.Errorf("third failed"), false, &ran),
+		})
+		if len(ran) != 3 {
+			t.Fatalf("expected all 3 steps to run, got %v", ran)
+		}
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		for _, expected := range []string{"failed to ensure first: first failed", "failed to ensure third: third failed"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error %q to contain %q", err.Error(), expected)
+			}
+		}
+	})
+	t.Run("a failing fatal step stops the orchestration", func(t *testing.T) {
+		var ran []string
+		err := runEnsures([]ensureStep{
+			step("first", fmt.Errorf("first failed"), false, &ran),
+			step("second", fmt.Errorf("second failed"), true, &ran),
+			step("third", nil, false, &ran),
+		})
+		if len(ran) != 2 {
+			t.Fatalf("expected only the first 2 steps to run, got %v", ran)
+		}
+		if err == nil || !strings.Contains(err.Error(), "first failed") || !strings.Contains(err.Error(), "second failed") {
+			t.Fatalf("expected both errors to be aggregated, got %v", err)
+		}
+	})
+	t.Run("no errors means no aggregate", func(t *testing.T) {
+		var ran []string
+		if err := runEnsures([]ensureStep{
+			step("first", nil, false, &ran),
+			step("second", nil, true, &ran),
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(ran) != 2 {
+			t.Fatalf("expected all 2 steps to run, got %v", ran)
+		}
+	})
+}
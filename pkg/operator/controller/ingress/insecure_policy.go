@@ -0,0 +1,138 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultInsecurePolicyAnnotation can be set on an ingresscontroller
+	// to "None", "Allow", or "Redirect" to set a default
+	// insecureEdgeTerminationPolicy for admitted routes that do not
+	// specify their own.  A route's own spec.tls value always takes
+	// precedence over the default.
+	DefaultInsecurePolicyAnnotation = "ingress.operator.openshift.io/default-insecure-edge-termination-policy"
+
+	// RouterDefaultInsecurePolicyEnvName is the router environment
+	// variable that configures the default insecureEdgeTerminationPolicy
+	// for routes that do not specify one.
+	RouterDefaultInsecurePolicyEnvName = "ROUTER_DEFAULT_INSECURE_EDGE_TERMINATION_POLICY"
+
+	// routeMetricsDefaultChunkSize is the default page size used when
+	// listing routes for route metrics.
+	routeMetricsDefaultChunkSize = int64(1000)
+)
+
+var (
+	routesDefaultInsecurePolicy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingress_controller_routes_default_insecure_policy",
+			Help: "Number of routes admitted by the ingresscontroller that fall back to the ingresscontroller's default insecureEdgeTerminationPolicy.",
+		}, []string{"ingresscontroller"})
+
+	routesExplicitInsecurePolicy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingress_controller_routes_explicit_insecure_policy",
+			Help: "Number of routes admitted by the ingresscontroller that explicitly set their own insecureEdgeTerminationPolicy.",
+		}, []string{"ingresscontroller"})
+)
+
+func init() {
+	prometheus.MustRegister(routesDefaultInsecurePolicy)
+	prometheus.MustRegister(routesExplicitInsecurePolicy)
+}
+
+// defaultInsecurePolicy returns the default insecureEdgeTerminationPolicy
+// configured on the ingresscontroller, and a boolean indicating whether a
+// valid policy is configured.  An invalid policy is logged and ignored.
+func defaultInsecurePolicy(ic *operatorv1.IngressController) (routev1.InsecureEdgeTerminationPolicyType, bool) {
+	val, ok := ic.Annotations[DefaultInsecurePolicyAnnotation]
+	if !ok {
+		return "", false
+	}
+	switch policy := routev1.InsecureEdgeTerminationPolicyType(val); policy {
+	case routev1.InsecureEdgeTerminationPolicyNone, routev1.InsecureEdgeTerminationPolicyAllow, routev1.InsecureEdgeTerminationPolicyRedirect:
+		return policy, true
+	default:
+		log.Info("ignoring invalid annotation value", "annotation", DefaultInsecurePolicyAnnotation, "value", val)
+		return "", false
+	}
+}
+
+// updateRouteInsecurePolicyMetrics recounts, for routes that the
+// ingresscontroller has admitted, how many explicitly set an
+// insecureEdgeTerminationPolicy and how many fall back to the
+// ingresscontroller's default, and updates the corresponding metrics.
+// Routes are listed in pages of the configured chunk size, and the counts are
+// accumulated incrementally across pages, so that a large route set is never
+// held in memory all at once.
+func (r *reconciler) updateRouteInsecurePolicyMetrics(ic *operatorv1.IngressController) error {
+	chunkSize := r.config.RouteMetricsChunkSize
+	if chunkSize <= 0 {
+		chunkSize = routeMetricsDefaultChunkSize
+	}
+	numDefault, numExplicit := 0, 0
+	continueToken := ""
+	for {
+		routeList := &routev1.RouteList{}
+		opts := []client.ListOption{client.Limit(chunkSize)}
+		if len(continueToken) != 0 {
+			opts = append(opts, client.Continue(continueToken))
+		}
+		if err := r.client.List(context.TODO(), routeList, opts...); err != nil {
+			return fmt.Errorf("failed to list routes: %v", err)
+		}
+		chunkDefault, chunkExplicit := countRouteInsecurePolicies(routeList.Items, ic.Name)
+		numDefault += chunkDefault
+		numExplicit += chunkExplicit
+		continueToken = routeList.Continue
+		if len(continueToken) == 0 {
+			break
+		}
+	}
+	routesDefaultInsecurePolicy.WithLabelValues(ic.Name).Set(float64(numDefault))
+	routesExplicitInsecurePolicy.WithLabelValues(ic.Name).Set(float64(numExplicit))
+	return nil
+}
+
+// countRouteInsecurePolicies counts how many of the given routes admitted by
+// the named router fall back to the default insecureEdgeTerminationPolicy and
+// how many set their own.  Routes are inspected in place to avoid copying.
+func countRouteInsecurePolicies(routes []routev1.Route, routerName string) (numDefault, numExplicit int) {
+	for i := range routes {
+		if !routeIsAdmittedBy(&routes[i], routerName) {
+			continue
+		}
+		if routes[i].Spec.TLS != nil && len(routes[i].Spec.TLS.InsecureEdgeTerminationPolicy) != 0 {
+			numExplicit++
+		} else {
+			numDefault++
+		}
+	}
+	return numDefault, numExplicit
+}
+
+// routeIsAdmittedBy returns true if the route has been admitted by the router
+// with the given name.
+func routeIsAdmittedBy(route *routev1.Route, routerName string) bool {
+	for _, ingress := range route.Status.Ingress {
+		if ingress.RouterName != routerName {
+			continue
+		}
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
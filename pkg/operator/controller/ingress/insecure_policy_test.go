@@ -0,0 +1,177 @@
+package ingress
+
+import (
+	"fmt"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDefaultInsecurePolicy(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotations    map[string]string
+		expectPolicy   bool
+		expectedPolicy routev1.InsecureEdgeTerminationPolicyType
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:    "Redirect",
+			annotations:    map[string]string{DefaultInsecurePolicyAnnotation: "Redirect"},
+			expectPolicy:   true,
+			expectedPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		},
+		{
+			description:    "None",
+			annotations:    map[string]string{DefaultInsecurePolicyAnnotation: "None"},
+			expectPolicy:   true,
+			expectedPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+		},
+		{
+			description: "invalid policy is ignored",
+			annotations: map[string]string{DefaultInsecurePolicyAnnotation: "Refuse"},
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: tc.annotations},
+		}
+		policy, ok := defaultInsecurePolicy(ic)
+		if ok != tc.expectPolicy {
+			t.Errorf("%q: expected ok=%v, got %v", tc.description, tc.expectPolicy, ok)
+		}
+		if policy != tc.expectedPolicy {
+			t.Errorf("%q: expected policy %q, got %q", tc.description, tc.expectedPolicy, policy)
+		}
+	}
+}
+
+func TestUpdateRouteInsecurePolicyMetrics(t *testing.T) {
+	route := func(name, routerName string, policy routev1.InsecureEdgeTerminationPolicyType) *routev1.Route {
+		r := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name},
+			Status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{{
+					RouterName: routerName,
+					Conditions: []routev1.RouteIngressCondition{{
+						Type:   routev1.RouteAdmitted,
+						Status: corev1.ConditionTrue,
+					}},
+				}},
+			},
+		}
+		if len(policy) != 0 {
+			r.Spec.TLS = &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: policy,
+			}
+		}
+		return r
+	}
+	objects := []runtime.Object{
+		route("explicit-redirect", "default", routev1.InsecureEdgeTerminationPolicyRedirect),
+		route("explicit-allow", "default", routev1.InsecureEdgeTerminationPolicyAllow),
+		route("fallback-1", "default", ""),
+		route("fallback-2", "default", ""),
+		route("fallback-3", "default", ""),
+		// Routes admitted by a different shard must not be counted.
+		route("other-shard", "internal", ""),
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objects...)
+	r := &reconciler{client: client}
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}
+	if err := r.updateRouteInsecurePolicyMetrics(ic); err != nil {
+		t.Fatalf("failed to update metrics: %v", err)
+	}
+	m := &dto.Metric{}
+	if err := routesDefaultInsecurePolicy.WithLabelValues("default").Write(m); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	if got := m.GetGauge().GetValue(); got != 3 {
+		t.Errorf("expected 3 routes using the default insecure policy, got %v", got)
+	}
+	m.Reset()
+	if err := routesExplicitInsecurePolicy.WithLabelValues("default").Write(m); err != nil {
+		t.Fatalf("failed to read metric: %v", err)
+	}
+	if got := m.GetGauge().GetValue(); got != 2 {
+		t.Errorf("expected 2 routes with an explicit insecure policy, got %v", got)
+	}
+}
+
+// benchmarkRoutes returns a large route set for benchmarking.
+func benchmarkRoutes(n int) []routev1.Route {
+	routes := make([]routev1.Route, n)
+	for i := range routes {
+		routes[i] = routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: fmt.Sprintf("route-%d", i)},
+			Status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{{
+					RouterName: "default",
+					Conditions: []routev1.RouteIngressCondition{{
+						Type:   routev1.RouteAdmitted,
+						Status: corev1.ConditionTrue,
+					}},
+				}},
+			},
+		}
+		if i%3 == 0 {
+			routes[i].Spec.TLS = &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			}
+		}
+	}
+	return routes
+}
+
+// BenchmarkCountRouteInsecurePolicies measures the in-place counting used by
+// the paginated metrics path.
+func BenchmarkCountRouteInsecurePolicies(b *testing.B) {
+	routes := benchmarkRoutes(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		countRouteInsecurePolicies(routes, "default")
+	}
+}
+
+// BenchmarkCountRouteInsecurePoliciesCopying measures the previous approach,
+// which copied each route while ranging over the list, for comparison with
+// BenchmarkCountRouteInsecurePolicies.
+func BenchmarkCountRouteInsecurePoliciesCopying(b *testing.B) {
+	routes := benchmarkRoutes(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		numDefault, numExplicit := 0, 0
+		for _, route := range routes {
+			route := route
+			if !routeIsAdmittedBy(&route, "default") {
+				continue
+			}
+			if route.Spec.TLS != nil && len(route.Spec.TLS.InsecureEdgeTerminationPolicy) != 0 {
+				numExplicit++
+			} else {
+				numDefault++
+			}
+		}
+		_, _ = numDefault, numExplicit
+	}
+}
@@ -32,7 +32,7 @@ func (r *reconciler) ensureInternalIngressControllerService(ic *operatorv1.Ingre
 		return current, nil
 	}
 
-	if err := r.client.Create(context.TODO(), desired); err != nil {
+	if err := r.createManagedObject(desired); err != nil {
 		return nil, fmt.Errorf("failed to create internal ingresscontroller service: %v", err)
 	}
 	log.Info("created internal ingresscontroller service", "service", desired)
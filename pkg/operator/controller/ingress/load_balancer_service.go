@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -12,6 +13,7 @@ import (
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 	oputil "github.com/openshift/cluster-ingress-operator/pkg/util"
+	retryable "github.com/openshift/cluster-ingress-operator/pkg/util/retryableerror"
 	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
 
 	corev1 "k8s.io/api/core/v1"
@@ -177,6 +179,9 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 		}
 		return r.currentLoadBalancerService(ci)
 	case wantLBS && haveLBS:
+		if loadBalancerServiceNeedsRecreate(currentLBService, desiredLBService, platform) {
+			return r.recreateLoadBalancerService(ci, currentLBService)
+		}
 		if deletedFinalizer, err := r.deleteLoadBalancerServiceFinalizer(currentLBService); err != nil {
 			return true, currentLBService, fmt.Errorf("failed to remove finalizer from load balancer service: %v", err)
 		} else if deletedFinalizer {
@@ -194,6 +199,50 @@ func (r *reconciler) ensureLoadBalancerService(ci *operatorv1.IngressController,
 	return true, currentLBService, nil
 }
 
+// loadBalancerServiceNeedsRecreate returns true if transitioning the current
+// load balancer service to the desired state requires deleting and recreating
+// the service.  The annotations that select the load balancer's scope are only
+// honored by cloud providers at provisioning time, so a scope change cannot be
+// reconciled in place.
+func loadBalancerServiceNeedsRecreate(current, desired *corev1.Service, platform *configv1.PlatformStatus) bool {
+	if platform == nil {
+		return false
+	}
+	for name := range InternalLBAnnotations[platform.Type] {
+		if current.Annotations[name] != desired.Annotations[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// recreateLoadBalancerService deletes the current load balancer service so
+// that the next reconcile can recreate it with the desired provisioning-time
+// parameters.  DNS is withdrawn first: the wildcard DNS record carries a
+// finalizer that the dns controller removes only after the provider records
+// are deleted, so the service is not deleted until the record is gone.  This
+// keeps clients from resolving the name to a load balancer that is about to
+// disappear; once the service is recreated, the wildcard record is republished
+// with the new load balancer's address.
+func (r *reconciler) recreateLoadBalancerService(ci *operatorv1.IngressController, current *corev1.Service) (bool, *corev1.Service, error) {
+	if err := r.deleteWildcardDNSRecord(ci); err != nil {
+		return true, current, fmt.Errorf("failed to withdraw wildcard dnsrecord before recreating load balancer service: %v", err)
+	}
+	if haveRec, _, err := r.currentWildcardDNSRecord(ci); err != nil {
+		return true, current, err
+	} else if haveRec {
+		return true, current, retryable.New(fmt.Errorf("waiting for wildcard dnsrecord to be withdrawn before recreating load balancer service for ingresscontroller %s/%s", ci.Namespace, ci.Name), 15*time.Second)
+	}
+	if _, err := r.deleteLoadBalancerServiceFinalizer(current); err != nil {
+		return true, current, fmt.Errorf("failed to remove finalizer from load balancer service: %v", err)
+	}
+	if err := r.deleteLoadBalancerService(current, &crclient.DeleteOptions{}); err != nil {
+		return true, current, err
+	}
+	log.Info("deleted load balancer service for recreation", "namespace", current.Namespace, "name", current.Name)
+	return false, nil, nil
+}
+
 // desiredLoadBalancerService returns the desired LB service for a
 // ingresscontroller, or nil if an LB service isn't desired. An LB service is
 // desired if the high availability type is Cloud. An LB service will declare an
@@ -334,7 +383,7 @@ func (r *reconciler) finalizeLoadBalancerService(ci *operatorv1.IngressControlle
 
 // createLoadBalancerService creates a load balancer service.
 func (r *reconciler) createLoadBalancerService(service *corev1.Service) error {
-	if err := r.client.Create(context.TODO(), service); err != nil {
+	if err := r.createManagedObject(service); err != nil {
 		return fmt.Errorf("failed to create load balancer service %s/%s: %v", service.Namespace, service.Name, err)
 	}
 	log.Info("created load balancer service", "namespace", service.Namespace, "name", service.Name)
@@ -1,15 +1,27 @@
 package ingress
 
 import (
+	"context"
 	"fmt"
-	corev1 "k8s.io/api/core/v1"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	retryable "github.com/openshift/cluster-ingress-operator/pkg/util/retryableerror"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredLoadBalancerService(t *testing.T) {
@@ -522,3 +534,136 @@ func TestLoadBalancerServiceChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadBalancerServiceNeedsRecreate(t *testing.T) {
+	service := func(annotations map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		}
+	}
+	awsPlatform := &configv1.PlatformStatus{Type: configv1.AWSPlatformType}
+	testCases := []struct {
+		description string
+		current     *corev1.Service
+		desired     *corev1.Service
+		platform    *configv1.PlatformStatus
+		expect      bool
+	}{
+		{
+			description: "no platform",
+			current:     service(nil),
+			desired:     service(nil),
+		},
+		{
+			description: "scope unchanged",
+			current:     service(map[string]string{awsInternalLBAnnotation: "0.0.0.0/0"}),
+			desired:     service(map[string]string{awsInternalLBAnnotation: "0.0.0.0/0"}),
+			platform:    awsPlatform,
+			expect:      false,
+		},
+		{
+			description: "external to internal",
+			current:     service(nil),
+			desired:     service(map[string]string{awsInternalLBAnnotation: "0.0.0.0/0"}),
+			platform:    awsPlatform,
+			expect:      true,
+		},
+		{
+			description: "internal to external",
+			current:     service(map[string]string{awsInternalLBAnnotation: "0.0.0.0/0"}),
+			desired:     service(nil),
+			platform:    awsPlatform,
+			expect:      true,
+		},
+		{
+			description: "unrelated annotation change",
+			current:     service(map[string]string{awsLBHealthCheckIntervalAnnotation: "5"}),
+			desired:     service(map[string]string{awsLBHealthCheckIntervalAnnotation: "10"}),
+			platform:    awsPlatform,
+			expect:      false,
+		},
+	}
+	for _, tc := range testCases {
+		if actual := loadBalancerServiceNeedsRecreate(tc.current, tc.desired, tc.platform); actual != tc.expect {
+			t.Errorf("%q: expected %v, got %v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+// dnsDrainTestClient simulates the dns controller's finalizer handling: while
+// holdRecord is true, deleting a dnsrecord leaves it in place, as it would be
+// while the dns controller is still withdrawing the provider records.  The
+// client also records the order of deletions.
+type dnsDrainTestClient struct {
+	crclient.Client
+	deletions  []string
+	holdRecord bool
+}
+
+func (c *dnsDrainTestClient) Delete(ctx context.Context, obj runtime.Object, opts ...crclient.DeleteOption) error {
+	switch obj.(type) {
+	case *iov1.DNSRecord:
+		c.deletions = append(c.deletions, "dnsrecord")
+		if c.holdRecord {
+			return nil
+		}
+	case *corev1.Service:
+		c.deletions = append(c.deletions, "service")
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestRecreateLoadBalancerServiceDrainsDNSFirst(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default"},
+	}
+	recordName := controller.WildcardDNSRecordName(ic)
+	record := &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{Namespace: recordName.Namespace, Name: recordName.Name},
+	}
+	serviceName := controller.LoadBalancerServiceName(ic)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: serviceName.Namespace, Name: serviceName.Name},
+	}
+	client := &dnsDrainTestClient{
+		Client:     fake.NewFakeClientWithScheme(operatorclient.GetScheme(), record, service.DeepCopy()),
+		holdRecord: true,
+	}
+	r := &reconciler{client: client}
+
+	// While the dnsrecord is still being withdrawn, the service must not be
+	// deleted, and the reconciler must ask to be retried.
+	_, _, err := r.recreateLoadBalancerService(ic, service)
+	if err == nil {
+		t.Fatal("expected a retryable error while the dnsrecord is being withdrawn, got none")
+	}
+	if _, ok := err.(retryable.Error); !ok {
+		t.Fatalf("expected a retryable error, got %T: %v", err, err)
+	}
+	if haveSvc, _, err := r.currentLoadBalancerService(ic); err != nil {
+		t.Fatalf("failed to get load balancer service: %v", err)
+	} else if !haveSvc {
+		t.Fatal("load balancer service was deleted before the dnsrecord was withdrawn")
+	}
+
+	// Once the dnsrecord is gone, the service is deleted, strictly after
+	// the record.
+	client.holdRecord = false
+	if _, _, err := r.recreateLoadBalancerService(ic, service); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if haveSvc, _, err := r.currentLoadBalancerService(ic); err != nil {
+		t.Fatalf("failed to get load balancer service: %v", err)
+	} else if haveSvc {
+		t.Fatal("expected load balancer service to be deleted")
+	}
+	expected := []string{"dnsrecord", "dnsrecord", "service"}
+	if len(client.deletions) != len(expected) {
+		t.Fatalf("expected deletions %v, got %v", expected, client.deletions)
+	}
+	for i := range expected {
+		if client.deletions[i] != expected[i] {
+			t.Fatalf("expected deletions %v, got %v", expected, client.deletions)
+		}
+	}
+}
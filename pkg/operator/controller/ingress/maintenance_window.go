@@ -0,0 +1,157 @@
+package ingress
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	retryable "github.com/openshift/cluster-ingress-operator/pkg/util/retryableerror"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// MaintenanceWindowAnnotation is an annotation on an ingresscontroller
+	// that specifies a daily maintenance window, in UTC, as a time range
+	// of the form "HH:MM-HH:MM" (for example, "22:00-04:00"; the range may
+	// wrap past midnight).  When a maintenance window is configured,
+	// router deployment updates that are not urgent are deferred until
+	// the window opens, so that router rollouts do not disrupt traffic
+	// during peak hours.  Urgent updates, such as image updates and
+	// changes to the certificate material that the router mounts, are
+	// applied immediately.  Note that rotation of the contents of an
+	// already-mounted certificate secret never rolls the deployment and
+	// so is never deferred.
+	MaintenanceWindowAnnotation = "ingress.operator.openshift.io/maintenance-window"
+
+	// IngressControllerDeploymentRolloutPendingConditionType is the
+	// status condition type that reports whether a router deployment
+	// update is deferred until the next maintenance window.
+	IngressControllerDeploymentRolloutPendingConditionType = "DeploymentRolloutPending"
+)
+
+// maintenanceWindow is a daily time window, expressed as offsets from
+// midnight UTC.  The window may wrap past midnight, in which case start is
+// greater than end.
+type maintenanceWindow struct {
+	start, end time.Duration
+}
+
+// maintenanceWindowFromAnnotation returns the maintenance window configured
+// on the given ingresscontroller, if a valid one is configured.
+func maintenanceWindowFromAnnotation(ic *operatorv1.IngressController) (*maintenanceWindow, bool) {
+	val, ok := ic.Annotations[MaintenanceWindowAnnotation]
+	if !ok {
+		return nil, false
+	}
+	window, err := parseMaintenanceWindow(val)
+	if err != nil {
+		log.Info("ignoring invalid annotation value", "annotation", MaintenanceWindowAnnotation, "value", val, "error", err.Error(), "namespace", ic.Namespace, "name", ic.Name)
+		return nil, false
+	}
+	return window, true
+}
+
+// parseMaintenanceWindow parses a maintenance window of the form
+// "HH:MM-HH:MM".
+func parseMaintenanceWindow(val string) (*maintenanceWindow, error) {
+	var startHour, startMinute, endHour, endMinute int
+	if n, err := fmt.Sscanf(val, "%d:%d-%d:%d", &startHour, &startMinute, &endHour, &endMinute); err != nil || n != 4 {
+		return nil, fmt.Errorf("expected a time range of the form \"HH:MM-HH:MM\"")
+	}
+	if startHour < 0 || startHour > 23 || endHour < 0 || endHour > 23 {
+		return nil, fmt.Errorf("hours must be between 0 and 23")
+	}
+	if startMinute < 0 || startMinute > 59 || endMinute < 0 || endMinute > 59 {
+		return nil, fmt.Errorf("minutes must be between 0 and 59")
+	}
+	window := &maintenanceWindow{
+		start: time.Duration(startHour)*time.Hour + time.Duration(startMinute)*time.Minute,
+		end:   time.Duration(endHour)*time.Hour + time.Duration(endMinute)*time.Minute,
+	}
+	if window.start == window.end {
+		return nil, fmt.Errorf("the window must not be empty")
+	}
+	return window, nil
+}
+
+// contains returns true if the given time falls within the window.
+func (w *maintenanceWindow) contains(t time.Time) bool {
+	t = t.UTC()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	// The window wraps past midnight.
+	return offset >= w.start || offset < w.end
+}
+
+// nextStart returns the next time at or after the given time at which the
+// window opens.  If the given time is within the window, the given time is
+// returned.
+func (w *maintenanceWindow) nextStart(t time.Time) time.Time {
+	if w.contains(t) {
+		return t
+	}
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	next := midnight.Add(w.start)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// deploymentChangeIsUrgent returns true if the difference between the
+// current and updated router deployments is urgent and must be applied
+// immediately, regardless of any configured maintenance window.  Image
+// updates and changes to the volumes or volume mounts are urgent because
+// they deliver security fixes and change which certificate and key material
+// the router serves.  Everything else, such as environment, replicas, and
+// node placement, can wait for the maintenance window.
+func deploymentChangeIsUrgent(current, updated *appsv1.Deployment) bool {
+	currentContainer := current.Spec.Template.Spec.Containers[0]
+	updatedContainer := updated.Spec.Template.Spec.Containers[0]
+	if currentContainer.Image != updatedContainer.Image {
+		return true
+	}
+	if !reflect.DeepEqual(current.Spec.Template.Spec.Volumes, updated.Spec.Template.Spec.Volumes) {
+		return true
+	}
+	if !reflect.DeepEqual(currentContainer.VolumeMounts, updatedContainer.VolumeMounts) {
+		return true
+	}
+	return false
+}
+
+// computeDeploymentRolloutPendingCondition computes the ingresscontroller's
+// "DeploymentRolloutPending" status condition, which reports whether a
+// router deployment update is deferred until the next maintenance window.
+// If an update is deferred, a retryable error is returned as well so that
+// the update is reconciled again when the window opens.
+func (r *reconciler) computeDeploymentRolloutPendingCondition(ic *operatorv1.IngressController) (operatorv1.OperatorCondition, error) {
+	next, ok := r.deferredRollouts[deferredRolloutKey(ic)]
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerDeploymentRolloutPendingConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "NoRolloutPending",
+			Message: "No router deployment update is deferred.",
+		}, nil
+	}
+	condition := operatorv1.OperatorCondition{
+		Type:    IngressControllerDeploymentRolloutPendingConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "MaintenanceWindowPending",
+		Message: fmt.Sprintf("A router deployment update is deferred until the maintenance window at %s.", next.Format(time.RFC3339)),
+	}
+	return condition, retryable.New(fmt.Errorf("router deployment update for ingresscontroller %s is deferred until %s", ic.Name, next.Format(time.RFC3339)), next.Sub(clock.Now()))
+}
+
+// deferredRolloutKey returns the key under which deferred rollouts are
+// tracked for the given ingresscontroller.
+func deferredRolloutKey(ic *operatorv1.IngressController) string {
+	return fmt.Sprintf("%s/%s", ic.Namespace, ic.Name)
+}
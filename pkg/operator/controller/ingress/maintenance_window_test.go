@@ -0,0 +1,217 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	testCases := []struct {
+		description string
+		value       string
+		expectValid bool
+	}{
+		{
+			description: "valid window",
+			value:       "22:00-04:00",
+			expectValid: true,
+		},
+		{
+			description: "valid window within one day",
+			value:       "01:30-03:45",
+			expectValid: true,
+		},
+		{
+			description: "missing end time",
+			value:       "22:00",
+		},
+		{
+			description: "hour out of range",
+			value:       "22:00-25:00",
+		},
+		{
+			description: "minute out of range",
+			value:       "22:61-04:00",
+		},
+		{
+			description: "empty window",
+			value:       "06:00-06:00",
+		},
+		{
+			description: "not a time range",
+			value:       "nightly",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := parseMaintenanceWindow(tc.value)
+		if tc.expectValid && err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		} else if !tc.expectValid && err == nil {
+			t.Errorf("%q: expected error, got none", tc.description)
+		}
+	}
+}
+
+func TestMaintenanceWindowContainsAndNextStart(t *testing.T) {
+	date := func(hour, minute int) time.Time {
+		return time.Date(2021, time.February, 3, hour, minute, 0, 0, time.UTC)
+	}
+	testCases := []struct {
+		description       string
+		window            string
+		time              time.Time
+		expectContains    bool
+		expectedNextStart time.Time
+	}{
+		{
+			description:       "before the window",
+			window:            "22:00-04:00",
+			time:              date(12, 0),
+			expectedNextStart: date(22, 0),
+		},
+		{
+			description:    "inside the window before midnight",
+			window:         "22:00-04:00",
+			time:           date(23, 30),
+			expectContains: true,
+		},
+		{
+			description:    "inside the window after midnight",
+			window:         "22:00-04:00",
+			time:           date(2, 0),
+			expectContains: true,
+		},
+		{
+			description:       "just past the end of a wrapping window",
+			window:            "22:00-04:00",
+			time:              date(4, 0),
+			expectedNextStart: date(22, 0),
+		},
+		{
+			description:    "inside a same-day window",
+			window:         "01:00-03:00",
+			time:           date(2, 0),
+			expectContains: true,
+		},
+		{
+			description:       "after a same-day window",
+			window:            "01:00-03:00",
+			time:              date(5, 0),
+			expectedNextStart: date(1, 0).AddDate(0, 0, 1),
+		},
+	}
+	for _, tc := range testCases {
+		window, err := parseMaintenanceWindow(tc.window)
+		if err != nil {
+			t.Fatalf("%q: failed to parse window: %v", tc.description, err)
+		}
+		if contains := window.contains(tc.time); contains != tc.expectContains {
+			t.Errorf("%q: expected contains to be %t, got %t", tc.description, tc.expectContains, contains)
+		}
+		if tc.expectContains {
+			if next := window.nextStart(tc.time); !next.Equal(tc.time) {
+				t.Errorf("%q: expected nextStart to return the given time, got %s", tc.description, next)
+			}
+		} else if next := window.nextStart(tc.time); !next.Equal(tc.expectedNextStart) {
+			t.Errorf("%q: expected nextStart to be %s, got %s", tc.description, tc.expectedNextStart, next)
+		}
+	}
+}
+
+// TestUpdateRouterDeploymentMaintenanceWindow verifies that non-urgent router
+// deployment updates are deferred until the maintenance window while urgent
+// updates are applied immediately, and that the "DeploymentRolloutPending"
+// condition reports any deferral.
+func TestUpdateRouterDeploymentMaintenanceWindow(t *testing.T) {
+	// Noon, outside the configured 22:00-04:00 window.
+	fakeClock := utilclock.NewFakeClock(time.Date(2021, time.February, 3, 12, 0, 0, 0, time.UTC))
+	clock = fakeClock
+	defer func() { clock = utilclock.RealClock{} }()
+
+	ci := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+			Annotations: map[string]string{
+				MaintenanceWindowAnnotation: "22:00-04:00",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	current, err := desiredRouterDeployment(ci, "quay.io/openshift/router:v1", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+	if err != nil {
+		t.Fatalf("failed to build router deployment: %v", err)
+	}
+	r := &reconciler{
+		client:           fake.NewFakeClientWithScheme(operatorclient.GetScheme(), current),
+		recorder:         record.NewFakeRecorder(10),
+		deferredRollouts: map[string]time.Time{},
+	}
+
+	// An environment-only change outside the window is deferred.
+	desired := current.DeepCopy()
+	desired.Spec.Template.Spec.Containers[0].Env = append(desired.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "ROUTER_TEST", Value: "1"})
+	if updated, err := r.updateRouterDeployment(ci, current, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if updated {
+		t.Fatal("expected the non-urgent update to be deferred")
+	}
+	if _, ok := r.deferredRollouts[deferredRolloutKey(ci)]; !ok {
+		t.Fatal("expected the deferred rollout to be tracked")
+	}
+	if condition, err := r.computeDeploymentRolloutPendingCondition(ci); condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected the %s condition to be true, got %s", IngressControllerDeploymentRolloutPendingConditionType, condition.Status)
+	} else if err == nil {
+		t.Error("expected a retryable error for the deferred rollout, got none")
+	}
+
+	// An image change outside the window is urgent and applies immediately.
+	desired = current.DeepCopy()
+	desired.Spec.Template.Spec.Containers[0].Image = "quay.io/openshift/router:v2"
+	if updated, err := r.updateRouterDeployment(ci, current, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !updated {
+		t.Fatal("expected the urgent update to be applied immediately")
+	}
+	if _, ok := r.deferredRollouts[deferredRolloutKey(ci)]; ok {
+		t.Fatal("expected the deferred rollout tracking to be cleared by the urgent update")
+	}
+
+	// Once the window opens, the non-urgent change applies.
+	fakeClock.SetTime(time.Date(2021, time.February, 3, 22, 30, 0, 0, time.UTC))
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: current.Namespace, Name: current.Name}, current); err != nil {
+		t.Fatalf("failed to get router deployment: %v", err)
+	}
+	desired = current.DeepCopy()
+	desired.Spec.Template.Spec.Containers[0].Env = append(desired.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "ROUTER_TEST", Value: "1"})
+	if updated, err := r.updateRouterDeployment(ci, current, desired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !updated {
+		t.Fatal("expected the non-urgent update to be applied during the window")
+	}
+	if condition, err := r.computeDeploymentRolloutPendingCondition(ci); condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected the %s condition to be false, got %s", IngressControllerDeploymentRolloutPendingConditionType, condition.Status)
+	} else if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
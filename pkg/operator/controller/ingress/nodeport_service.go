@@ -44,7 +44,7 @@ func (r *reconciler) ensureNodePortService(ic *operatorv1.IngressController, dep
 		}
 		return false, nil, nil
 	case wantService && !haveService:
-		if err := r.client.Create(context.TODO(), desired); err != nil {
+		if err := r.createManagedObject(desired); err != nil {
 			return false, nil, fmt.Errorf("failed to create NodePort service: %v", err)
 		}
 		log.Info("created NodePort service", "service", desired)
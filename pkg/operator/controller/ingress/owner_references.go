@@ -0,0 +1,78 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// additionalOwnerAllowedKinds maps the kinds that may be named as an
+// additional owner of the router deployment to their API versions.  The set is
+// deliberately small: an additional owner exists so that a wrapper operator
+// can tear the router down by deleting a single parent object, and these kinds
+// cover the parent objects such wrappers create.
+var additionalOwnerAllowedKinds = map[string]string{
+	"ConfigMap":  "v1",
+	"Deployment": "apps/v1",
+}
+
+// additionalOwnerReference resolves the additional owner reference named by
+// the operator configuration, if any.  The owner is given as "Kind/name" and
+// must be an existing object of an allowed kind in the operand namespace.
+// The reference is added to the router deployment alongside the
+// ingresscontroller ownership; the router's services are owned by the
+// deployment, so deleting the additional owner cascades to them as well.
+func (r *reconciler) additionalOwnerReference() (*metav1.OwnerReference, error) {
+	if len(r.config.AdditionalOwnerReference) == 0 {
+		return nil, nil
+	}
+	parts := strings.SplitN(r.config.AdditionalOwnerReference, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("additional owner reference %q must have the format Kind/name", r.config.AdditionalOwnerReference)
+	}
+	kind, name := parts[0], parts[1]
+	apiVersion, ok := additionalOwnerAllowedKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("additional owner reference kind %q is not allowed", kind)
+	}
+	var owner interface {
+		runtime.Object
+		metav1.Object
+	}
+	switch kind {
+	case "ConfigMap":
+		owner = &corev1.ConfigMap{}
+	case "Deployment":
+		owner = &appsv1.Deployment{}
+	}
+	ownerName := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: name}
+	if err := r.client.Get(context.TODO(), ownerName, owner); err != nil {
+		return nil, fmt.Errorf("failed to get additional owner %s %s: %v", kind, ownerName, err)
+	}
+	return &metav1.OwnerReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		UID:        owner.GetUID(),
+	}, nil
+}
+
+// setAdditionalOwnerReference appends the given owner reference to the object
+// if the object does not already reference that owner.
+func setAdditionalOwnerReference(meta *metav1.ObjectMeta, ref *metav1.OwnerReference) {
+	for _, existing := range meta.OwnerReferences {
+		if existing.UID == ref.UID {
+			return
+		}
+	}
+	meta.OwnerReferences = append(meta.OwnerReferences, *ref)
+}
@@ -0,0 +1,122 @@
+package ingress
+
+import (
+	"testing"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdditionalOwnerReference(t *testing.T) {
+	owner := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.DefaultOperandNamespace,
+			Name:      "parent",
+			UID:       types.UID("abc-123"),
+		},
+	}
+	testCases := []struct {
+		description string
+		configured  string
+		objects     []runtime.Object
+		expectRef   bool
+		expectError bool
+	}{
+		{
+			description: "no additional owner configured",
+		},
+		{
+			description: "configured owner exists",
+			configured:  "ConfigMap/parent",
+			objects:     []runtime.Object{owner},
+			expectRef:   true,
+		},
+		{
+			description: "configured owner does not exist",
+			configured:  "ConfigMap/parent",
+			expectError: true,
+		},
+		{
+			description: "kind is not allowed",
+			configured:  "Secret/parent",
+			expectError: true,
+		},
+		{
+			description: "malformed reference",
+			configured:  "parent",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), tc.objects...)
+		r := &reconciler{
+			client: client,
+			config: Config{AdditionalOwnerReference: tc.configured},
+		}
+		ref, err := r.additionalOwnerReference()
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if (ref != nil) != tc.expectRef {
+			t.Errorf("%q: expected reference presence %v, got %v", tc.description, tc.expectRef, ref != nil)
+			continue
+		}
+		if ref == nil {
+			continue
+		}
+		if ref.Kind != "ConfigMap" || ref.APIVersion != "v1" || ref.Name != "parent" || ref.UID != owner.UID {
+			t.Errorf("%q: unexpected reference: %+v", tc.description, ref)
+		}
+	}
+}
+
+func TestSetAdditionalOwnerReference(t *testing.T) {
+	icRef := metav1.OwnerReference{
+		APIVersion: "operator.openshift.io/v1",
+		Kind:       "IngressController",
+		Name:       "default",
+		UID:        types.UID("ic-uid"),
+	}
+	extraRef := &metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       "parent",
+		UID:        types.UID("abc-123"),
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       controller.DefaultOperandNamespace,
+			Name:            "router-default",
+			OwnerReferences: []metav1.OwnerReference{icRef},
+		},
+	}
+	setAdditionalOwnerReference(&deployment.ObjectMeta, extraRef)
+	if len(deployment.OwnerReferences) != 2 {
+		t.Fatalf("expected 2 owner references, got %d: %+v", len(deployment.OwnerReferences), deployment.OwnerReferences)
+	}
+	if deployment.OwnerReferences[1].UID != extraRef.UID {
+		t.Errorf("expected additional owner reference %+v, got %+v", extraRef, deployment.OwnerReferences[1])
+	}
+	// Appending the same owner again must be a no-op.
+	setAdditionalOwnerReference(&deployment.ObjectMeta, extraRef)
+	if len(deployment.OwnerReferences) != 2 {
+		t.Errorf("expected 2 owner references after re-adding, got %d", len(deployment.OwnerReferences))
+	}
+}
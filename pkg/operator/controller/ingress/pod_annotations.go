@@ -0,0 +1,114 @@
+package ingress
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// PodTemplateAnnotationsAnnotation can be set on an ingresscontroller
+	// to a JSON object of annotation keys and values that the operator
+	// sets on the router deployment's pod template, merged with the
+	// annotations the operator itself manages.  This lets external tooling
+	// that keys off pod annotations (for example, sidecar injectors) work
+	// with router pods.  Removing a key from the annotation removes it
+	// from the pod template.
+	PodTemplateAnnotationsAnnotation = "ingress.operator.openshift.io/pod-template-annotations"
+
+	// managedPodTemplateAnnotationsAnnotation is set on the router pod
+	// template to record which annotation keys were added through
+	// PodTemplateAnnotationsAnnotation, so that keys that are no longer
+	// configured can be removed without touching annotations set by other
+	// agents.
+	managedPodTemplateAnnotationsAnnotation = "ingress.operator.openshift.io/managed-pod-template-annotations"
+)
+
+// podTemplateAnnotations returns the extra pod template annotations configured
+// on the ingresscontroller.  An error is returned if the annotation value is
+// not valid JSON or tries to set an annotation that the operator manages.
+func podTemplateAnnotations(ic *operatorv1.IngressController) (map[string]string, error) {
+	val, ok := ic.Annotations[PodTemplateAnnotationsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	annotations := map[string]string{}
+	if err := json.Unmarshal([]byte(val), &annotations); err != nil {
+		return nil, fmt.Errorf("failed to parse annotation value %q: %v", val, err)
+	}
+	for key := range annotations {
+		if strings.HasPrefix(key, "ingress.operator.openshift.io/") || key == LivenessGracePeriodSecondsAnnotation {
+			return nil, fmt.Errorf("annotation %q is managed by the operator and cannot be configured", key)
+		}
+	}
+	if len(annotations) == 0 {
+		return nil, nil
+	}
+	return annotations, nil
+}
+
+// applyPodTemplateAnnotations sets the given extra annotations on the
+// deployment's pod template and records their keys so that they can be
+// removed once they are no longer configured.
+func applyPodTemplateAnnotations(deployment *appsv1.Deployment, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	keys := make([]string, 0, len(annotations))
+	for key, val := range annotations {
+		deployment.Spec.Template.Annotations[key] = val
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	deployment.Spec.Template.Annotations[managedPodTemplateAnnotationsAnnotation] = strings.Join(keys, ",")
+}
+
+// managedPodTemplateAnnotationKeys returns the keys recorded in the pod
+// template's bookkeeping annotation.
+func managedPodTemplateAnnotationKeys(annotations map[string]string) []string {
+	val, ok := annotations[managedPodTemplateAnnotationsAnnotation]
+	if !ok || len(val) == 0 {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// syncManagedPodTemplateAnnotations returns the current pod template
+// annotations updated to agree with the expected pod template's managed
+// annotations: keys that are no longer managed are removed, and managed keys
+// take the expected values.  Annotations set by other agents are untouched.
+func syncManagedPodTemplateAnnotations(current, expected map[string]string) map[string]string {
+	updated := map[string]string{}
+	for key, val := range current {
+		updated[key] = val
+	}
+	expectedKeys := map[string]bool{}
+	for _, key := range managedPodTemplateAnnotationKeys(expected) {
+		expectedKeys[key] = true
+	}
+	for _, key := range managedPodTemplateAnnotationKeys(current) {
+		if !expectedKeys[key] {
+			delete(updated, key)
+		}
+	}
+	for key := range expectedKeys {
+		updated[key] = expected[key]
+	}
+	if val, ok := expected[managedPodTemplateAnnotationsAnnotation]; ok {
+		updated[managedPodTemplateAnnotationsAnnotation] = val
+	} else {
+		delete(updated, managedPodTemplateAnnotationsAnnotation)
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	return updated
+}
@@ -0,0 +1,135 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodTemplateAnnotations(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotation     string
+		haveAnnotation bool
+		expectError    bool
+		expected       map[string]string
+	}{
+		{
+			description: "no annotation",
+		},
+		{
+			description:    "valid annotations",
+			annotation:     `{"sidecar.example.com/inject":"true","tooling.example.com/owner":"mesh"}`,
+			haveAnnotation: true,
+			expected: map[string]string{
+				"sidecar.example.com/inject": "true",
+				"tooling.example.com/owner":  "mesh",
+			},
+		},
+		{
+			description:    "invalid JSON",
+			annotation:     `inject=true`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+		{
+			description:    "operator-managed key is rejected",
+			annotation:     `{"ingress.operator.openshift.io/hard-stop-after":"1h"}`,
+			haveAnnotation: true,
+			expectError:    true,
+		},
+		{
+			description:    "empty object",
+			annotation:     `{}`,
+			haveAnnotation: true,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if tc.haveAnnotation {
+			ic.Annotations = map[string]string{PodTemplateAnnotationsAnnotation: tc.annotation}
+		}
+		annotations, err := podTemplateAnnotations(ic)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if len(annotations) != len(tc.expected) {
+			t.Errorf("%q: expected %d annotations, got %d", tc.description, len(tc.expected), len(annotations))
+			continue
+		}
+		for key, val := range tc.expected {
+			if annotations[key] != val {
+				t.Errorf("%q: expected annotation %q to be %q, got %q", tc.description, key, val, annotations[key])
+			}
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentPodTemplateAnnotations(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				PodTemplateAnnotationsAnnotation: `{"sidecar.example.com/inject":"true"}`,
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	ingressConfig := &configv1.Ingress{}
+	apiConfig := &configv1.APIServer{}
+	networkConfig := &configv1.Network{}
+	deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("failed to generate desired router deployment: %v", err)
+	}
+	if actual := deployment.Spec.Template.Annotations["sidecar.example.com/inject"]; actual != "true" {
+		t.Errorf("expected pod template annotation value %q, got %q", "true", actual)
+	}
+	if actual := deployment.Spec.Template.Annotations[managedPodTemplateAnnotationsAnnotation]; actual != "sidecar.example.com/inject" {
+		t.Errorf("expected managed-keys annotation %q, got %q", "sidecar.example.com/inject", actual)
+	}
+
+	// An unchanged set of annotations must not cause an update.
+	if changed, _ := deploymentConfigChanged(deployment, deployment.DeepCopy()); changed {
+		t.Error("expected no update for an unchanged deployment")
+	}
+
+	// Removing the configured annotations removes them from the template
+	// without touching annotations set by other agents.
+	current := deployment.DeepCopy()
+	current.Spec.Template.Annotations["other.example.com/unmanaged"] = "keep"
+	ic.Annotations = nil
+	expected, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("failed to generate desired router deployment: %v", err)
+	}
+	changed, updated := deploymentConfigChanged(current, expected)
+	if !changed {
+		t.Fatal("expected an update after removing the configured annotations")
+	}
+	if _, ok := updated.Spec.Template.Annotations["sidecar.example.com/inject"]; ok {
+		t.Error("expected configured annotation to be removed from the pod template")
+	}
+	if _, ok := updated.Spec.Template.Annotations[managedPodTemplateAnnotationsAnnotation]; ok {
+		t.Error("expected managed-keys annotation to be removed from the pod template")
+	}
+	if val := updated.Spec.Template.Annotations["other.example.com/unmanaged"]; val != "keep" {
+		t.Errorf("expected unmanaged annotation to be preserved, got %q", val)
+	}
+}
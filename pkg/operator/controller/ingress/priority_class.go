@@ -0,0 +1,97 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// RouterPriorityClassAnnotation can be set on an ingresscontroller to
+	// the name of the priority class to assign to its router pods instead
+	// of the default, "system-cluster-critical".  The priority class must
+	// exist; a nonexistent class is reported through the
+	// "PriorityClassValid" status condition, and the deployment keeps the
+	// default priority class so that the pods remain schedulable.
+	RouterPriorityClassAnnotation = "ingress.operator.openshift.io/priority-class"
+
+	// IngressControllerPriorityClassValidConditionType is the type of the
+	// status condition that reports whether any configured priority class
+	// exists.
+	IngressControllerPriorityClassValidConditionType = "PriorityClassValid"
+)
+
+// priorityClassFromAnnotation returns the priority class name configured on
+// the ingresscontroller, and a boolean indicating whether one is configured.
+func priorityClassFromAnnotation(ic *operatorv1.IngressController) (string, bool) {
+	val, ok := ic.Annotations[RouterPriorityClassAnnotation]
+	if !ok || len(val) == 0 {
+		return "", false
+	}
+	return val, true
+}
+
+// applyPriorityClass sets any configured and valid priority class on the
+// router deployment.  An invalid class leaves the default in place, so that
+// the pods remain schedulable; the "PriorityClassValid" status condition
+// reports the problem.
+func (r *reconciler) applyPriorityClass(ci *operatorv1.IngressController, deployment *appsv1.Deployment) {
+	name, ok := priorityClassFromAnnotation(ci)
+	if !ok {
+		return
+	}
+	if err := r.validatePriorityClass(name); err != nil {
+		log.Error(err, "not configuring priority class for ingresscontroller", "namespace", ci.Namespace, "name", ci.Name)
+		return
+	}
+	deployment.Spec.Template.Spec.PriorityClassName = name
+}
+
+// validatePriorityClass returns an error if the named priority class does not
+// exist.
+func (r *reconciler) validatePriorityClass(name string) error {
+	priorityClass := &schedulingv1.PriorityClass{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, priorityClass); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("priority class %q does not exist", name)
+		}
+		return fmt.Errorf("failed to get priority class %q: %v", name, err)
+	}
+	return nil
+}
+
+// computePriorityClassCondition computes the ingresscontroller's
+// "PriorityClassValid" status condition, which reports whether any configured
+// priority class exists.
+func (r *reconciler) computePriorityClassCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	name, ok := priorityClassFromAnnotation(ic)
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerPriorityClassValidConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NotConfigured",
+			Message: "No priority class is configured.",
+		}
+	}
+	if err := r.validatePriorityClass(name); err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerPriorityClassValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidPriorityClass",
+			Message: fmt.Sprintf("The configured priority class is not used: %v", err),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerPriorityClassValidConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Valid",
+		Message: fmt.Sprintf("The router pods use priority class %q.", name),
+	}
+}
@@ -0,0 +1,122 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyPriorityClass(t *testing.T) {
+	testCases := []struct {
+		description   string
+		annotation    string
+		priorityClass *schedulingv1.PriorityClass
+		expected      string
+	}{
+		{
+			description: "no annotation keeps the default",
+			expected:    "system-cluster-critical",
+		},
+		{
+			description: "existing priority class is applied",
+			annotation:  "ingress-critical",
+			priorityClass: &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "ingress-critical"},
+			},
+			expected: "ingress-critical",
+		},
+		{
+			description: "nonexistent priority class keeps the default",
+			annotation:  "no-such-class",
+			expected:    "system-cluster-critical",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.PrivateStrategyType,
+				},
+			},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{RouterPriorityClassAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.priorityClass != nil {
+			objs = append(objs, tc.priorityClass)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", &configv1.Ingress{}, &configv1.APIServer{}, &configv1.Network{}, false, "")
+		if err != nil {
+			t.Fatalf("%q: failed to build router deployment: %v", tc.description, err)
+		}
+		r.applyPriorityClass(ic, deployment)
+		if actual := deployment.Spec.Template.Spec.PriorityClassName; actual != tc.expected {
+			t.Errorf("%q: expected priority class %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestComputePriorityClassCondition(t *testing.T) {
+	testCases := []struct {
+		description    string
+		annotation     string
+		priorityClass  *schedulingv1.PriorityClass
+		expectedStatus operatorv1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			description:    "not configured",
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "NotConfigured",
+		},
+		{
+			description: "valid priority class",
+			annotation:  "ingress-critical",
+			priorityClass: &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "ingress-critical"},
+			},
+			expectedStatus: operatorv1.ConditionTrue,
+			expectedReason: "Valid",
+		},
+		{
+			description:    "nonexistent priority class",
+			annotation:     "no-such-class",
+			expectedStatus: operatorv1.ConditionFalse,
+			expectedReason: "InvalidPriorityClass",
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		}
+		if len(tc.annotation) != 0 {
+			ic.Annotations = map[string]string{RouterPriorityClassAnnotation: tc.annotation}
+		}
+		objs := []runtime.Object{}
+		if tc.priorityClass != nil {
+			objs = append(objs, tc.priorityClass)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objs...),
+		}
+		condition := r.computePriorityClassCondition(ic)
+		if condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+			t.Errorf("%q: expected status %q with reason %q, got %q with reason %q", tc.description, tc.expectedStatus, tc.expectedReason, condition.Status, condition.Reason)
+		}
+	}
+}
@@ -41,7 +41,7 @@ func (r *reconciler) ensureServiceCAConfigMap() (bool, *corev1.ConfigMap, error)
 		}
 		return false, nil, nil
 	case wantCM && !haveCM:
-		if err := r.client.Create(context.TODO(), desired); err != nil {
+		if err := r.createManagedObject(desired); err != nil {
 			return false, nil, fmt.Errorf("failed to create configmap: %v", err)
 		}
 		log.Info("created configmap", "configmap", desired)
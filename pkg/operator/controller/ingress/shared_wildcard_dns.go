@@ -0,0 +1,267 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	iov1 "github.com/openshift/api/operatoringress/v1"
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedWildcardDNSTargetAnnotation can be set on an ingresscontroller to the
+// hostname of a shared external load balancer.  When the annotation is set,
+// the operator publishes a single shared wildcard CNAME record pointing at the
+// given target instead of a per-controller record for the ingresscontroller's
+// load-balancer service.  The shared record is deduplicated across all
+// ingresscontrollers that opt in and is deleted only after the last opted-in
+// ingresscontroller is deleted or opts out.
+const SharedWildcardDNSTargetAnnotation = "ingress.operator.openshift.io/shared-wildcard-dns-target"
+
+// sharedWildcardDNSRecordOwnersAnnotation is set on the shared wildcard DNS
+// record to the comma-separated names of the ingresscontrollers that currently
+// publish through the record.  The record is deleted when the last owner is
+// removed.
+const sharedWildcardDNSRecordOwnersAnnotation = "ingress.operator.openshift.io/shared-wildcard-owners"
+
+// IngressControllerSharedWildcardDNSConditionType is the type of the status
+// condition that reports whether an ingresscontroller that has opted in to
+// the shared wildcard DNS record can actually publish through it.  The
+// condition is false when the existing shared record serves a different
+// domain than the ingresscontroller's.
+const IngressControllerSharedWildcardDNSConditionType = "SharedWildcardDNSRecordReady"
+
+// sharedWildcardDNSTarget returns the shared wildcard DNS target for the
+// ingresscontroller, and a boolean indicating whether the ingresscontroller
+// has opted in to the shared wildcard record.
+func sharedWildcardDNSTarget(ic *operatorv1.IngressController) (string, bool) {
+	target, ok := ic.Annotations[SharedWildcardDNSTargetAnnotation]
+	if !ok || len(target) == 0 {
+		return "", false
+	}
+	return target, true
+}
+
+// ensureSharedWildcardDNSRecord ensures that the shared wildcard DNS record
+// exists, points at the given target, and lists the given ingresscontroller as
+// an owner.
+func (r *reconciler) ensureSharedWildcardDNSRecord(ic *operatorv1.IngressController, target string) (bool, *iov1.DNSRecord, error) {
+	// If the ingresscontroller has no ingress domain, we cannot configure
+	// any DNS records.
+	if len(ic.Status.Domain) == 0 {
+		return false, nil, nil
+	}
+
+	haveRec, current, err := r.currentSharedWildcardDNSRecord()
+	if err != nil {
+		return false, nil, err
+	}
+	if !haveRec {
+		desired := desiredSharedWildcardDNSRecord(ic, target)
+		if err := r.client.Create(context.TODO(), desired); err != nil {
+			return false, nil, fmt.Errorf("failed to create shared wildcard dnsrecord %s/%s: %v", desired.Namespace, desired.Name, err)
+		}
+		log.Info("created shared wildcard dnsrecord", "dnsrecord", desired)
+		return r.currentSharedWildcardDNSRecord()
+	}
+	if current.Spec.DNSName != sharedWildcardDNSRecordDomain(ic) {
+		// The shared record serves a different domain, so listing this
+		// ingresscontroller as an owner would not publish any of its
+		// hostnames.  The caller is expected to check for the conflict
+		// before opting the ingresscontroller in; refuse here as well
+		// so the record's owners never disagree about its domain.
+		return true, current, fmt.Errorf("shared wildcard dnsrecord %s/%s has DNS name %q, which does not cover domain %q", current.Namespace, current.Name, current.Spec.DNSName, ic.Status.Domain)
+	}
+
+	updated := current.DeepCopy()
+	changed := false
+	owners := sharedWildcardDNSRecordOwners(updated)
+	if !containsString(owners, ic.Name) {
+		owners = append(owners, ic.Name)
+		sort.Strings(owners)
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[sharedWildcardDNSRecordOwnersAnnotation] = strings.Join(owners, ",")
+		changed = true
+	}
+	if len(updated.Spec.Targets) != 1 || updated.Spec.Targets[0] != target {
+		updated.Spec.Targets = []string{target}
+		changed = true
+	}
+	if changed {
+		if err := r.client.Update(context.TODO(), updated); err != nil {
+			return true, current, fmt.Errorf("failed to update shared wildcard dnsrecord %s/%s: %v", updated.Namespace, updated.Name, err)
+		}
+		log.Info("updated shared wildcard dnsrecord", "namespace", updated.Namespace, "name", updated.Name, "owners", owners)
+		return r.currentSharedWildcardDNSRecord()
+	}
+	return true, current, nil
+}
+
+// desiredSharedWildcardDNSRecord returns the shared wildcard DNS record as it
+// should be created for its first owner.  The record deliberately has no
+// controller owner reference: it is owned jointly by all opted-in
+// ingresscontrollers, so deleting any single one must not garbage-collect the
+// record.
+func desiredSharedWildcardDNSRecord(ic *operatorv1.IngressController, target string) *iov1.DNSRecord {
+	name := controller.SharedWildcardDNSRecordName()
+	domain := sharedWildcardDNSRecordDomain(ic)
+	return &iov1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Annotations: map[string]string{
+				sharedWildcardDNSRecordOwnersAnnotation: ic.Name,
+			},
+			Finalizers: []string{manifests.DNSRecordFinalizer},
+		},
+		Spec: iov1.DNSRecordSpec{
+			DNSName:    domain,
+			Targets:    []string{target},
+			RecordType: iov1.CNAMERecordType,
+			RecordTTL:  defaultRecordTTL,
+		},
+	}
+}
+
+// sharedWildcardDNSRecordDomain returns the wildcard DNS name that the
+// shared wildcard DNS record must have in order to cover the
+// ingresscontroller's domain.  The name is absolute to prevent any
+// ambiguity.
+func sharedWildcardDNSRecordDomain(ic *operatorv1.IngressController) string {
+	return fmt.Sprintf("*.%s.", ic.Status.Domain)
+}
+
+// sharedWildcardDNSRecordConflict returns whether an existing shared
+// wildcard DNS record serves a different domain than the ingresscontroller,
+// along with the existing record's DNS name.  An opted-in ingresscontroller
+// whose domain conflicts must keep its per-controller wildcard record;
+// publishing through the shared record would cover none of its hostnames.
+func (r *reconciler) sharedWildcardDNSRecordConflict(ic *operatorv1.IngressController) (bool, string, error) {
+	haveRec, current, err := r.currentSharedWildcardDNSRecord()
+	if err != nil {
+		return false, "", err
+	}
+	if !haveRec {
+		return false, "", nil
+	}
+	if current.Spec.DNSName != sharedWildcardDNSRecordDomain(ic) {
+		return true, current.Spec.DNSName, nil
+	}
+	return false, current.Spec.DNSName, nil
+}
+
+// computeSharedWildcardDNSCondition computes the ingresscontroller's
+// "SharedWildcardDNSRecordReady" status condition, which reports whether an
+// ingresscontroller that has opted in to the shared wildcard DNS record can
+// publish through it.
+func (r *reconciler) computeSharedWildcardDNSCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	if _, ok := sharedWildcardDNSTarget(ic); !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerSharedWildcardDNSConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NotConfigured",
+			Message: "This ingresscontroller does not publish through the shared wildcard DNS record.",
+		}
+	}
+	conflict, existingDNSName, err := r.sharedWildcardDNSRecordConflict(ic)
+	if err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerSharedWildcardDNSConditionType,
+			Status:  operatorv1.ConditionUnknown,
+			Reason:  "FailedGet",
+			Message: fmt.Sprintf("Failed to get the shared wildcard DNS record: %v", err),
+		}
+	}
+	if conflict {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerSharedWildcardDNSConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "DomainConflict",
+			Message: fmt.Sprintf("The shared wildcard DNS record has DNS name %q, which does not cover domain %q; the per-controller wildcard DNS record is kept.", existingDNSName, ic.Status.Domain),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerSharedWildcardDNSConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Ready",
+		Message: "The shared wildcard DNS record covers this ingresscontroller's domain.",
+	}
+}
+
+func (r *reconciler) currentSharedWildcardDNSRecord() (bool, *iov1.DNSRecord, error) {
+	current := &iov1.DNSRecord{}
+	err := r.client.Get(context.TODO(), controller.SharedWildcardDNSRecordName(), current)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, current, nil
+}
+
+// releaseSharedWildcardDNSRecord removes the ingresscontroller from the shared
+// wildcard DNS record's owners and deletes the record if no owners remain.
+func (r *reconciler) releaseSharedWildcardDNSRecord(ic *operatorv1.IngressController) error {
+	haveRec, current, err := r.currentSharedWildcardDNSRecord()
+	if err != nil {
+		return err
+	}
+	if !haveRec {
+		return nil
+	}
+	owners := sharedWildcardDNSRecordOwners(current)
+	remaining := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		if owner != ic.Name {
+			remaining = append(remaining, owner)
+		}
+	}
+	if len(remaining) == len(owners) {
+		return nil
+	}
+	if len(remaining) == 0 {
+		if err := r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete shared wildcard dnsrecord %s/%s: %v", current.Namespace, current.Name, err)
+		}
+		log.Info("deleted shared wildcard dnsrecord", "namespace", current.Namespace, "name", current.Name)
+		return nil
+	}
+	updated := current.DeepCopy()
+	updated.Annotations[sharedWildcardDNSRecordOwnersAnnotation] = strings.Join(remaining, ",")
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update shared wildcard dnsrecord %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("removed owner from shared wildcard dnsrecord", "namespace", updated.Namespace, "name", updated.Name, "owner", ic.Name, "remaining owners", remaining)
+	return nil
+}
+
+// sharedWildcardDNSRecordOwners returns the names of the ingresscontrollers
+// that currently publish through the shared wildcard DNS record.
+func sharedWildcardDNSRecordOwners(record *iov1.DNSRecord) []string {
+	var owners []string
+	for _, owner := range strings.Split(record.Annotations[sharedWildcardDNSRecordOwnersAnnotation], ",") {
+		if len(owner) != 0 {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package ingress
+
+import (
+	"reflect"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestSharedWildcardDNSRecordReferenceCounting verifies that the shared
+// wildcard DNS record accumulates owners as ingresscontrollers opt in and is
+// deleted only after the last owner is released.
+func TestSharedWildcardDNSRecordReferenceCounting(t *testing.T) {
+	ic := func(name string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: controller.DefaultOperatorNamespace,
+				Name:      name,
+				Annotations: map[string]string{
+					SharedWildcardDNSTargetAnnotation: "lb.external.example.com",
+				},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: "apps.openshift.example.com",
+			},
+		}
+	}
+	alpha, beta := ic("alpha"), ic("beta")
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme())
+	r := &reconciler{client: client}
+
+	// The first opt-in creates the shared record with one owner.
+	haveRec, record, err := r.ensureSharedWildcardDNSRecord(alpha, "lb.external.example.com")
+	if err != nil {
+		t.Fatalf("failed to ensure shared wildcard dnsrecord for alpha: %v", err)
+	}
+	if !haveRec {
+		t.Fatal("expected shared wildcard dnsrecord to exist after first opt-in")
+	}
+	if expected := []string{"alpha"}; !reflect.DeepEqual(sharedWildcardDNSRecordOwners(record), expected) {
+		t.Errorf("expected owners %v, got %v", expected, sharedWildcardDNSRecordOwners(record))
+	}
+	if expected := "*.apps.openshift.example.com."; record.Spec.DNSName != expected {
+		t.Errorf("expected DNS name %q, got %q", expected, record.Spec.DNSName)
+	}
+
+	// A second opt-in is deduplicated onto the same record.
+	if _, record, err = r.ensureSharedWildcardDNSRecord(beta, "lb.external.example.com"); err != nil {
+		t.Fatalf("failed to ensure shared wildcard dnsrecord for beta: %v", err)
+	}
+	if expected := []string{"alpha", "beta"}; !reflect.DeepEqual(sharedWildcardDNSRecordOwners(record), expected) {
+		t.Errorf("expected owners %v, got %v", expected, sharedWildcardDNSRecordOwners(record))
+	}
+
+	// Ensuring again for an existing owner changes nothing.
+	if _, record, err = r.ensureSharedWildcardDNSRecord(alpha, "lb.external.example.com"); err != nil {
+		t.Fatalf("failed to re-ensure shared wildcard dnsrecord for alpha: %v", err)
+	}
+	if expected := []string{"alpha", "beta"}; !reflect.DeepEqual(sharedWildcardDNSRecordOwners(record), expected) {
+		t.Errorf("expected owners %v, got %v", expected, sharedWildcardDNSRecordOwners(record))
+	}
+
+	// Releasing one owner must not delete the record while another owner
+	// remains.
+	if err := r.releaseSharedWildcardDNSRecord(alpha); err != nil {
+		t.Fatalf("failed to release shared wildcard dnsrecord for alpha: %v", err)
+	}
+	haveRec, record, err = r.currentSharedWildcardDNSRecord()
+	if err != nil {
+		t.Fatalf("failed to get shared wildcard dnsrecord: %v", err)
+	}
+	if !haveRec {
+		t.Fatal("expected shared wildcard dnsrecord to exist while an owner remains")
+	}
+	if expected := []string{"beta"}; !reflect.DeepEqual(sharedWildcardDNSRecordOwners(record), expected) {
+		t.Errorf("expected owners %v, got %v", expected, sharedWildcardDNSRecordOwners(record))
+	}
+
+	// Releasing the last owner deletes the record.
+	if err := r.releaseSharedWildcardDNSRecord(beta); err != nil {
+		t.Fatalf("failed to release shared wildcard dnsrecord for beta: %v", err)
+	}
+	if haveRec, _, err = r.currentSharedWildcardDNSRecord(); err != nil {
+		t.Fatalf("failed to get shared wildcard dnsrecord: %v", err)
+	} else if haveRec {
+		t.Error("expected shared wildcard dnsrecord to be deleted after last owner released")
+	}
+
+	// Releasing an owner that is not listed is a no-op.
+	if err := r.releaseSharedWildcardDNSRecord(alpha); err != nil {
+		t.Fatalf("expected releasing a non-owner to be a no-op, got error: %v", err)
+	}
+}
+
+// TestSharedWildcardDNSRecordDomainConflict verifies that an
+// ingresscontroller whose domain does not match the existing shared wildcard
+// DNS record's DNS name is refused rather than listed as an owner, and that
+// the conflict is reported through the SharedWildcardDNSRecordReady status
+// condition.
+func TestSharedWildcardDNSRecordDomainConflict(t *testing.T) {
+	ic := func(name, domain string) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: controller.DefaultOperatorNamespace,
+				Name:      name,
+				Annotations: map[string]string{
+					SharedWildcardDNSTargetAnnotation: "lb.external.example.com",
+				},
+			},
+			Status: operatorv1.IngressControllerStatus{
+				Domain: domain,
+			},
+		}
+	}
+	alpha := ic("alpha", "apps.openshift.example.com")
+	beta := ic("beta", "shard.openshift.example.com")
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme())
+	r := &reconciler{client: client}
+
+	if _, _, err := r.ensureSharedWildcardDNSRecord(alpha, "lb.external.example.com"); err != nil {
+		t.Fatalf("failed to ensure shared wildcard dnsrecord for alpha: %v", err)
+	}
+
+	// An ingresscontroller with a different domain conflicts with the
+	// existing record and is not added as an owner.
+	if conflict, existingDNSName, err := r.sharedWildcardDNSRecordConflict(beta); err != nil {
+		t.Fatalf("failed to check shared wildcard dnsrecord for beta: %v", err)
+	} else if !conflict {
+		t.Error("expected a conflict for beta")
+	} else if expected := "*.apps.openshift.example.com."; existingDNSName != expected {
+		t.Errorf("expected existing DNS name %q, got %q", expected, existingDNSName)
+	}
+	if _, _, err := r.ensureSharedWildcardDNSRecord(beta, "lb.external.example.com"); err == nil {
+		t.Error("expected ensuring the shared wildcard dnsrecord for beta to fail")
+	}
+	_, record, err := r.currentSharedWildcardDNSRecord()
+	if err != nil {
+		t.Fatalf("failed to get shared wildcard dnsrecord: %v", err)
+	}
+	if expected := []string{"alpha"}; !reflect.DeepEqual(sharedWildcardDNSRecordOwners(record), expected) {
+		t.Errorf("expected owners %v, got %v", expected, sharedWildcardDNSRecordOwners(record))
+	}
+	if expected := "*.apps.openshift.example.com."; record.Spec.DNSName != expected {
+		t.Errorf("expected DNS name %q, got %q", expected, record.Spec.DNSName)
+	}
+
+	// The status condition reports the conflict for beta and readiness for
+	// alpha, and is not configured for an opted-out ingresscontroller.
+	if cond := r.computeSharedWildcardDNSCondition(beta); cond.Status != operatorv1.ConditionFalse || cond.Reason != "DomainConflict" {
+		t.Errorf("expected condition False/DomainConflict for beta, got %s/%s", cond.Status, cond.Reason)
+	}
+	if cond := r.computeSharedWildcardDNSCondition(alpha); cond.Status != operatorv1.ConditionTrue || cond.Reason != "Ready" {
+		t.Errorf("expected condition True/Ready for alpha, got %s/%s", cond.Status, cond.Reason)
+	}
+	gamma := ic("gamma", "other.openshift.example.com")
+	delete(gamma.Annotations, SharedWildcardDNSTargetAnnotation)
+	if cond := r.computeSharedWildcardDNSCondition(gamma); cond.Status != operatorv1.ConditionTrue || cond.Reason != "NotConfigured" {
+		t.Errorf("expected condition True/NotConfigured for gamma, got %s/%s", cond.Status, cond.Reason)
+	}
+}
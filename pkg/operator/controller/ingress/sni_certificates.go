@@ -0,0 +1,116 @@
+package ingress
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SNICertificatesAnnotation can be set on an ingresscontroller to a JSON
+// object mapping hostnames to secret names.  Each referenced secret must be a
+// kubernetes.io/tls secret in the operand namespace, and its certificate must
+// be valid for the hostname it is mapped to.  The operator mounts the
+// referenced secrets into the router deployment and wires them into the
+// router's SNI configuration, so the router serves each certificate for its
+// hostname.  Because the secrets are mounted into the router pods, rotating a
+// referenced secret updates the mounted content, which the router's
+// certificate watcher picks up and reloads.
+const SNICertificatesAnnotation = "ingress.operator.openshift.io/sni-certificates"
+
+// sniCertificatesVolumeMountPath is the directory under which SNI certificate
+// secrets are mounted in the router container, one subdirectory per hostname.
+const sniCertificatesVolumeMountPath = "/etc/pki/tls/sni-certificates"
+
+// RouterSNICertificateDirsEnvName is the router environment variable that
+// lists the directories containing SNI certificates.
+const RouterSNICertificateDirsEnvName = "ROUTER_SNI_CERTIFICATE_DIRS"
+
+// sniCertificate associates a hostname with the secret holding the
+// certificate to serve for that hostname.
+type sniCertificate struct {
+	Host       string
+	SecretName string
+}
+
+// sniCertificates parses the SNI certificates annotation on the given
+// ingresscontroller and returns the certificates sorted by hostname.  A
+// certificate that references the ingresscontroller's effective default
+// certificate secret is rejected because the default certificate is already
+// served for hosts that no SNI certificate matches.
+func sniCertificates(ci *operatorv1.IngressController, defaultCertificateSecretName string) ([]sniCertificate, error) {
+	val, ok := ci.Annotations[SNICertificatesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal([]byte(val), &mapping); err != nil {
+		return nil, fmt.Errorf("invalid value for annotation %s: %v", SNICertificatesAnnotation, err)
+	}
+	certs := make([]sniCertificate, 0, len(mapping))
+	for host, secretName := range mapping {
+		if len(host) == 0 || len(secretName) == 0 {
+			return nil, fmt.Errorf("invalid value for annotation %s: hostname and secret name must be nonempty", SNICertificatesAnnotation)
+		}
+		if secretName == defaultCertificateSecretName {
+			return nil, fmt.Errorf("invalid value for annotation %s: secret %q for host %q duplicates the default certificate secret", SNICertificatesAnnotation, secretName, host)
+		}
+		certs = append(certs, sniCertificate{Host: host, SecretName: secretName})
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].Host < certs[j].Host
+	})
+	return certs, nil
+}
+
+// sniCertificateVolumeName returns the name of the volume for the SNI
+// certificate with the given index.
+func sniCertificateVolumeName(i int) string {
+	return fmt.Sprintf("sni-certificate-%d", i)
+}
+
+// sniCertificateMountPath returns the path at which the SNI certificate for
+// the given host is mounted in the router container.
+func sniCertificateMountPath(host string) string {
+	return fmt.Sprintf("%s/%s", sniCertificatesVolumeMountPath, host)
+}
+
+// validateSNICertificates verifies that each SNI certificate secret exists and
+// that its certificate is valid for the hostname it is mapped to.
+func (r *reconciler) validateSNICertificates(ci *operatorv1.IngressController, certs []sniCertificate) error {
+	for _, cert := range certs {
+		secret := &corev1.Secret{}
+		name := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: cert.SecretName}
+		if err := r.client.Get(context.TODO(), name, secret); err != nil {
+			return fmt.Errorf("failed to get SNI certificate secret %s for host %q: %v", name, cert.Host, err)
+		}
+		if err := certificateCoversHost(secret.Data["tls.crt"], cert.Host); err != nil {
+			return fmt.Errorf("SNI certificate secret %s does not cover host %q: %v", name, cert.Host, err)
+		}
+	}
+	return nil
+}
+
+// certificateCoversHost verifies that the leaf certificate in the given
+// PEM-encoded certificate data is valid for the given hostname.
+func certificateCoversHost(pemData []byte, host string) error {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return fmt.Errorf("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate: %v", err)
+	}
+	return cert.VerifyHostname(host)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -31,6 +32,61 @@ import (
 // clock is to enable unit testing
 var clock utilclock.Clock = utilclock.RealClock{}
 
+const (
+	// RolloutStatusPollIntervalAnnotation can be set on an
+	// ingresscontroller to a duration value to override how often the
+	// operator rechecks the ingresscontroller's status conditions while the
+	// router deployment's rollout has not completed.
+	RolloutStatusPollIntervalAnnotation = "ingress.operator.openshift.io/rollout-status-poll-interval"
+
+	// StuckRolloutTimeoutAnnotation can be set on an ingresscontroller to a
+	// duration value to override how long a router deployment rollout may
+	// leave some replicas unavailable before the operator reports the
+	// rollout as stuck by marking the ingresscontroller degraded.
+	StuckRolloutTimeoutAnnotation = "ingress.operator.openshift.io/stuck-rollout-timeout"
+
+	// defaultRolloutStatusPollInterval is how often status conditions are
+	// rechecked while some condition indicates a degraded state.
+	defaultRolloutStatusPollInterval = time.Minute
+
+	// defaultStuckRolloutTimeout is how long not all of a deployment's
+	// replicas may be available before the rollout is considered stuck.
+	defaultStuckRolloutTimeout = time.Minute * 60
+)
+
+// rolloutRetryPolicy controls how eagerly the operator rechecks a router
+// deployment's rollout status and how long a rollout may take before it is
+// reported as stuck.
+type rolloutRetryPolicy struct {
+	pollInterval        time.Duration
+	stuckRolloutTimeout time.Duration
+}
+
+// rolloutRetryPolicyFor returns the rollout retry policy for the given
+// ingresscontroller, applying any overrides from the ingresscontroller's
+// annotations.  Invalid or nonpositive overrides are logged and ignored.
+func rolloutRetryPolicyFor(ic *operatorv1.IngressController) rolloutRetryPolicy {
+	policy := rolloutRetryPolicy{
+		pollInterval:        defaultRolloutStatusPollInterval,
+		stuckRolloutTimeout: defaultStuckRolloutTimeout,
+	}
+	if val, ok := ic.Annotations[RolloutStatusPollIntervalAnnotation]; ok {
+		if d, err := time.ParseDuration(val); err != nil || d <= 0 {
+			log.Info("ignoring invalid annotation value", "annotation", RolloutStatusPollIntervalAnnotation, "value", val)
+		} else {
+			policy.pollInterval = d
+		}
+	}
+	if val, ok := ic.Annotations[StuckRolloutTimeoutAnnotation]; ok {
+		if d, err := time.ParseDuration(val); err != nil || d <= 0 {
+			log.Info("ignoring invalid annotation value", "annotation", StuckRolloutTimeoutAnnotation, "value", val)
+		} else {
+			policy.stuckRolloutTimeout = d
+		}
+	}
+	return policy
+}
+
 // expectedCondition contains a condition that is expected to be checked when
 // determining Available or Degraded status of the ingress controller
 type expectedCondition struct {
@@ -62,8 +118,17 @@ func (r *reconciler) syncIngressControllerStatus(ic *operatorv1.IngressControlle
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDeploymentReplicasAllAvailableCondition(deployment))
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeLoadBalancerStatus(ic, service, operandEvents)...)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDNSStatus(ic, wildcardRecord, dnsConfig)...)
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, r.computeTLSSessionTicketKeysCondition(ic))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, r.computeSharedWildcardDNSCondition(ic))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, r.computeMinimumTLSVersionCondition(ic))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, r.computePriorityClassCondition(ic))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, r.computeConfigTemplateCondition(ic))
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeDefaultCertificateManagedCondition(ic))
+	rolloutPendingCondition, rolloutPendingErr := r.computeDeploymentRolloutPendingCondition(ic)
+	errs = append(errs, rolloutPendingErr)
+	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, rolloutPendingCondition)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, computeIngressAvailableCondition(updated.Status.Conditions))
-	degradedCondition, err := computeIngressDegradedCondition(updated.Status.Conditions, updated.Name)
+	degradedCondition, err := computeIngressDegradedCondition(updated.Status.Conditions, updated.Name, rolloutRetryPolicyFor(ic))
 	errs = append(errs, err)
 	updated.Status.Conditions = MergeConditions(updated.Status.Conditions, degradedCondition)
 	updated.Status.Conditions = PruneConditions(updated.Status.Conditions)
@@ -429,7 +494,7 @@ func computeDeploymentReplicasAllAvailableCondition(deployment *appsv1.Deploymen
 // duration value that indicates, if it is non-zero, that the operator should
 // reconcile the ingresscontroller again after that period to update its status
 // conditions.
-func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition, icName string) (operatorv1.OperatorCondition, error) {
+func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition, icName string, retryPolicy rolloutRetryPolicy) (operatorv1.OperatorCondition, error) {
 	expectedConditions := []expectedCondition{
 		{
 			condition: IngressControllerAdmittedConditionType,
@@ -453,7 +518,7 @@ func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition,
 		{
 			condition:   IngressControllerDeploymentReplicasAllAvailableConditionType,
 			status:      operatorv1.ConditionTrue,
-			gracePeriod: time.Minute * 60,
+			gracePeriod: retryPolicy.stuckRolloutTimeout,
 		},
 		{
 			condition:        operatorv1.LoadBalancerReadyIngressConditionType,
@@ -496,8 +561,8 @@ func computeIngressDegradedCondition(conditions []operatorv1.OperatorCondition,
 	}
 	graceConditions, degradedConditions, requeueAfter := checkConditions(expectedConditions, conditions)
 	if len(degradedConditions) != 0 {
-		// Keep checking conditions every minute while degraded.
-		retryAfter := time.Minute
+		// Keep checking conditions at the poll interval while degraded.
+		retryAfter := retryPolicy.pollInterval
 
 		degraded := formatConditions(degradedConditions)
 		condition := operatorv1.OperatorCondition{
@@ -595,6 +660,8 @@ func computeLoadBalancerStatus(ic *operatorv1.IngressController, service *corev1
 		Message: "The endpoint publishing strategy supports a managed load balancer",
 	})
 
+	conditions = append(conditions, computeLoadBalancerProviderParametersCondition(ic))
+
 	switch {
 	case service == nil:
 		conditions = append(conditions, operatorv1.OperatorCondition{
@@ -637,6 +704,51 @@ func computeLoadBalancerStatus(ic *operatorv1.IngressController, service *corev1
 	return conditions
 }
 
+// computeLoadBalancerProviderParametersCondition checks the load balancer
+// provider parameters against the configured provider and load balancer type
+// and reports any parameters that the configuration silently ignores, so that
+// a user who sets a parameter that is unsupported by the current load
+// balancer type can see why it has no effect.
+func computeLoadBalancerProviderParametersCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	var ignored []string
+	lb := ic.Status.EndpointPublishingStrategy.LoadBalancer
+	if lb != nil && lb.ProviderParameters != nil {
+		pp := lb.ProviderParameters
+		if pp.Type != operatorv1.AWSLoadBalancerProvider && pp.AWS != nil {
+			ignored = append(ignored, "providerParameters.aws")
+		}
+		if pp.Type != operatorv1.GCPLoadBalancerProvider && pp.GCP != nil {
+			ignored = append(ignored, "providerParameters.gcp")
+		}
+		if pp.Type == operatorv1.AWSLoadBalancerProvider && pp.AWS != nil {
+			switch pp.AWS.Type {
+			case operatorv1.AWSClassicLoadBalancer:
+				if pp.AWS.NetworkLoadBalancerParameters != nil {
+					ignored = append(ignored, "providerParameters.aws.networkLoadBalancer")
+				}
+			case operatorv1.AWSNetworkLoadBalancer:
+				if pp.AWS.ClassicLoadBalancerParameters != nil {
+					ignored = append(ignored, "providerParameters.aws.classicLoadBalancer")
+				}
+			}
+		}
+	}
+	if len(ignored) != 0 {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerLoadBalancerProviderParametersSupportedConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "UnsupportedParameters",
+			Message: fmt.Sprintf("The following load balancer parameters are not supported by the configured load balancer type and are ignored: %s", strings.Join(ignored, ", ")),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerLoadBalancerProviderParametersSupportedConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Supported",
+		Message: "All configured load balancer parameters are supported",
+	}
+}
+
 func isProvisioned(service *corev1.Service) bool {
 	ingresses := service.Status.LoadBalancer.Ingress
 	return len(ingresses) > 0 && (len(ingresses[0].Hostname) > 0 || len(ingresses[0].IP) > 0)
@@ -733,3 +845,25 @@ func computeDNSStatus(ic *operatorv1.IngressController, wildcardRecord *iov1.DNS
 
 	return conditions
 }
+
+// computeDefaultCertificateManagedCondition returns a condition reporting
+// whether the operator manages the ingresscontroller's default certificate.
+// The condition is False when management is disabled with the
+// UnmanagedDefaultCertificateAnnotation, in which case the serving
+// certificate must be provisioned out-of-band.
+func computeDefaultCertificateManagedCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	if DefaultCertificateIsUnmanaged(ic) {
+		return operatorv1.OperatorCondition{
+			Type:    "DefaultCertificateManaged",
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "UnmanagedByAnnotation",
+			Message: "Operator management of the default certificate is disabled; the serving certificate must be provisioned out-of-band.",
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    "DefaultCertificateManaged",
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Managed",
+		Message: "The operator manages the default certificate.",
+	}
+}
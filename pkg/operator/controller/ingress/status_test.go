@@ -1,6 +1,7 @@
 package ingress
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -466,7 +467,7 @@ func TestComputeIngressDegradedCondition(t *testing.T) {
 		},
 	}
 	for _, test := range tests {
-		actual, err := computeIngressDegradedCondition(test.conditions, test.icName)
+		actual, err := computeIngressDegradedCondition(test.conditions, test.icName, rolloutRetryPolicyFor(&operatorv1.IngressController{}))
 		switch e := err.(type) {
 		case retryable.Error:
 			if !test.expectRequeue {
@@ -814,6 +815,7 @@ func TestComputeLoadBalancerStatus(t *testing.T) {
 			service:    provisionedLBservice("default"),
 			expect: []operatorv1.OperatorCondition{
 				cond(operatorv1.LoadBalancerManagedIngressConditionType, operatorv1.ConditionTrue, "WantedByEndpointPublishingStrategy", clock.Now()),
+				cond(IngressControllerLoadBalancerProviderParametersSupportedConditionType, operatorv1.ConditionTrue, "Supported", clock.Now()),
 				cond(operatorv1.LoadBalancerReadyIngressConditionType, operatorv1.ConditionTrue, "LoadBalancerProvisioned", clock.Now()),
 			},
 		},
@@ -828,6 +830,7 @@ func TestComputeLoadBalancerStatus(t *testing.T) {
 			},
 			expect: []operatorv1.OperatorCondition{
 				cond(operatorv1.LoadBalancerManagedIngressConditionType, operatorv1.ConditionTrue, "WantedByEndpointPublishingStrategy", clock.Now()),
+				cond(IngressControllerLoadBalancerProviderParametersSupportedConditionType, operatorv1.ConditionTrue, "Supported", clock.Now()),
 				cond(operatorv1.LoadBalancerReadyIngressConditionType, operatorv1.ConditionFalse, "LoadBalancerPending", clock.Now()),
 			},
 		},
@@ -842,6 +845,7 @@ func TestComputeLoadBalancerStatus(t *testing.T) {
 			},
 			expect: []operatorv1.OperatorCondition{
 				cond(operatorv1.LoadBalancerManagedIngressConditionType, operatorv1.ConditionTrue, "WantedByEndpointPublishingStrategy", clock.Now()),
+				cond(IngressControllerLoadBalancerProviderParametersSupportedConditionType, operatorv1.ConditionTrue, "Supported", clock.Now()),
 				cond(operatorv1.LoadBalancerReadyIngressConditionType, operatorv1.ConditionFalse, "SyncLoadBalancerFailed", clock.Now()),
 			},
 		},
@@ -857,6 +861,7 @@ func TestComputeLoadBalancerStatus(t *testing.T) {
 			controller: ingressController("default", operatorv1.LoadBalancerServiceStrategyType),
 			expect: []operatorv1.OperatorCondition{
 				cond(operatorv1.LoadBalancerManagedIngressConditionType, operatorv1.ConditionTrue, "WantedByEndpointPublishingStrategy", clock.Now()),
+				cond(IngressControllerLoadBalancerProviderParametersSupportedConditionType, operatorv1.ConditionTrue, "Supported", clock.Now()),
 				cond(operatorv1.LoadBalancerReadyIngressConditionType, operatorv1.ConditionFalse, "ServiceNotFound", clock.Now()),
 			},
 		},
@@ -1127,3 +1132,168 @@ func TestMergeConditions(t *testing.T) {
 		}
 	}
 }
+
+func TestComputeLoadBalancerProviderParametersCondition(t *testing.T) {
+	testCases := []struct {
+		description        string
+		providerParameters *operatorv1.ProviderLoadBalancerParameters
+		expectStatus       operatorv1.ConditionStatus
+		expectInMessage    string
+	}{
+		{
+			description:  "no provider parameters",
+			expectStatus: operatorv1.ConditionTrue,
+		},
+		{
+			description: "NLB parameters on a classic ELB",
+			providerParameters: &operatorv1.ProviderLoadBalancerParameters{
+				Type: operatorv1.AWSLoadBalancerProvider,
+				AWS: &operatorv1.AWSLoadBalancerParameters{
+					Type:                          operatorv1.AWSClassicLoadBalancer,
+					NetworkLoadBalancerParameters: &operatorv1.AWSNetworkLoadBalancerParameters{},
+				},
+			},
+			expectStatus:    operatorv1.ConditionFalse,
+			expectInMessage: "providerParameters.aws.networkLoadBalancer",
+		},
+		{
+			description: "classic ELB parameters on an NLB",
+			providerParameters: &operatorv1.ProviderLoadBalancerParameters{
+				Type: operatorv1.AWSLoadBalancerProvider,
+				AWS: &operatorv1.AWSLoadBalancerParameters{
+					Type:                          operatorv1.AWSNetworkLoadBalancer,
+					ClassicLoadBalancerParameters: &operatorv1.AWSClassicLoadBalancerParameters{},
+				},
+			},
+			expectStatus:    operatorv1.ConditionFalse,
+			expectInMessage: "providerParameters.aws.classicLoadBalancer",
+		},
+		{
+			description: "GCP parameters with the AWS provider",
+			providerParameters: &operatorv1.ProviderLoadBalancerParameters{
+				Type: operatorv1.AWSLoadBalancerProvider,
+				GCP:  &operatorv1.GCPLoadBalancerParameters{},
+			},
+			expectStatus:    operatorv1.ConditionFalse,
+			expectInMessage: "providerParameters.gcp",
+		},
+		{
+			description: "matching NLB parameters",
+			providerParameters: &operatorv1.ProviderLoadBalancerParameters{
+				Type: operatorv1.AWSLoadBalancerProvider,
+				AWS: &operatorv1.AWSLoadBalancerParameters{
+					Type:                          operatorv1.AWSNetworkLoadBalancer,
+					NetworkLoadBalancerParameters: &operatorv1.AWSNetworkLoadBalancerParameters{},
+				},
+			},
+			expectStatus: operatorv1.ConditionTrue,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					Type: operatorv1.LoadBalancerServiceStrategyType,
+					LoadBalancer: &operatorv1.LoadBalancerStrategy{
+						ProviderParameters: tc.providerParameters,
+					},
+				},
+			},
+		}
+		condition := computeLoadBalancerProviderParametersCondition(ic)
+		if condition.Status != tc.expectStatus {
+			t.Errorf("%q: expected status %s, got %s", tc.description, tc.expectStatus, condition.Status)
+			continue
+		}
+		if len(tc.expectInMessage) != 0 && !strings.Contains(condition.Message, tc.expectInMessage) {
+			t.Errorf("%q: expected message to mention %q, got: %s", tc.description, tc.expectInMessage, condition.Message)
+		}
+	}
+}
+
+func TestRolloutRetryPolicyFor(t *testing.T) {
+	testCases := []struct {
+		description   string
+		annotations   map[string]string
+		expectedPoll  time.Duration
+		expectedStuck time.Duration
+	}{
+		{
+			description:   "no annotations uses defaults",
+			expectedPoll:  defaultRolloutStatusPollInterval,
+			expectedStuck: defaultStuckRolloutTimeout,
+		},
+		{
+			description: "valid overrides",
+			annotations: map[string]string{
+				RolloutStatusPollIntervalAnnotation: "2m",
+				StuckRolloutTimeoutAnnotation:       "30m",
+			},
+			expectedPoll:  2 * time.Minute,
+			expectedStuck: 30 * time.Minute,
+		},
+		{
+			description: "invalid overrides are ignored",
+			annotations: map[string]string{
+				RolloutStatusPollIntervalAnnotation: "soon",
+				StuckRolloutTimeoutAnnotation:       "-5m",
+			},
+			expectedPoll:  defaultRolloutStatusPollInterval,
+			expectedStuck: defaultStuckRolloutTimeout,
+		},
+	}
+	for _, tc := range testCases {
+		ic := &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+		}
+		policy := rolloutRetryPolicyFor(ic)
+		if policy.pollInterval != tc.expectedPoll {
+			t.Errorf("%q: expected poll interval %s, got %s", tc.description, tc.expectedPoll, policy.pollInterval)
+		}
+		if policy.stuckRolloutTimeout != tc.expectedStuck {
+			t.Errorf("%q: expected stuck-rollout timeout %s, got %s", tc.description, tc.expectedStuck, policy.stuckRolloutTimeout)
+		}
+	}
+}
+
+// TestRolloutWithinTimeoutIsNotStuck verifies that a rollout that completes
+// within the stuck-rollout timeout never causes the ingresscontroller to be
+// reported degraded for incomplete replica availability.
+func TestRolloutWithinTimeoutIsNotStuck(t *testing.T) {
+	policy := rolloutRetryPolicy{
+		pollInterval:        time.Minute,
+		stuckRolloutTimeout: 30 * time.Minute,
+	}
+	// While the rollout is in progress but within the timeout, the
+	// ingresscontroller must not be degraded.
+	inProgress := []operatorv1.OperatorCondition{
+		cond(IngressControllerDeploymentReplicasAllAvailableConditionType, operatorv1.ConditionFalse, "", clock.Now().Add(-20*time.Minute)),
+	}
+	condition, err := computeIngressDegradedCondition(inProgress, "default", policy)
+	if condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected Degraded=False during in-progress rollout, got %s", condition.Status)
+	}
+	if _, ok := err.(retryable.Error); !ok {
+		t.Errorf("expected a retryable error during in-progress rollout, got %v", err)
+	}
+	// Once the rollout completes, the ingresscontroller must be steady:
+	// not degraded and with no requeue.
+	finished := []operatorv1.OperatorCondition{
+		cond(IngressControllerDeploymentReplicasAllAvailableConditionType, operatorv1.ConditionTrue, "", clock.Now()),
+	}
+	condition, err = computeIngressDegradedCondition(finished, "default", policy)
+	if condition.Status != operatorv1.ConditionFalse {
+		t.Errorf("expected Degraded=False after rollout finished, got %s", condition.Status)
+	}
+	if err != nil {
+		t.Errorf("expected no requeue after rollout finished, got %v", err)
+	}
+	// A rollout that exceeds the timeout is reported as stuck.
+	stuck := []operatorv1.OperatorCondition{
+		cond(IngressControllerDeploymentReplicasAllAvailableConditionType, operatorv1.ConditionFalse, "", clock.Now().Add(-31*time.Minute)),
+	}
+	condition, _ = computeIngressDegradedCondition(stuck, "default", policy)
+	if condition.Status != operatorv1.ConditionTrue {
+		t.Errorf("expected Degraded=True for stuck rollout, got %s", condition.Status)
+	}
+}
@@ -0,0 +1,83 @@
+package ingress
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// IngressControllerTLSProfileMeetsMinimumVersionConditionType is the type of
+// the status condition that reports whether the ingresscontroller's effective
+// TLS profile meets the operator's configured minimum TLS version.  The
+// condition is False when the ingresscontroller requests a profile with a
+// weaker minimum TLS version than the configured floor, in which case the
+// floor is applied instead of the requested version.
+const IngressControllerTLSProfileMeetsMinimumVersionConditionType = "TLSProfileMeetsMinimumVersion"
+
+// tlsVersionRanks orders the TLS protocol versions so that profiles can be
+// compared against the configured floor.
+var tlsVersionRanks = map[configv1.TLSProtocolVersion]int{
+	configv1.VersionTLS10: 1,
+	configv1.VersionTLS11: 2,
+	configv1.VersionTLS12: 3,
+	configv1.VersionTLS13: 4,
+}
+
+// validateMinimumTLSVersion verifies that the given minimum TLS version is
+// either empty (meaning no floor is enforced) or a known TLS protocol version.
+func validateMinimumTLSVersion(version configv1.TLSProtocolVersion) error {
+	if len(version) == 0 {
+		return nil
+	}
+	if _, ok := tlsVersionRanks[version]; !ok {
+		return fmt.Errorf("unknown minimum TLS version: %q", version)
+	}
+	return nil
+}
+
+// clampTLSProfileSpec returns a TLS profile spec whose minimum TLS version is
+// no weaker than the given floor, and a boolean indicating whether the given
+// spec was clamped.  A profile that is already at or above the floor is
+// returned unmodified; the input is never mutated.
+func clampTLSProfileSpec(spec *configv1.TLSProfileSpec, floor configv1.TLSProtocolVersion) (*configv1.TLSProfileSpec, bool) {
+	if len(floor) == 0 {
+		return spec, false
+	}
+	if tlsVersionRanks[spec.MinTLSVersion] >= tlsVersionRanks[floor] {
+		return spec, false
+	}
+	clamped := spec.DeepCopy()
+	clamped.MinTLSVersion = floor
+	return clamped, true
+}
+
+// computeMinimumTLSVersionCondition returns a condition reporting whether the
+// TLS profile that the ingresscontroller requests meets the operator's
+// configured minimum TLS version.
+func (r *reconciler) computeMinimumTLSVersionCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	floor := r.config.MinimumTLSVersion
+	if len(floor) == 0 || !hasTLSSecurityProfile(ic) {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerTLSProfileMeetsMinimumVersionConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NoProfileRequested",
+			Message: "No TLS security profile is requested, or no minimum TLS version is enforced.",
+		}
+	}
+	spec := tlsProfileSpecForSecurityProfile(ic.Spec.TLSSecurityProfile)
+	if _, clamped := clampTLSProfileSpec(spec, floor); clamped {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerTLSProfileMeetsMinimumVersionConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "ProfileWeakerThanMinimum",
+			Message: fmt.Sprintf("The requested TLS security profile allows TLS version %q, which is weaker than the enforced minimum %q; the minimum is applied instead.", spec.MinTLSVersion, floor),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerTLSProfileMeetsMinimumVersionConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "ProfileMeetsMinimum",
+		Message: "The requested TLS security profile meets the enforced minimum TLS version.",
+	}
+}
@@ -0,0 +1,169 @@
+package ingress
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClampTLSProfileSpec(t *testing.T) {
+	testCases := []struct {
+		description     string
+		minTLSVersion   configv1.TLSProtocolVersion
+		floor           configv1.TLSProtocolVersion
+		expectClamped   bool
+		expectedVersion configv1.TLSProtocolVersion
+	}{
+		{
+			description:     "no floor",
+			minTLSVersion:   configv1.VersionTLS11,
+			expectedVersion: configv1.VersionTLS11,
+		},
+		{
+			description:     "profile meets the floor",
+			minTLSVersion:   configv1.VersionTLS12,
+			floor:           configv1.VersionTLS12,
+			expectedVersion: configv1.VersionTLS12,
+		},
+		{
+			description:     "profile tightens beyond the floor",
+			minTLSVersion:   configv1.VersionTLS13,
+			floor:           configv1.VersionTLS12,
+			expectedVersion: configv1.VersionTLS13,
+		},
+		{
+			description:     "profile weaker than the floor is clamped",
+			minTLSVersion:   configv1.VersionTLS10,
+			floor:           configv1.VersionTLS12,
+			expectClamped:   true,
+			expectedVersion: configv1.VersionTLS12,
+		},
+	}
+	for _, tc := range testCases {
+		spec := &configv1.TLSProfileSpec{MinTLSVersion: tc.minTLSVersion}
+		clamped, wasClamped := clampTLSProfileSpec(spec, tc.floor)
+		if wasClamped != tc.expectClamped {
+			t.Errorf("%q: expected clamped=%v, got %v", tc.description, tc.expectClamped, wasClamped)
+		}
+		if clamped.MinTLSVersion != tc.expectedVersion {
+			t.Errorf("%q: expected minimum TLS version %q, got %q", tc.description, tc.expectedVersion, clamped.MinTLSVersion)
+		}
+		if spec.MinTLSVersion != tc.minTLSVersion {
+			t.Errorf("%q: input spec was mutated", tc.description)
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentMinimumTLSVersion(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: operatorv1.IngressControllerSpec{
+			TLSSecurityProfile: &configv1.TLSSecurityProfile{
+				Type: configv1.TLSProfileCustomType,
+				Custom: &configv1.CustomTLSProfile{
+					TLSProfileSpec: configv1.TLSProfileSpec{
+						Ciphers:       []string{"ECDHE-ECDSA-AES128-GCM-SHA256"},
+						MinTLSVersion: configv1.VersionTLS11,
+					},
+				},
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	ingressConfig := &configv1.Ingress{}
+	apiConfig := &configv1.APIServer{}
+	networkConfig := &configv1.Network{}
+
+	// An ingresscontroller that requests TLS 1.1 with a TLS 1.2 floor gets
+	// the floor applied.
+	deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, configv1.VersionTLS12)
+	if err != nil {
+		t.Fatalf("failed to generate desired router deployment: %v", err)
+	}
+	env := map[string]string{}
+	for _, v := range deployment.Spec.Template.Spec.Containers[0].Env {
+		env[v.Name] = v.Value
+	}
+	if env["SSL_MIN_VERSION"] != "TLSv1.2" {
+		t.Errorf("expected SSL_MIN_VERSION %q, got %q", "TLSv1.2", env["SSL_MIN_VERSION"])
+	}
+
+	// Without a floor, the requested version applies (TLS 1.1 for the
+	// requested profile).
+	deployment, err = desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("failed to generate desired router deployment: %v", err)
+	}
+	env = map[string]string{}
+	for _, v := range deployment.Spec.Template.Spec.Containers[0].Env {
+		env[v.Name] = v.Value
+	}
+	if env["SSL_MIN_VERSION"] != "TLSv1.1" {
+		t.Errorf("expected SSL_MIN_VERSION %q, got %q", "TLSv1.1", env["SSL_MIN_VERSION"])
+	}
+}
+
+func TestComputeMinimumTLSVersionCondition(t *testing.T) {
+	icWithVersion := func(version configv1.TLSProtocolVersion) *operatorv1.IngressController {
+		return &operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operatorv1.IngressControllerSpec{
+				TLSSecurityProfile: &configv1.TLSSecurityProfile{
+					Type: configv1.TLSProfileCustomType,
+					Custom: &configv1.CustomTLSProfile{
+						TLSProfileSpec: configv1.TLSProfileSpec{
+							MinTLSVersion: version,
+						},
+					},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		description    string
+		ic             *operatorv1.IngressController
+		floor          configv1.TLSProtocolVersion
+		expectedStatus operatorv1.ConditionStatus
+	}{
+		{
+			description:    "no floor configured",
+			ic:             icWithVersion(configv1.VersionTLS10),
+			expectedStatus: operatorv1.ConditionTrue,
+		},
+		{
+			description:    "no profile requested",
+			ic:             &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			floor:          configv1.VersionTLS12,
+			expectedStatus: operatorv1.ConditionTrue,
+		},
+		{
+			description:    "profile tightens beyond the floor",
+			ic:             icWithVersion(configv1.VersionTLS13),
+			floor:          configv1.VersionTLS12,
+			expectedStatus: operatorv1.ConditionTrue,
+		},
+		{
+			description:    "profile weaker than the floor",
+			ic:             icWithVersion(configv1.VersionTLS10),
+			floor:          configv1.VersionTLS12,
+			expectedStatus: operatorv1.ConditionFalse,
+		},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{config: Config{MinimumTLSVersion: tc.floor}}
+		condition := r.computeMinimumTLSVersionCondition(tc.ic)
+		if condition.Type != IngressControllerTLSProfileMeetsMinimumVersionConditionType {
+			t.Errorf("%q: unexpected condition type %q", tc.description, condition.Type)
+		}
+		if condition.Status != tc.expectedStatus {
+			t.Errorf("%q: expected status %q, got %q", tc.description, tc.expectedStatus, condition.Status)
+		}
+	}
+}
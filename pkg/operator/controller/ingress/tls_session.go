@@ -0,0 +1,188 @@
+package ingress
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// TLSSessionTicketKeysSecretAnnotation can be set on an
+	// ingresscontroller to the name of a secret in the operand namespace
+	// holding TLS session ticket keys.  The secret must have a
+	// "tls-ticket-keys" key whose value is one base64-encoded 48-byte key
+	// per line.  The operator mounts the secret into the router deployment
+	// and points the router at the mounted key file.  Because the keys are
+	// mounted from a secret, rotating the secret updates the mounted
+	// content, which the router picks up with a soft reload that does not
+	// drop established connections.
+	TLSSessionTicketKeysSecretAnnotation = "ingress.operator.openshift.io/tls-session-ticket-keys"
+
+	// TLSSessionCacheSizeAnnotation can be set on an ingresscontroller to
+	// a positive integer to configure the router's TLS session cache size.
+	// If the annotation is not set, the router's default is used.
+	TLSSessionCacheSizeAnnotation = "ingress.operator.openshift.io/tls-session-cache-size"
+
+	// IngressControllerTLSSessionTicketKeysValidConditionType reports
+	// whether the TLS session ticket keys configured for the
+	// ingresscontroller are valid.
+	IngressControllerTLSSessionTicketKeysValidConditionType = "TLSSessionTicketKeysValid"
+
+	// RouterTLSSessionTicketKeyFileEnvName is the router environment
+	// variable that names the file holding TLS session ticket keys.
+	RouterTLSSessionTicketKeyFileEnvName = "ROUTER_TLS_SESSION_TICKET_KEY_FILE"
+
+	// RouterTLSSessionCacheSizeEnvName is the router environment variable
+	// that configures the TLS session cache size.
+	RouterTLSSessionCacheSizeEnvName = "ROUTER_TLS_SESSION_CACHE_SIZE"
+
+	// tlsSessionTicketKeysVolumeName is the name of the volume holding the
+	// TLS session ticket keys in the router deployment.
+	tlsSessionTicketKeysVolumeName = "tls-session-ticket-keys"
+
+	// tlsSessionTicketKeysVolumeMountPath is the directory under which the
+	// TLS session ticket keys secret is mounted in the router container.
+	tlsSessionTicketKeysVolumeMountPath = "/etc/pki/tls/session-ticket-keys"
+
+	// tlsSessionTicketKeysSecretKey is the key in the TLS session ticket
+	// keys secret that holds the key file.
+	tlsSessionTicketKeysSecretKey = "tls-ticket-keys"
+
+	// tlsSessionTicketKeySize is the required length in bytes of each
+	// decoded TLS session ticket key.
+	tlsSessionTicketKeySize = 48
+)
+
+// tlsSessionTicketKeysSecret returns the name of the secret holding TLS
+// session ticket keys for the ingresscontroller, and a boolean indicating
+// whether the ingresscontroller has session ticket keys configured.
+func tlsSessionTicketKeysSecret(ic *operatorv1.IngressController) (string, bool) {
+	name, ok := ic.Annotations[TLSSessionTicketKeysSecretAnnotation]
+	if !ok || len(name) == 0 {
+		return "", false
+	}
+	return name, true
+}
+
+// tlsSessionCacheSize returns the TLS session cache size configured for the
+// ingresscontroller, and a boolean indicating whether a valid size is
+// configured.  An invalid or nonpositive value is logged and ignored.
+func tlsSessionCacheSize(ic *operatorv1.IngressController) (int, bool) {
+	val, ok := ic.Annotations[TLSSessionCacheSizeAnnotation]
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.Atoi(val)
+	if err != nil || size <= 0 {
+		log.Info("ignoring invalid annotation value", "annotation", TLSSessionCacheSizeAnnotation, "value", val)
+		return 0, false
+	}
+	return size, true
+}
+
+// validateTLSSessionTicketKeys validates a TLS session ticket key file.  The
+// file must have at least one key, one base64-encoded key per line, and each
+// key must decode to exactly 48 bytes.
+func validateTLSSessionTicketKeys(data []byte) error {
+	numKeys := 0
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return fmt.Errorf("line %d: key is not valid base64: %v", i+1, err)
+		}
+		if len(key) != tlsSessionTicketKeySize {
+			return fmt.Errorf("line %d: key must be %d bytes, got %d", i+1, tlsSessionTicketKeySize, len(key))
+		}
+		numKeys++
+	}
+	if numKeys == 0 {
+		return fmt.Errorf("no keys found")
+	}
+	return nil
+}
+
+// validateTLSSessionTicketKeysSecret verifies that the named TLS session
+// ticket keys secret exists in the operand namespace and holds valid key
+// material.
+func (r *reconciler) validateTLSSessionTicketKeysSecret(secretName string) error {
+	secret := &corev1.Secret{}
+	name := types.NamespacedName{Namespace: controller.DefaultOperandNamespace, Name: secretName}
+	if err := r.client.Get(context.TODO(), name, secret); err != nil {
+		return fmt.Errorf("failed to get TLS session ticket keys secret %s: %v", name, err)
+	}
+	if err := validateTLSSessionTicketKeys(secret.Data[tlsSessionTicketKeysSecretKey]); err != nil {
+		return fmt.Errorf("TLS session ticket keys secret %s has invalid key material: %v", name, err)
+	}
+	return nil
+}
+
+// computeTLSSessionTicketKeysCondition computes the ingresscontroller's
+// "TLSSessionTicketKeysValid" status condition, which reports whether any
+// configured TLS session ticket keys are valid.
+func (r *reconciler) computeTLSSessionTicketKeysCondition(ic *operatorv1.IngressController) operatorv1.OperatorCondition {
+	secretName, ok := tlsSessionTicketKeysSecret(ic)
+	if !ok {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerTLSSessionTicketKeysValidConditionType,
+			Status:  operatorv1.ConditionTrue,
+			Reason:  "NotConfigured",
+			Message: "No TLS session ticket keys are configured.",
+		}
+	}
+	if err := r.validateTLSSessionTicketKeysSecret(secretName); err != nil {
+		return operatorv1.OperatorCondition{
+			Type:    IngressControllerTLSSessionTicketKeysValidConditionType,
+			Status:  operatorv1.ConditionFalse,
+			Reason:  "InvalidTicketKeys",
+			Message: err.Error(),
+		}
+	}
+	return operatorv1.OperatorCondition{
+		Type:    IngressControllerTLSSessionTicketKeysValidConditionType,
+		Status:  operatorv1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "The configured TLS session ticket keys are valid.",
+	}
+}
+
+// removeTLSSessionTicketKeyConfig removes the TLS session ticket key volume,
+// volume mount, and environment variable from the router deployment.  It is
+// used to keep invalid key material out of the router while the
+// "TLSSessionTicketKeysValid" status condition reports the problem.
+func removeTLSSessionTicketKeyConfig(deployment *appsv1.Deployment) {
+	volumes := deployment.Spec.Template.Spec.Volumes
+	for i, volume := range volumes {
+		if volume.Name == tlsSessionTicketKeysVolumeName {
+			deployment.Spec.Template.Spec.Volumes = append(volumes[:i], volumes[i+1:]...)
+			break
+		}
+	}
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	for i, mount := range container.VolumeMounts {
+		if mount.Name == tlsSessionTicketKeysVolumeName {
+			container.VolumeMounts = append(container.VolumeMounts[:i], container.VolumeMounts[i+1:]...)
+			break
+		}
+	}
+	for i, env := range container.Env {
+		if env.Name == RouterTLSSessionTicketKeyFileEnvName {
+			container.Env = append(container.Env[:i], container.Env[i+1:]...)
+			break
+		}
+	}
+}
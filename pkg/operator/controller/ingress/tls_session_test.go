@@ -0,0 +1,138 @@
+package ingress
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateTLSSessionTicketKeys(t *testing.T) {
+	validKey := base64.StdEncoding.EncodeToString(make([]byte, tlsSessionTicketKeySize))
+	shortKey := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	testCases := []struct {
+		description string
+		data        string
+		expectError bool
+	}{
+		{
+			description: "single valid key",
+			data:        validKey,
+		},
+		{
+			description: "multiple valid keys with blank lines",
+			data:        validKey + "\n\n" + validKey + "\n",
+		},
+		{
+			description: "empty file",
+			data:        "",
+			expectError: true,
+		},
+		{
+			description: "key is not base64",
+			data:        "not base64!",
+			expectError: true,
+		},
+		{
+			description: "key has wrong length",
+			data:        shortKey,
+			expectError: true,
+		},
+		{
+			description: "one bad key among good keys",
+			data:        validKey + "\n" + shortKey,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		err := validateTLSSessionTicketKeys([]byte(tc.data))
+		if tc.expectError && err == nil {
+			t.Errorf("%q: expected error, got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+func TestDesiredRouterDeploymentTLSSession(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+			Annotations: map[string]string{
+				TLSSessionTicketKeysSecretAnnotation: "router-ticket-keys",
+				TLSSessionCacheSizeAnnotation:        "20000",
+			},
+		},
+		Status: operatorv1.IngressControllerStatus{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+				Type: operatorv1.PrivateStrategyType,
+			},
+		},
+	}
+	ingressConfig := &configv1.Ingress{}
+	apiConfig := &configv1.APIServer{}
+	networkConfig := &configv1.Network{}
+	deployment, err := desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	foundVolume := false
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == tlsSessionTicketKeysVolumeName {
+			foundVolume = true
+			if volume.Secret == nil || volume.Secret.SecretName != "router-ticket-keys" {
+				t.Errorf("expected volume %q to reference secret %q, got %+v", volume.Name, "router-ticket-keys", volume.VolumeSource)
+			}
+		}
+	}
+	if !foundVolume {
+		t.Errorf("expected deployment to have volume %q", tlsSessionTicketKeysVolumeName)
+	}
+	foundMount := false
+	for _, mount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if mount.Name == tlsSessionTicketKeysVolumeName {
+			foundMount = true
+			if mount.MountPath != tlsSessionTicketKeysVolumeMountPath {
+				t.Errorf("expected mount path %q, got %q", tlsSessionTicketKeysVolumeMountPath, mount.MountPath)
+			}
+		}
+	}
+	if !foundMount {
+		t.Errorf("expected router container to mount volume %q", tlsSessionTicketKeysVolumeName)
+	}
+	expectedKeyFile := tlsSessionTicketKeysVolumeMountPath + "/" + tlsSessionTicketKeysSecretKey
+	checkDeploymentHasEnvVar(t, deployment, RouterTLSSessionTicketKeyFileEnvName, true, expectedKeyFile)
+	checkDeploymentHasEnvVar(t, deployment, RouterTLSSessionCacheSizeEnvName, true, "20000")
+
+	// An invalid cache size is ignored, and removing the ticket key
+	// annotation removes the wiring.
+	ic.Annotations = map[string]string{
+		TLSSessionCacheSizeAnnotation: "lots",
+	}
+	deployment, err = desiredRouterDeployment(ic, "quay.io/openshift/router:latest", ingressConfig, apiConfig, networkConfig, false, "")
+	if err != nil {
+		t.Fatalf("invalid router Deployment: %v", err)
+	}
+	checkDeploymentHasEnvVar(t, deployment, RouterTLSSessionTicketKeyFileEnvName, false, "")
+	checkDeploymentHasEnvVar(t, deployment, RouterTLSSessionCacheSizeEnvName, false, "")
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == tlsSessionTicketKeysVolumeName {
+			t.Errorf("expected deployment not to have volume %q", volume.Name)
+		}
+	}
+}
+
+func TestComputeTLSSessionTicketKeysConditionMessages(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}
+	r := &reconciler{}
+	condition := r.computeTLSSessionTicketKeysCondition(ic)
+	if condition.Status != operatorv1.ConditionTrue || !strings.Contains(condition.Reason, "NotConfigured") {
+		t.Errorf("expected True/NotConfigured for unconfigured ingresscontroller, got %s/%s", condition.Status, condition.Reason)
+	}
+}
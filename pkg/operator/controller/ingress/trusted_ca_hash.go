@@ -0,0 +1,86 @@
+package ingress
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	// trustedCABundleHashAnnotation is set on the router deployment's pod
+	// template to a hash of the trusted CA configmap's bundle so that a
+	// bundle rotation rolls the deployment and the routers pick up the new
+	// trust store.
+	trustedCABundleHashAnnotation = "ingress.operator.openshift.io/trusted-ca-bundle-hash"
+
+	// trustedCAConfigMapName is the name of the configmap in the operator
+	// namespace holding the trusted CA bundle.
+	trustedCAConfigMapName = "trusted-ca"
+
+	// trustedCABundleKey is the configmap key under which the trusted CA
+	// bundle is stored.
+	trustedCABundleKey = "ca-bundle.crt"
+)
+
+// applyTrustedCABundleHash annotates the deployment's pod template with a hash
+// of the trusted CA configmap's bundle.  A missing configmap or an empty
+// bundle clears the annotation rather than recording a hash of nothing.
+func (r *reconciler) applyTrustedCABundleHash(deployment *appsv1.Deployment) error {
+	configmap := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: r.config.Namespace, Name: trustedCAConfigMapName}
+	if err := r.client.Get(context.TODO(), name, configmap); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get configmap %s: %v", name, err)
+		}
+		configmap = nil
+	}
+	var bundle string
+	if configmap != nil {
+		bundle = configmap.Data[trustedCABundleKey]
+	}
+	if len(strings.TrimSpace(bundle)) == 0 {
+		delete(deployment.Spec.Template.Annotations, trustedCABundleHashAnnotation)
+		return nil
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[trustedCABundleHashAnnotation] = trustedCABundleHash(bundle)
+	return nil
+}
+
+// trustedCABundleHash returns a stable hash of the given CA bundle.  The
+// bundle's PEM blocks are hashed in sorted order by their decoded contents so
+// that reordered certificates or reflowed whitespace do not change the hash
+// and cause a spurious rollout.  Content that contains no PEM blocks is hashed
+// with its whitespace normalized.
+func trustedCABundleHash(bundle string) string {
+	var blocks []string
+	for rest := []byte(bundle); ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block.Type+string(block.Bytes))
+	}
+	if len(blocks) == 0 {
+		blocks = []string{strings.Join(strings.Fields(bundle), " ")}
+	}
+	sort.Strings(blocks)
+	hasher := fnv.New32a()
+	for _, block := range blocks {
+		hasher.Write([]byte(block))
+	}
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
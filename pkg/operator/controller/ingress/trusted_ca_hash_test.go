@@ -0,0 +1,142 @@
+package ingress
+
+import (
+	"encoding/pem"
+	"testing"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// pemBlock returns a PEM-encoded CERTIFICATE block with the given contents.
+func pemBlock(contents string) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte(contents)}))
+}
+
+func TestTrustedCABundleHash(t *testing.T) {
+	certA := pemBlock("certificate a")
+	certB := pemBlock("certificate b")
+	certC := pemBlock("certificate c")
+	testCases := []struct {
+		description string
+		bundle      string
+		other       string
+		expectEqual bool
+	}{
+		{
+			description: "identical bundles",
+			bundle:      certA + certB,
+			other:       certA + certB,
+			expectEqual: true,
+		},
+		{
+			description: "reordered certificates",
+			bundle:      certA + certB,
+			other:       certB + certA,
+			expectEqual: true,
+		},
+		{
+			description: "extra whitespace between certificates",
+			bundle:      certA + certB,
+			other:       certA + "\n\n" + certB + "\n",
+			expectEqual: true,
+		},
+		{
+			description: "different certificates",
+			bundle:      certA + certB,
+			other:       certA + certC,
+			expectEqual: false,
+		},
+		{
+			description: "non-PEM content with reflowed whitespace",
+			bundle:      "not a\tcertificate",
+			other:       "not  a certificate\n",
+			expectEqual: true,
+		},
+		{
+			description: "different non-PEM content",
+			bundle:      "not a certificate",
+			other:       "still not a certificate",
+			expectEqual: false,
+		},
+	}
+	for _, tc := range testCases {
+		hash, otherHash := trustedCABundleHash(tc.bundle), trustedCABundleHash(tc.other)
+		if (hash == otherHash) != tc.expectEqual {
+			t.Errorf("%q: expected equal hashes to be %v, got %q and %q", tc.description, tc.expectEqual, hash, otherHash)
+		}
+	}
+}
+
+func TestApplyTrustedCABundleHash(t *testing.T) {
+	deployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							trustedCABundleHashAnnotation: "stale",
+						},
+					},
+				},
+			},
+		}
+	}
+	configmap := func(bundle string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-operator",
+				Name:      trustedCAConfigMapName,
+			},
+			Data: map[string]string{trustedCABundleKey: bundle},
+		}
+	}
+	testCases := []struct {
+		description string
+		configmap   *corev1.ConfigMap
+		expectHash  bool
+	}{
+		{
+			description: "missing configmap clears the annotation",
+		},
+		{
+			description: "empty bundle clears the annotation",
+			configmap:   configmap("   \n"),
+		},
+		{
+			description: "non-empty bundle sets the annotation",
+			configmap:   configmap(pemBlock("certificate a")),
+			expectHash:  true,
+		},
+	}
+	for _, tc := range testCases {
+		var objects []runtime.Object
+		if tc.configmap != nil {
+			objects = append(objects, tc.configmap)
+		}
+		r := &reconciler{
+			client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objects...),
+			config: Config{Namespace: "openshift-ingress-operator"},
+		}
+		d := deployment()
+		if err := r.applyTrustedCABundleHash(d); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		hash, ok := d.Spec.Template.Annotations[trustedCABundleHashAnnotation]
+		if ok != tc.expectHash {
+			t.Errorf("%q: expected annotation presence to be %v, got %v", tc.description, tc.expectHash, ok)
+			continue
+		}
+		if tc.expectHash && hash == "stale" {
+			t.Errorf("%q: expected the annotation to be recomputed", tc.description)
+		}
+	}
+}
@@ -168,6 +168,16 @@ func WildcardDNSRecordName(ic *operatorv1.IngressController) types.NamespacedNam
 	}
 }
 
+// SharedWildcardDNSRecordName returns the namespaced name of the shared
+// wildcard DNS record published for ingresscontrollers that opt in to a
+// shared external load balancer.
+func SharedWildcardDNSRecordName() types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: DefaultOperatorNamespace,
+		Name:      "shared-wildcard",
+	}
+}
+
 func CanaryDaemonSetName() types.NamespacedName {
 	return types.NamespacedName{
 		Namespace: DefaultCanaryNamespace,
@@ -0,0 +1,183 @@
+// The pod-health controller maintains the router-health readiness-gate
+// condition on router pods that have the readiness gate.  It periodically
+// probes each gated pod's health endpoint and sets the condition accordingly,
+// so that an unhealthy router is removed from service endpoints even while the
+// basic readiness probe still passes.
+package podhealth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	runtimecontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	controllerName = "pod_health_controller"
+
+	// routerHealthPort is the port on which the router serves its health
+	// endpoints.
+	routerHealthPort = 1936
+
+	// probePeriod is how often each gated pod is probed.
+	probePeriod = 30 * time.Second
+
+	// probeTimeout bounds a single health probe.
+	probeTimeout = 5 * time.Second
+)
+
+var log = logf.Logger.WithName(controllerName)
+
+// Config holds all the things necessary for the controller to run.
+type Config struct {
+	// Namespace is the namespace in which router pods run.
+	Namespace string
+}
+
+type reconciler struct {
+	config Config
+
+	client     client.Client
+	httpClient *http.Client
+}
+
+// New creates the pod-health controller.  The controller watches router pods
+// that have the router-health readiness gate and maintains the gate's
+// condition on them.
+func New(mgr manager.Manager, config Config) (runtimecontroller.Controller, error) {
+	reconciler := &reconciler{
+		config: config,
+		client: mgr.GetClient(),
+		httpClient: &http.Client{
+			Timeout: probeTimeout,
+		},
+	}
+	c, err := runtimecontroller.New(controllerName, mgr, runtimecontroller.Options{Reconciler: reconciler})
+	if err != nil {
+		return nil, err
+	}
+	isGatedPod := func(meta metav1.Object, object runtime.Object) bool {
+		if meta.GetNamespace() != config.Namespace {
+			return false
+		}
+		pod, ok := object.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		return podHasReadinessGate(pod)
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(isGatedPod)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// podHasReadinessGate returns true if the pod has the router-health readiness
+// gate.
+func podHasReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == ingresscontroller.RouterHealthyConditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconcile probes the pod's health endpoint and updates the router-health
+// condition on the pod.  Gated pods are requeued so that the condition is
+// refreshed periodically.
+func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, fmt.Errorf("failed to get pod %s: %v", request.NamespacedName, err)
+	}
+	if pod.DeletionTimestamp != nil || !podHasReadinessGate(pod) {
+		return reconcile.Result{}, nil
+	}
+	if len(pod.Status.PodIP) == 0 {
+		// The pod isn't networked yet; check again later.
+		return reconcile.Result{RequeueAfter: probePeriod}, nil
+	}
+	healthy, reason, message := r.checkRouterHealth(pod.Status.PodIP)
+	if err := r.setRouterHealthyCondition(pod, healthy, reason, message); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to update %s condition on pod %s/%s: %v", ingresscontroller.RouterHealthyConditionType, pod.Namespace, pod.Name, err)
+	}
+	return reconcile.Result{RequeueAfter: probePeriod}, nil
+}
+
+// checkRouterHealth probes the router's health endpoint at the given pod IP
+// and reports whether the router is healthy, with a reason and message for the
+// pod condition.
+func (r *reconciler) checkRouterHealth(podIP string) (bool, string, string) {
+	url := fmt.Sprintf("http://%s/healthz/ready", net.JoinHostPort(podIP, fmt.Sprintf("%d", routerHealthPort)))
+	resp, err := r.httpClient.Get(url)
+	if err != nil {
+		return false, "ProbeError", fmt.Sprintf("error probing router health endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return false, "RouterUnhealthy", fmt.Sprintf("router health endpoint returned status %d", resp.StatusCode)
+	}
+	return true, "RouterHealthy", "router health endpoint reports healthy"
+}
+
+// setRouterHealthyCondition sets the router-health condition on the pod,
+// updating the pod's status if the condition changed.
+func (r *reconciler) setRouterHealthyCondition(pod *corev1.Pod, healthy bool, reason, message string) error {
+	status := corev1.ConditionFalse
+	if healthy {
+		status = corev1.ConditionTrue
+	}
+	condition := corev1.PodCondition{
+		Type:               ingresscontroller.RouterHealthyConditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	updated := pod.DeepCopy()
+	found := false
+	for i, c := range updated.Status.Conditions {
+		if c.Type != condition.Type {
+			continue
+		}
+		found = true
+		if c.Status == condition.Status && c.Reason == condition.Reason && c.Message == condition.Message {
+			return nil
+		}
+		if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+		updated.Status.Conditions[i] = condition
+		break
+	}
+	if !found {
+		updated.Status.Conditions = append(updated.Status.Conditions, condition)
+	}
+	if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+		return err
+	}
+	log.Info("updated pod condition", "namespace", pod.Namespace, "name", pod.Name, "condition", condition.Type, "status", condition.Status, "reason", reason)
+	return nil
+}
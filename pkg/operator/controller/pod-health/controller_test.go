@@ -0,0 +1,83 @@
+package podhealth
+
+import (
+	"context"
+	"testing"
+
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetRouterHealthyCondition(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress",
+			Name:      "router-default-1",
+		},
+		Spec: corev1.PodSpec{
+			ReadinessGates: []corev1.PodReadinessGate{
+				{ConditionType: ingresscontroller.RouterHealthyConditionType},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme.Scheme, pod)
+	r := &reconciler{client: client}
+
+	getCondition := func() *corev1.PodCondition {
+		t.Helper()
+		updated := &corev1.Pod{}
+		name := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		if err := client.Get(context.TODO(), name, updated); err != nil {
+			t.Fatalf("failed to get pod: %v", err)
+		}
+		for i, c := range updated.Status.Conditions {
+			if c.Type == ingresscontroller.RouterHealthyConditionType {
+				return &updated.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	if err := r.setRouterHealthyCondition(pod, false, "ProbeError", "error probing router health endpoint"); err != nil {
+		t.Fatalf("failed to set condition: %v", err)
+	}
+	condition := getCondition()
+	if condition == nil {
+		t.Fatal("expected condition to be set")
+	}
+	if condition.Status != corev1.ConditionFalse || condition.Reason != "ProbeError" {
+		t.Errorf("expected False/ProbeError, got %s/%s", condition.Status, condition.Reason)
+	}
+
+	pod = &corev1.Pod{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Namespace: "openshift-ingress", Name: "router-default-1"}, pod); err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	if err := r.setRouterHealthyCondition(pod, true, "RouterHealthy", "router health endpoint reports healthy"); err != nil {
+		t.Fatalf("failed to set condition: %v", err)
+	}
+	condition = getCondition()
+	if condition == nil || condition.Status != corev1.ConditionTrue || condition.Reason != "RouterHealthy" {
+		t.Errorf("expected True/RouterHealthy, got %+v", condition)
+	}
+}
+
+func TestPodHasReadinessGate(t *testing.T) {
+	pod := &corev1.Pod{}
+	if podHasReadinessGate(pod) {
+		t.Error("expected pod without readiness gates to not match")
+	}
+	pod.Spec.ReadinessGates = []corev1.PodReadinessGate{
+		{ConditionType: ingresscontroller.RouterHealthyConditionType},
+	}
+	if !podHasReadinessGate(pod) {
+		t.Error("expected pod with the router-health readiness gate to match")
+	}
+}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -16,7 +17,6 @@ import (
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
-	oputil "github.com/openshift/cluster-ingress-operator/pkg/util"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -25,7 +25,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
 
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -41,6 +40,12 @@ const (
 
 	ingressesEqualConditionMessage = "desired and current number of IngressControllers are equal"
 
+	// degradedConditionsAnnotation is an annotation on the clusteroperator
+	// that exposes the full list of degraded ingresscontrollers as JSON for
+	// consumers that need more detail than the summary in the Degraded
+	// condition message.
+	degradedConditionsAnnotation = "ingress.operator.openshift.io/degraded-conditions"
+
 	controllerName = "status_controller"
 )
 
@@ -55,10 +60,14 @@ var clock utilclock.Clock = utilclock.RealClock{}
 // The controller watches IngressController resources in the manager namespace
 // and uses them to compute the operator status.
 func New(mgr manager.Manager, config Config) (controller.Controller, error) {
+	reader := config.CachedReader
+	if reader == nil {
+		reader = mgr.GetClient()
+	}
 	reconciler := &reconciler{
 		config: config,
 		client: mgr.GetClient(),
-		cache:  mgr.GetCache(),
+		reader: reader,
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: reconciler})
 	if err != nil {
@@ -75,6 +84,10 @@ type Config struct {
 	IngressControllerImage string
 	OperatorReleaseVersion string
 	Namespace              string
+	// CachedReader, if set, is used for reads of watched objects.  The
+	// status controller is tolerant of stale reads because it reconciles
+	// again on every change to the objects it derives status from.
+	CachedReader client.Reader
 }
 
 // reconciler handles the actual status reconciliation logic in response to
@@ -83,7 +96,7 @@ type reconciler struct {
 	config Config
 
 	client client.Client
-	cache  cache.Cache
+	reader client.Reader
 }
 
 // Reconcile computes the operator's current status and therefrom creates or
@@ -151,6 +164,7 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 	co.Status.Conditions = mergeConditions(co.Status.Conditions, computeOperatorAvailableCondition(allIngressesAvailable))
 	co.Status.Conditions = mergeConditions(co.Status.Conditions, computeOperatorProgressingCondition(allIngressesAvailable, oldStatus.Versions, co.Status.Versions, r.config.OperatorReleaseVersion, r.config.IngressControllerImage))
 	co.Status.Conditions = mergeConditions(co.Status.Conditions, computeOperatorDegradedCondition(state.IngressControllers))
+	co.Status.Conditions = mergeConditions(co.Status.Conditions, computeIngressDomainCondition(state.IngressConfig))
 
 	if !operatorStatusesEqual(*oldStatus, co.Status) {
 		if err := r.client.Status().Update(context.TODO(), co); err != nil {
@@ -158,9 +172,41 @@ func (r *reconciler) Reconcile(request reconcile.Request) (reconcile.Result, err
 		}
 	}
 
+	if err := r.ensureDegradedConditionsAnnotation(co, state.IngressControllers); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to update degraded-conditions annotation on clusteroperator %s: %v", co.Name, err)
+	}
+
 	return reconcile.Result{}, nil
 }
 
+// ensureDegradedConditionsAnnotation keeps the degraded-conditions annotation
+// on the clusteroperator in sync with the degraded ingresscontrollers.  The
+// annotation holds the full list as JSON; the Degraded condition message only
+// holds a summary.
+func (r *reconciler) ensureDegradedConditionsAnnotation(co *configv1.ClusterOperator, ingresses []operatorv1.IngressController) error {
+	var value string
+	if degraded := degradedIngresses(ingresses); len(degraded) != 0 {
+		data, err := json.Marshal(degraded)
+		if err != nil {
+			return fmt.Errorf("failed to marshal degraded conditions: %v", err)
+		}
+		value = string(data)
+	}
+	if co.Annotations[degradedConditionsAnnotation] == value {
+		return nil
+	}
+	updated := co.DeepCopy()
+	if len(value) == 0 {
+		delete(updated.Annotations, degradedConditionsAnnotation)
+	} else {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[degradedConditionsAnnotation] = value
+	}
+	return r.client.Update(context.TODO(), updated)
+}
+
 // Populate versions and conditions in cluster operator status as CVO expects these fields.
 func initializeClusterOperator(co *configv1.ClusterOperator) {
 	co.Status.Versions = []configv1.OperandVersion{
@@ -190,6 +236,7 @@ func initializeClusterOperator(co *configv1.ClusterOperator) {
 }
 
 type operatorState struct {
+	IngressConfig      *configv1.Ingress
 	IngressNamespace   *corev1.Namespace
 	CanaryNamespace    *corev1.Namespace
 	IngressControllers []operatorv1.IngressController
@@ -201,8 +248,17 @@ type operatorState struct {
 func (r *reconciler) getOperatorState(ingressNamespace, canaryNamespace string) (operatorState, error) {
 	state := operatorState{}
 
+	ingressConfig := &configv1.Ingress{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingressConfig); err != nil {
+		if !errors.IsNotFound(err) {
+			return state, fmt.Errorf("failed to get ingress 'cluster': %v", err)
+		}
+	} else {
+		state.IngressConfig = ingressConfig
+	}
+
 	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ingressNamespace}}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: ingressNamespace}, ns); err != nil {
+	if err := r.reader.Get(context.TODO(), types.NamespacedName{Name: ingressNamespace}, ns); err != nil {
 		if !errors.IsNotFound(err) {
 			return state, fmt.Errorf("failed to get namespace %q: %v", ingressNamespace, err)
 		}
@@ -211,7 +267,7 @@ func (r *reconciler) getOperatorState(ingressNamespace, canaryNamespace string)
 	}
 
 	ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: canaryNamespace}}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: canaryNamespace}, ns); err != nil {
+	if err := r.reader.Get(context.TODO(), types.NamespacedName{Name: canaryNamespace}, ns); err != nil {
 		if !errors.IsNotFound(err) {
 			return state, fmt.Errorf("failed to get namespace %q: %v", canaryNamespace, err)
 		}
@@ -220,7 +276,7 @@ func (r *reconciler) getOperatorState(ingressNamespace, canaryNamespace string)
 	}
 
 	ingressList := &operatorv1.IngressControllerList{}
-	if err := r.cache.List(context.TODO(), ingressList, client.InNamespace(r.config.Namespace)); err != nil {
+	if err := r.reader.List(context.TODO(), ingressList, client.InNamespace(r.config.Namespace)); err != nil {
 		return state, fmt.Errorf("failed to list ingresscontrollers in %q: %v", r.config.Namespace, err)
 	} else {
 		state.IngressControllers = ingressList.Items
@@ -267,36 +323,79 @@ func checkAllIngressesAvailable(ingresses []operatorv1.IngressController) bool {
 	return len(ingresses) != 0
 }
 
-// computeOperatorDegradedCondition computes the operator's current Degraded status state.
-func computeOperatorDegradedCondition(ingresses []operatorv1.IngressController) configv1.ClusterOperatorStatusCondition {
-	degradedIngresses := make(map[*operatorv1.IngressController]operatorv1.OperatorCondition)
-	for i, ingress := range ingresses {
-		for j, cond := range ingress.Status.Conditions {
+// degradedIngress describes one degraded ingresscontroller for aggregation
+// into the clusteroperator status.
+type degradedIngress struct {
+	Name    string `json:"ingresscontroller"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// degradedIngresses returns the degraded ingresscontrollers in priority order:
+// the default ingresscontroller first, as it carries most cluster ingress
+// traffic and is therefore the most severe, then the rest sorted by name so
+// that the result is deterministic.
+func degradedIngresses(ingresses []operatorv1.IngressController) []degradedIngress {
+	var degraded []degradedIngress
+	for _, ingress := range ingresses {
+		for _, cond := range ingress.Status.Conditions {
 			if cond.Type == operatorv1.OperatorStatusTypeDegraded && cond.Status == operatorv1.ConditionTrue {
-				degradedIngresses[&ingresses[i]] = ingress.Status.Conditions[j]
+				degraded = append(degraded, degradedIngress{
+					Name:    ingress.Name,
+					Reason:  cond.Reason,
+					Message: cond.Message,
+				})
 			}
 		}
 	}
-	if len(degradedIngresses) == 0 {
+	sort.Slice(degraded, func(i, j int) bool {
+		iDefault := degraded[i].Name == manifests.DefaultIngressControllerName
+		jDefault := degraded[j].Name == manifests.DefaultIngressControllerName
+		if iDefault != jDefault {
+			return iDefault
+		}
+		return degraded[i].Name < degraded[j].Name
+	})
+	return degraded
+}
+
+// computeOperatorDegradedCondition computes the operator's current Degraded
+// status state.  When several ingresscontrollers are degraded, the condition
+// message is a prioritized summary (most severe first) with
+// ingresscontrollers that share the same failure de-duplicated into one entry.
+func computeOperatorDegradedCondition(ingresses []operatorv1.IngressController) configv1.ClusterOperatorStatusCondition {
+	degraded := degradedIngresses(ingresses)
+	if len(degraded) == 0 {
 		return configv1.ClusterOperatorStatusCondition{
 			Type:   configv1.OperatorDegraded,
 			Status: configv1.ConditionFalse,
 			Reason: "NoIngressControllersDegraded",
 		}
 	}
-	message := "Some ingresscontrollers are degraded:"
-	// Sort keys so that the result is deterministic.
-	keys := make([]*operatorv1.IngressController, 0, len(degradedIngresses))
-	for ingress := range degradedIngresses {
-		keys = append(keys, ingress)
-	}
-	sort.Slice(keys, func(i, j int) bool {
-		return oputil.ObjectLess(&keys[i].ObjectMeta, &keys[j].ObjectMeta)
-	})
-	for _, ingress := range keys {
-		cond := degradedIngresses[ingress]
-		message = fmt.Sprintf("%s ingresscontroller %q is degraded: %s: %s", message, ingress.Name, cond.Reason, cond.Message)
+	// Group ingresscontrollers that are degraded for the same reason and
+	// message, preserving priority order.
+	type failure struct {
+		reason, message string
+	}
+	var order []failure
+	members := map[failure][]string{}
+	for _, d := range degraded {
+		f := failure{reason: d.Reason, message: d.Message}
+		if _, ok := members[f]; !ok {
+			order = append(order, f)
+		}
+		members[f] = append(members[f], fmt.Sprintf("%q", d.Name))
+	}
+	entries := make([]string, 0, len(order))
+	for _, f := range order {
+		names := members[f]
+		noun := "ingresscontroller"
+		if len(names) > 1 {
+			noun = "ingresscontrollers"
+		}
+		entries = append(entries, fmt.Sprintf("%s %s: %s: %s", noun, strings.Join(names, ", "), f.reason, f.message))
 	}
+	message := "Some ingresscontrollers are degraded: " + strings.Join(entries, "; ")
 	return configv1.ClusterOperatorStatusCondition{
 		Type:    configv1.OperatorDegraded,
 		Status:  configv1.ConditionTrue,
@@ -305,6 +404,27 @@ func computeOperatorDegradedCondition(ingresses []operatorv1.IngressController)
 	}
 }
 
+// computeIngressDomainCondition reports whether the cluster ingress config
+// has a domain set.  When the domain is empty, the operator cannot create the
+// default ingresscontroller, so surface a condition that explains the missing
+// domain rather than leaving the user to infer it from partial state.
+func computeIngressDomainCondition(ingressConfig *configv1.Ingress) configv1.ClusterOperatorStatusCondition {
+	if ingressConfig == nil || len(ingressConfig.Spec.Domain) == 0 {
+		return configv1.ClusterOperatorStatusCondition{
+			Type:    "DefaultIngressDomainValid",
+			Status:  configv1.ConditionFalse,
+			Reason:  "DomainEmpty",
+			Message: "The cluster ingress config has an empty domain; the default ingresscontroller cannot be created until spec.domain is set on ingresses.config.openshift.io/cluster",
+		}
+	}
+	return configv1.ClusterOperatorStatusCondition{
+		Type:    "DefaultIngressDomainValid",
+		Status:  configv1.ConditionTrue,
+		Reason:  "DomainSet",
+		Message: fmt.Sprintf("The cluster ingress domain is %q", ingressConfig.Spec.Domain),
+	}
+}
+
 // computeOperatorProgressingCondition computes the operator's current Progressing status state.
 func computeOperatorProgressingCondition(allIngressesAvailable bool, oldVersions, curVersions []configv1.OperandVersion, operatorReleaseVersion, ingressControllerImage string) configv1.ClusterOperatorStatusCondition {
 	// TODO: Update progressingCondition when an ingresscontroller
@@ -7,6 +7,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -486,3 +487,55 @@ func TestComputeOperatorStatusVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestComputeOperatorDegradedCondition(t *testing.T) {
+	degradedIngressController := func(name, reason, message string) operatorv1.IngressController {
+		return operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: operatorv1.IngressControllerStatus{
+				Conditions: []operatorv1.OperatorCondition{
+					{
+						Type:    operatorv1.OperatorStatusTypeDegraded,
+						Status:  operatorv1.ConditionTrue,
+						Reason:  reason,
+						Message: message,
+					},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		description     string
+		ingresses       []operatorv1.IngressController
+		expectDegraded  configv1.ConditionStatus
+		expectedMessage string
+	}{
+		{
+			description: "no ingresscontrollers degraded",
+			ingresses: []operatorv1.IngressController{
+				{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			},
+			expectDegraded: configv1.ConditionFalse,
+		},
+		{
+			description: "three degraded, default first, duplicates folded",
+			ingresses: []operatorv1.IngressController{
+				degradedIngressController("zzz", "DegradedConditions", "DeploymentAvailable=False"),
+				degradedIngressController("default", "DegradedConditions", "DNSReady=False"),
+				degradedIngressController("aaa", "DegradedConditions", "DeploymentAvailable=False"),
+			},
+			expectDegraded:  configv1.ConditionTrue,
+			expectedMessage: `Some ingresscontrollers are degraded: ingresscontroller "default": DegradedConditions: DNSReady=False; ingresscontrollers "aaa", "zzz": DegradedConditions: DeploymentAvailable=False`,
+		},
+	}
+	for _, tc := range testCases {
+		condition := computeOperatorDegradedCondition(tc.ingresses)
+		if condition.Status != tc.expectDegraded {
+			t.Errorf("%q: expected status %s, got %s", tc.description, tc.expectDegraded, condition.Status)
+			continue
+		}
+		if len(tc.expectedMessage) != 0 && condition.Message != tc.expectedMessage {
+			t.Errorf("%q: expected message:\n%s\ngot:\n%s", tc.description, tc.expectedMessage, condition.Message)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package operator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// defaultIngressControllerCreateFailures counts failed attempts to
+	// create the default ingresscontroller.  Persistent failures (for
+	// example, an invalid provider-parameter combination) can be alerted
+	// on without reading the operator's logs.
+	defaultIngressControllerCreateFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ingress_operator_default_ingresscontroller_create_failures_total",
+		Help: "Number of failed attempts to create the default ingresscontroller.",
+	})
+
+	// defaultIngressControllerExists indicates whether the default
+	// ingresscontroller currently exists (1) or not (0).
+	defaultIngressControllerExists = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ingress_operator_default_ingresscontroller_exists",
+		Help: "Whether the default ingresscontroller exists (1) or not (0).",
+	})
+
+	// defaultIngressControllerAvailableDuration records how long the
+	// default ingresscontroller took to become available after it was
+	// created, which bounds install-time ingress availability.  The value
+	// is derived from the ingresscontroller's creation timestamp and the
+	// Available condition's transition time, so it survives operator
+	// restarts without double-counting.
+	defaultIngressControllerAvailableDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ingress_operator_default_ingresscontroller_available_duration_seconds",
+		Help: "Time in seconds from the default ingresscontroller's creation until its Available status condition became true.",
+	})
+)
+
+func init() {
+	ctrlruntimemetrics.Registry.MustRegister(defaultIngressControllerCreateFailures)
+	ctrlruntimemetrics.Registry.MustRegister(defaultIngressControllerExists)
+	ctrlruntimemetrics.Registry.MustRegister(defaultIngressControllerAvailableDuration)
+}
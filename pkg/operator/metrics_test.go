@@ -0,0 +1,171 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// failingCreateClient fails all create requests.
+type failingCreateClient struct {
+	crclient.Client
+}
+
+func (c *failingCreateClient) Create(ctx context.Context, obj runtime.Object, opts ...crclient.CreateOption) error {
+	return fmt.Errorf("admission webhook denied the request")
+}
+
+// operatorMetricValue returns the current value of the named unlabeled
+// counter or gauge metric.
+func operatorMetricValue(t *testing.T, name string) float64 {
+	t.Helper()
+	families, err := ctrlruntimemetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if counter := metric.GetCounter(); counter != nil {
+				return counter.GetValue()
+			}
+			if gauge := metric.GetGauge(); gauge != nil {
+				return gauge.GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// TestDefaultIngressControllerMetrics verifies that failed creates of the
+// default ingresscontroller increment the failure counter and that the
+// existence gauge tracks whether the default ingresscontroller exists.
+func TestDefaultIngressControllerMetrics(t *testing.T) {
+	const (
+		failuresMetric = "ingress_operator_default_ingresscontroller_create_failures_total"
+		existsMetric   = "ingress_operator_default_ingresscontroller_exists"
+	)
+	objects := []runtime.Object{
+		&configv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.IngressSpec{Domain: "apps.example.com"},
+		},
+		&configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objects...)
+	operator := &Operator{
+		client:    &failingCreateClient{Client: fakeClient},
+		namespace: "openshift-ingress-operator",
+		config:    operatorconfig.Config{},
+	}
+
+	// A failed create increments the failure counter, and the gauge
+	// reports that the default ingresscontroller does not exist.
+	failuresBefore := operatorMetricValue(t, failuresMetric)
+	if err := operator.ensureDefaultIngressController(); err == nil {
+		t.Fatal("expected the create to fail")
+	}
+	if failures := operatorMetricValue(t, failuresMetric); failures != failuresBefore+1 {
+		t.Errorf("expected %s to be %v, got %v", failuresMetric, failuresBefore+1, failures)
+	}
+	if exists := operatorMetricValue(t, existsMetric); exists != 0 {
+		t.Errorf("expected %s to be 0, got %v", existsMetric, exists)
+	}
+
+	// A successful create sets the gauge and leaves the counter alone.
+	operator.client = fakeClient
+	if err := operator.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failures := operatorMetricValue(t, failuresMetric); failures != failuresBefore+1 {
+		t.Errorf("expected %s to remain %v, got %v", failuresMetric, failuresBefore+1, failures)
+	}
+	if exists := operatorMetricValue(t, existsMetric); exists != 1 {
+		t.Errorf("expected %s to be 1, got %v", existsMetric, exists)
+	}
+}
+
+// TestDefaultIngressControllerAvailableDurationMetric verifies that the time
+// from the default ingresscontroller's creation to its Available condition
+// becoming true is recorded, and recorded only once.
+func TestDefaultIngressControllerAvailableDurationMetric(t *testing.T) {
+	const durationMetric = "ingress_operator_default_ingresscontroller_available_duration_seconds"
+	created := time.Now().Add(-10 * time.Minute)
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "openshift-ingress-operator",
+			Name:              manifests.DefaultIngressControllerName,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: operatorv1.IngressControllerStatus{
+			Conditions: []operatorv1.OperatorCondition{
+				{
+					Type:               operatorv1.OperatorStatusTypeAvailable,
+					Status:             operatorv1.ConditionFalse,
+					LastTransitionTime: metav1.NewTime(created),
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), ic)
+	operator := &Operator{
+		client:    fakeClient,
+		namespace: "openshift-ingress-operator",
+	}
+
+	// While the ingresscontroller is not yet available, nothing is
+	// recorded.
+	defaultIngressControllerAvailableDuration.Set(0)
+	if err := operator.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if duration := operatorMetricValue(t, durationMetric); duration != 0 {
+		t.Fatalf("expected %s to be 0 before the ingresscontroller is available, got %v", durationMetric, duration)
+	}
+
+	// Once the Available condition transitions to true, the duration from
+	// creation to the transition is recorded.
+	ic.Status.Conditions[0].Status = operatorv1.ConditionTrue
+	ic.Status.Conditions[0].LastTransitionTime = metav1.NewTime(created.Add(90 * time.Second))
+	if err := fakeClient.Status().Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingresscontroller status: %v", err)
+	}
+	if err := operator.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if duration := operatorMetricValue(t, durationMetric); duration != 90 {
+		t.Fatalf("expected %s to be 90, got %v", durationMetric, duration)
+	}
+
+	// A later transition (for example, a flap after a restart) does not
+	// overwrite the recorded duration.
+	ic.Status.Conditions[0].LastTransitionTime = metav1.NewTime(created.Add(5 * time.Minute))
+	if err := fakeClient.Status().Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingresscontroller status: %v", err)
+	}
+	if err := operator.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if duration := operatorMetricValue(t, durationMetric); duration != 90 {
+		t.Fatalf("expected %s to remain 90, got %v", durationMetric, duration)
+	}
+}
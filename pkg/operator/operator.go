@@ -3,6 +3,10 @@ package operator
 import (
 	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	logf "github.com/openshift/cluster-ingress-operator/pkg/log"
@@ -15,18 +19,25 @@ import (
 	certpublishercontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/certificate-publisher"
 	dnscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/dns"
 	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+	podhealthcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/pod-health"
 	statuscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/status"
 
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/client-go/rest"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 
+	toolscache "k8s.io/client-go/tools/cache"
+
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -36,6 +47,36 @@ var (
 	log = logf.Logger.WithName("init")
 )
 
+// externalTopologyMode is the ControlPlaneTopology value for clusters whose
+// control plane runs outside the cluster (for example, HyperShift).  The
+// vendored API does not define a constant for this value yet.
+const externalTopologyMode configv1.TopologyMode = "External"
+
+// optionalControllers names the controllers that may be disabled, whether
+// unconditionally via DisabledControllers or on External control-plane
+// topology via ExternalTopologyDisabledControllers.  The canary controller's
+// end-to-end checks, the dns controller's record publishing, and the
+// pod-health controller's readiness gates are each dispensable in specialized
+// deployments (for example, externally-managed DNS).  The ingress, status,
+// certificate, and certificate-publisher controllers are always required and
+// cannot be disabled.
+var optionalControllers = map[string]bool{
+	"canary":     true,
+	"dns":        true,
+	"pod-health": true,
+}
+
+// optionalControllerNames returns the names of the controllers that may be
+// disabled, sorted for stable error messages.
+func optionalControllerNames() []string {
+	names := make([]string, 0, len(optionalControllers))
+	for name := range optionalControllers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	// Setup controller-runtime logging
 	logf.SetRuntimeLogger(log)
@@ -51,21 +92,75 @@ type Operator struct {
 	manager manager.Manager
 
 	namespace string
+
+	config operatorconfig.Config
+
+	// infraConfigMu guards infraConfig, which caches the cluster
+	// Infrastructure config for infrastructureConfig.  The periodic ensure
+	// loop and Start run concurrently, so access must be serialized.
+	infraConfigMu sync.Mutex
+	infraConfig   *configv1.Infrastructure
+
+	// ensureTrigger carries requests for an immediate pass of the
+	// default-ingresscontroller ensure logic, outside the periodic loop.
+	// The channel is buffered so that a trigger fired while a pass is
+	// already running queues exactly one follow-up pass.
+	ensureTrigger chan struct{}
+
+	// availableDurationOnce guards the availability-duration metric so
+	// that it is recorded at most once per operator process.  The value
+	// is derived from the ingresscontroller's own timestamps, so repeat
+	// recordings after a restart would produce the same number anyway.
+	availableDurationOnce sync.Once
+}
+
+// infrastructureConfig returns the cluster Infrastructure config, fetching it
+// from the API server on first use and serving it from a cache thereafter.
+// The infrastructure config is effectively immutable post-install, and the
+// operator uses a non-caching client, so re-fetching it on every pass of the
+// periodic ensure loop would add avoidable API load.  Safe for concurrent
+// use.
+func (o *Operator) infrastructureConfig() (*configv1.Infrastructure, error) {
+	o.infraConfigMu.Lock()
+	defer o.infraConfigMu.Unlock()
+	if o.infraConfig != nil {
+		return o.infraConfig, nil
+	}
+	infraConfig := &configv1.Infrastructure{}
+	if err := o.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+		return nil, err
+	}
+	o.infraConfig = infraConfig
+	return infraConfig, nil
 }
 
 // New creates (but does not start) a new operator from configuration.
 func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, error) {
+	if err := applyClientRateLimits(kubeConfig, config); err != nil {
+		return nil, fmt.Errorf("failed to configure client rate limits: %v", err)
+	}
+	if _, err := effectiveReconcileInterval(config.ReconcileInterval); err != nil {
+		return nil, fmt.Errorf("invalid reconcile interval: %v", err)
+	}
+	if _, err := effectiveReconcileJitter(config.ReconcileJitter); err != nil {
+		return nil, fmt.Errorf("invalid reconcile jitter: %v", err)
+	}
+	syncPeriod, err := effectiveCacheSyncPeriod(config.CacheSyncPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache sync period: %v", err)
+	}
+	namespaces, err := watchedNamespaces(config)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("watching namespaces", "namespaces", strings.Join(namespaces, ","))
 	scheme := operatorclient.GetScheme()
 	// Set up an operator manager for the operator namespace.
 	mgr, err := manager.New(kubeConfig, manager.Options{
-		Namespace: config.Namespace,
-		Scheme:    scheme,
-		NewCache: cache.MultiNamespacedCacheBuilder([]string{
-			config.Namespace,
-			operatorcontroller.DefaultOperandNamespace,
-			operatorcontroller.DefaultCanaryNamespace,
-			operatorcontroller.GlobalMachineSpecifiedConfigNamespace,
-		}),
+		Namespace:  config.Namespace,
+		Scheme:     scheme,
+		NewCache:   cache.MultiNamespacedCacheBuilder(namespaces),
+		SyncPeriod: &syncPeriod,
 		// Use a non-caching client everywhere. The default split client does not
 		// promise to invalidate the cache during writes (nor does it promise
 		// sequential create/get coherence), and we have code which (probably
@@ -73,33 +168,69 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 		// return the updated resource. All client consumers will need audited to
 		// ensure they are tolerant of stale data (or we need a cache or client that
 		// makes stronger coherence guarantees).
+		//
+		// The client negotiates protobuf for the built-in kube groups
+		// that support it to reduce API server load and bandwidth.
 		NewClient: func(_ cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
-			return client.New(config, options)
+			return operatorclient.NewProtobufAwareClient(config, options)
 		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create operator manager: %v", err)
 	}
 
+	// Determine the control-plane topology so that controllers that do not
+	// apply on External topology can be disabled.  The manager's cache has
+	// not started yet, so use a direct client for this read.
+	topologyClient, err := client.New(kubeConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %v", err)
+	}
+	infraConfig := &configv1.Infrastructure{}
+	if err := topologyClient.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure 'cluster': %v", err)
+	}
+	disabled, err := disabledControllers(config, infraConfig.Status.ControlPlaneTopology)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create and register the ingress controller with the operator manager.
 	if _, err := ingresscontroller.New(mgr, ingresscontroller.Config{
-		Namespace:              config.Namespace,
-		IngressControllerImage: config.IngressControllerImage,
+		Namespace:                config.Namespace,
+		IngressControllerImage:   config.IngressControllerImage,
+		RouteMetricsChunkSize:    config.RouteMetricsChunkSize,
+		AdditionalOwnerReference: config.AdditionalOwnerReference,
+		MinimumTLSVersion:        configv1.TLSProtocolVersion(config.MinimumTLSVersion),
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create ingress controller: %v", err)
 	}
 
+	// The status controller is read-heavy and only derives status from
+	// watched objects, so it is safe to serve its reads from the manager's
+	// cache.  Write-path controllers (ingress, certificate, dns) must keep
+	// the non-caching client for the coherence reasons described above.
+	cachedReader := operatorclient.NewCachedReader(mgr.GetCache(), mgr.GetClient(),
+		config.Namespace,
+		operatorcontroller.DefaultOperandNamespace,
+		operatorcontroller.DefaultCanaryNamespace,
+	)
+
 	// Set up the status controller.
 	if _, err := statuscontroller.New(mgr, statuscontroller.Config{
 		Namespace:              config.Namespace,
 		IngressControllerImage: config.IngressControllerImage,
 		OperatorReleaseVersion: config.OperatorReleaseVersion,
+		CachedReader:           cachedReader,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create status controller: %v", err)
 	}
 
 	// Set up the certificate controller
-	if _, err := certcontroller.New(mgr, config.Namespace); err != nil {
+	if _, err := certcontroller.New(mgr, certcontroller.Config{
+		Namespace:               config.Namespace,
+		MaxConcurrentReconciles: config.CertificateControllerMaxConcurrentReconciles,
+	}); err != nil {
 		return nil, fmt.Errorf("failed to create cacert controller: %v", err)
 	}
 
@@ -108,20 +239,43 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 		return nil, fmt.Errorf("failed to create certificate-publisher controller: %v", err)
 	}
 
+	// Set up the pod-health controller, which maintains the router-health
+	// readiness-gate condition on router pods that opt in to it.
+	if disabled["pod-health"] {
+		log.Info("controller is disabled; not running it", "controller", "pod-health")
+	} else if _, err := podhealthcontroller.New(mgr, podhealthcontroller.Config{
+		Namespace: operatorcontroller.DefaultOperandNamespace,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create pod-health controller: %v", err)
+	}
+
 	// Set up the DNS controller
-	if _, err := dnscontroller.New(mgr, dnscontroller.Config{
-		Namespace:              config.Namespace,
-		OperatorReleaseVersion: config.OperatorReleaseVersion,
+	if disabled["dns"] {
+		log.Info("controller is disabled; not running it", "controller", "dns")
+	} else if _, err := dnscontroller.New(mgr, dnscontroller.Config{
+		Namespace:               config.Namespace,
+		OperatorReleaseVersion:  config.OperatorReleaseVersion,
+		ResolutionCheckResolver: config.DNSResolutionCheckResolver,
+		ResolutionCheckTimeout:  config.DNSResolutionCheckTimeout,
+		DeletionTimeout:         config.DNSDeletionTimeout,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create dns controller: %v", err)
 	}
 
 	// Set up the canary controller when the config.CanaryImage is not empty
-	if len(config.CanaryImage) != 0 {
+	if disabled["canary"] {
+		log.Info("controller is disabled; not running it", "controller", "canary")
+	} else if len(config.CanaryImage) != 0 {
+		canaryNodeSelector, canaryTolerations := placementForNodeRole(config.CanaryPlacementNodeRole)
 		if _, err := canarycontroller.New(mgr, canarycontroller.Config{
-			Namespace:   config.Namespace,
-			CanaryImage: config.CanaryImage,
-			Stop:        config.Stop,
+			Namespace:              config.Namespace,
+			CanaryImage:            config.CanaryImage,
+			NodeSelector:           canaryNodeSelector,
+			Tolerations:            canaryTolerations,
+			ExternalProbeURL:       config.CanaryExternalProbeURL,
+			RouteTLSTermination:    routev1.TLSTerminationType(config.CanaryRouteTLSTermination),
+			RouteProbeCABundleFile: config.CanaryRouteProbeCABundleFile,
+			Stop:                   config.Stop,
 		}); err != nil {
 			return nil, fmt.Errorf("failed to create canary controller: %v", err)
 		}
@@ -131,17 +285,179 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 		manager: mgr,
 		// TODO: These are only needed for the default ingress controller stuff, which
 		// should be refactored away.
-		client:    mgr.GetClient(),
-		namespace: config.Namespace,
+		client:        mgr.GetClient(),
+		namespace:     config.Namespace,
+		config:        config,
+		ensureTrigger: make(chan struct{}, 1),
 	}, nil
 }
 
+// watchedNamespaces returns the deduplicated list of namespaces that the
+// operator's cache watches: the built-in operator, operand, canary, and
+// global-config namespaces plus any configured additional namespaces.  The
+// additional namespaces are validated so that a typo fails startup rather
+// than silently producing a cache that never syncs.
+func watchedNamespaces(config operatorconfig.Config) ([]string, error) {
+	namespaces := []string{
+		config.Namespace,
+		operatorcontroller.DefaultOperandNamespace,
+		operatorcontroller.DefaultCanaryNamespace,
+		operatorcontroller.GlobalMachineSpecifiedConfigNamespace,
+	}
+	seen := map[string]bool{}
+	for _, namespace := range namespaces {
+		seen[namespace] = true
+	}
+	for _, namespace := range config.AdditionalWatchNamespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid additional watch namespace %q: %s", namespace, strings.Join(errs, "; "))
+		}
+		if seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// disabledControllers returns the set of controllers that should not be run
+// given the configuration and the cluster's control-plane topology.
+// DisabledControllers applies on any topology;
+// ExternalTopologyDisabledControllers applies only when the topology is
+// External.  The configured controller names are validated in both cases so
+// that a misconfiguration does not go unnoticed.
+func disabledControllers(config operatorconfig.Config, topology configv1.TopologyMode) (map[string]bool, error) {
+	disabled := map[string]bool{}
+	for _, name := range config.DisabledControllers {
+		if !optionalControllers[name] {
+			return nil, fmt.Errorf("controller %q cannot be disabled; controllers that may be disabled: %s", name, strings.Join(optionalControllerNames(), ", "))
+		}
+		disabled[name] = true
+	}
+	for _, name := range config.ExternalTopologyDisabledControllers {
+		if !optionalControllers[name] {
+			return nil, fmt.Errorf("controller %q cannot be disabled on External topology; controllers that may be disabled: %s", name, strings.Join(optionalControllerNames(), ", "))
+		}
+		if topology == externalTopologyMode {
+			disabled[name] = true
+		}
+	}
+	return disabled, nil
+}
+
+// defaultReconcileInterval is the interval of the periodic ensure loop when
+// no interval is configured.
+const defaultReconcileInterval = 1 * time.Minute
+
+// minimumReconcileInterval is the shortest configurable reconcile interval;
+// shorter intervals would waste API calls without improving convergence.
+const minimumReconcileInterval = 10 * time.Second
+
+// defaultReconcileJitter is the jitter factor of the periodic ensure loop
+// when none is configured.  A modest factor spreads out the API load from a
+// fleet of operators restarting at the same time (for example, after a
+// coordinated node reboot) without noticeably delaying reconciliation.
+const defaultReconcileJitter = 0.1
+
+// defaultCacheSyncPeriod is the informer resync period of the operator's
+// cache when none is configured.  A resync replays every cached object
+// through the controllers, repairing any state that drifted because a watch
+// event was missed (for example, during an apiserver blip).
+const defaultCacheSyncPeriod = 24 * time.Hour
+
+// minimumCacheSyncPeriod is the shortest configurable cache resync period;
+// a more aggressive period would periodically hammer the apiserver with a
+// full replay of every watched object.
+const minimumCacheSyncPeriod = 10 * time.Minute
+
+// maximumReconcileJitter is the largest configurable jitter factor.  A
+// factor above 1.0 could more than double the effective interval, delaying
+// recovery of the default ingresscontroller.
+const maximumReconcileJitter = 1.0
+
+// effectiveReconcileInterval resolves the configured reconcile interval,
+// applying the default when none is configured and rejecting intervals that
+// are too short.
+func effectiveReconcileInterval(configured time.Duration) (time.Duration, error) {
+	if configured == 0 {
+		return defaultReconcileInterval, nil
+	}
+	if configured < minimumReconcileInterval {
+		return 0, fmt.Errorf("reconcile interval %s is less than the minimum %s", configured, minimumReconcileInterval)
+	}
+	return configured, nil
+}
+
+// effectiveCacheSyncPeriod resolves the configured cache resync period,
+// applying the default when it is zero and rejecting periods below the
+// minimum.
+func effectiveCacheSyncPeriod(configured time.Duration) (time.Duration, error) {
+	if configured == 0 {
+		return defaultCacheSyncPeriod, nil
+	}
+	if configured < minimumCacheSyncPeriod {
+		return 0, fmt.Errorf("cache sync period %s is less than the minimum %s", configured, minimumCacheSyncPeriod)
+	}
+	return configured, nil
+}
+
+// effectiveReconcileJitter resolves the configured reconcile jitter factor,
+// applying the default when it is zero and rejecting values that are
+// negative or above the maximum.  The effective interval of each pass of the
+// periodic ensure loop is the reconcile interval plus a random fraction of
+// it up to the jitter factor.
+func effectiveReconcileJitter(configured float64) (float64, error) {
+	if configured == 0 {
+		return defaultReconcileJitter, nil
+	}
+	if configured < 0 {
+		return 0, fmt.Errorf("reconcile jitter %v must not be negative", configured)
+	}
+	if configured > maximumReconcileJitter {
+		return 0, fmt.Errorf("reconcile jitter %v is greater than the maximum %v", configured, maximumReconcileJitter)
+	}
+	return configured, nil
+}
+
+// applyClientRateLimits validates the client QPS and burst from config and
+// applies them to the given REST config.  Every controller goes through the
+// shared client built from this REST config, so these limits bound the
+// operator's aggregate load on the API server (for example, during the mass
+// reconcile that follows an upgrade).
+func applyClientRateLimits(kubeConfig *rest.Config, config operatorconfig.Config) error {
+	if config.ClientQPS <= 0 {
+		return fmt.Errorf("client QPS must be positive: %v", config.ClientQPS)
+	}
+	if config.ClientBurst < int(config.ClientQPS) {
+		return fmt.Errorf("client burst %d must be at least client QPS %v", config.ClientBurst, config.ClientQPS)
+	}
+	kubeConfig.QPS = config.ClientQPS
+	kubeConfig.Burst = config.ClientBurst
+	return nil
+}
+
 // Start creates the default IngressController and then starts the operator
 // synchronously until a message is received on the stop channel.
 // TODO: Move the default IngressController logic elsewhere.
 func (o *Operator) Start(stop <-chan struct{}) error {
-	// Periodicaly ensure the default controller exists.
-	go wait.Until(func() {
+	// If the canary is not enabled, remove any canary resources that a
+	// previous run of the operator created.
+	if len(o.config.CanaryImage) == 0 {
+		go func() {
+			if err := canarycontroller.EnsureCanaryResourcesRemoved(o.client); err != nil {
+				log.Error(err, "failed to remove canary resources")
+			}
+		}()
+	}
+
+	// Periodicaly ensure the default controller exists.  The interval and
+	// jitter were validated in New, so the errors can be ignored here.
+	// Jittering the interval spreads out the loop's Gets across a fleet of
+	// operators that restarted at the same time.
+	interval, _ := effectiveReconcileInterval(o.config.ReconcileInterval)
+	jitter, _ := effectiveReconcileJitter(o.config.ReconcileJitter)
+	go wait.JitterUntil(func() {
 		if !o.manager.GetCache().WaitForCacheSync(stop) {
 			log.Error(nil, "failed to sync cache before ensuring default ingresscontroller")
 			return
@@ -150,7 +466,37 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 		if err != nil {
 			log.Error(err, "failed to ensure default ingresscontroller")
 		}
-	}, 1*time.Minute, stop)
+		if err := o.ensureTrustedCAConfigMap(); err != nil {
+			log.Error(err, "failed to ensure trusted CA configmap")
+		}
+	}, interval, jitter, true, stop)
+
+	// Watch the default ingresscontroller so that an accidental deletion
+	// is repaired immediately rather than after up to a full reconcile
+	// interval of downtime.  A failure to set up the watch is not fatal;
+	// the periodic loop still recreates the ingresscontroller, just more
+	// slowly.
+	if informer, err := o.manager.GetCache().GetInformer(context.TODO(), &operatorv1.IngressController{}); err != nil {
+		log.Error(err, "failed to get ingresscontroller informer; deleted default ingresscontrollers will be recreated by the periodic loop only")
+	} else {
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			DeleteFunc: func(obj interface{}) {
+				o.notifyDefaultIngressControllerDeleted(obj, stop)
+			},
+		})
+	}
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-o.ensureTrigger:
+				if err := o.ensureDefaultIngressController(); err != nil {
+					log.Error(err, "failed to ensure default ingresscontroller after deletion")
+				}
+			}
+		}
+	}()
 
 	errChan := make(chan error)
 	go func() {
@@ -160,9 +506,61 @@ func (o *Operator) Start(stop <-chan struct{}) error {
 	// Wait for the manager to exit or an explicit stop.
 	select {
 	case <-stop:
-		return nil
+		// Returning as soon as the stop channel closes would let the
+		// process exit while a reconcile is mid-write, occasionally
+		// leaving a partially-updated object behind.  Wait for the
+		// manager to actually return so that in-flight reconciles
+		// drain, but bound the wait so a wedged controller cannot
+		// prevent shutdown.
+		return drainManagerStop(errChan, managerShutdownTimeout)
+	case err := <-errChan:
+		return err
+	}
+}
+
+// notifyDefaultIngressControllerDeleted requests an immediate pass of the
+// default-ingresscontroller ensure logic when the deleted object is the
+// default ingresscontroller.  No trigger is fired once the stop channel has
+// closed: during operator shutdown (for example, uninstall) a deletion may
+// well be intentional, and racing to recreate the object would fight it.
+func (o *Operator) notifyDefaultIngressControllerDeleted(obj interface{}, stop <-chan struct{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ic, ok := obj.(*operatorv1.IngressController)
+	if !ok {
+		return
+	}
+	if ic.Namespace != o.namespace || ic.Name != manifests.DefaultIngressControllerName {
+		return
+	}
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	log.Info("default ingresscontroller was deleted; triggering an immediate recreate", "namespace", ic.Namespace, "name", ic.Name)
+	select {
+	case o.ensureTrigger <- struct{}{}:
+	default:
+		// A trigger is already queued.
+	}
+}
+
+// managerShutdownTimeout bounds how long Start waits after a stop for the
+// manager and its controllers to finish in-flight reconciles.
+const managerShutdownTimeout = 30 * time.Second
+
+// drainManagerStop waits up to timeout for the manager goroutine to return
+// and returns its error.  On timeout it returns nil, matching the previous
+// behavior of not failing shutdown.
+func drainManagerStop(errChan <-chan error, timeout time.Duration) error {
+	select {
 	case err := <-errChan:
 		return err
+	case <-time.After(timeout):
+		log.Info("timed out waiting for the manager to stop; exiting anyway")
+		return nil
 	}
 }
 
@@ -172,36 +570,220 @@ func (o *Operator) ensureDefaultIngressController() error {
 	name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
 	ic := &operatorv1.IngressController{}
 	if err := o.client.Get(context.TODO(), name, ic); err == nil {
+		defaultIngressControllerExists.Set(1)
+		o.recordDefaultIngressControllerAvailableDuration(ic)
+		// An existing default ingresscontroller without the
+		// operator-managed label was created (or recreated) by an
+		// admin.  Leave it alone; do not take ownership by labeling
+		// it after the fact.
+		if ic.Labels[operatorManagedDefaultLabel] != "true" {
+			log.Info("the default ingresscontroller exists but was not created by the operator; leaving it unmanaged", "namespace", ic.Namespace, "name", ic.Name, "label", operatorManagedDefaultLabel)
+		}
 		return nil
 	} else if !errors.IsNotFound(err) {
 		return err
 	}
-	infraConfig := &configv1.Infrastructure{}
-	if err := o.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, infraConfig); err != nil {
+	defaultIngressControllerExists.Set(0)
+	// Refrain from creating the default ingresscontroller while the
+	// cluster ingress domain is empty; a controller created without a
+	// domain would fail admission and leave confusing partial state.  The
+	// periodic ensure loop recovers automatically once the domain is
+	// populated.
+	ingressConfig := &configv1.Ingress{}
+	if err := o.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingressConfig); err != nil {
+		return err
+	}
+	if len(ingressConfig.Spec.Domain) == 0 {
+		log.Info("the cluster ingress config has an empty domain; not creating the default ingresscontroller until spec.domain is set")
+		return nil
+	}
+	infraConfig, err := o.infrastructureConfig()
+	if err != nil {
 		return err
 	}
+	// Record the cluster's IP family on the default ingresscontroller so
+	// that downstream controllers need not re-derive it from the network
+	// config.  A missing network config (as in some test environments)
+	// leaves the annotation unset.
+	ipFamily := ""
+	networkConfig := &configv1.Network{}
+	if err := o.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, networkConfig); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+	} else {
+		ipFamily = ipFamilyForNetworkConfig(networkConfig)
+	}
 	// Set the replicas field to a non-nil value because otherwise its
 	// persisted value will be nil, which causes GETs on the /scale
 	// subresource to fail, which breaks the scaling client.  See also:
 	// https://github.com/kubernetes/kubernetes/pull/75210
-	//
-	// TODO: Set the replicas value to the number of workers.
-	replicas := int32(2)
-	if infraConfig.Status.InfrastructureTopology == configv1.SingleReplicaTopologyMode {
-		replicas = 1
+	workerCount := 0
+	if o.config.DefaultReplicasNodesPerReplica > 0 {
+		nodeList := &corev1.NodeList{}
+		if err := o.client.List(context.TODO(), nodeList, client.MatchingLabels{workerNodeRoleLabel: ""}); err != nil {
+			return fmt.Errorf("failed to list worker nodes: %v", err)
+		}
+		workerCount = len(nodeList.Items)
 	}
+	replicas := determineDefaultIngressControllerReplicas(o.config, infraConfig, workerCount)
 	ic = &operatorv1.IngressController{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name.Name,
 			Namespace: name.Namespace,
+			// Mark the default ingresscontroller as operator-created
+			// so that it can be distinguished from a default that an
+			// admin deleted and recreated by hand.
+			Labels: map[string]string{
+				operatorManagedDefaultLabel: "true",
+			},
 		},
 		Spec: operatorv1.IngressControllerSpec{
+			// Carry the cluster ingress domain explicitly on the
+			// default ingresscontroller so that a customized domain
+			// is visible and stable on the object itself rather
+			// than derived downstream.  The domain was verified to
+			// be non-empty above.
+			Domain:   ingressConfig.Spec.Domain,
 			Replicas: &replicas,
 		},
 	}
+	if len(ipFamily) != 0 {
+		ic.Annotations = map[string]string{ipFamilyAnnotation: ipFamily}
+	}
+	// Apply the configured default node placement, if any.  The default is
+	// only applied at creation time, so placement that a user later sets on
+	// the ingresscontroller is never overwritten.
+	if len(o.config.DefaultPlacementNodeRole) != 0 {
+		ic.Spec.NodePlacement = nodePlacementForNodeRole(o.config.DefaultPlacementNodeRole)
+	}
 	if err := o.client.Create(context.TODO(), ic); err != nil {
+		defaultIngressControllerCreateFailures.Inc()
 		return err
 	}
+	defaultIngressControllerExists.Set(1)
 	log.Info("created default ingresscontroller", "namespace", ic.Namespace, "name", ic.Name)
 	return nil
 }
+
+// workerNodeRoleLabel is the label that identifies worker nodes.
+const workerNodeRoleLabel = "node-role.kubernetes.io/worker"
+
+// operatorManagedDefaultLabel marks the default ingresscontroller as created
+// by the operator, distinguishing it from a default that an admin recreated.
+const operatorManagedDefaultLabel = "ingress.operator.openshift.io/operator-managed-default"
+
+// ipFamilyAnnotation records the cluster's IP family on the default
+// ingresscontroller at creation time.
+const ipFamilyAnnotation = "ingress.operator.openshift.io/ip-family"
+
+// ipFamilyForNetworkConfig derives the cluster's IP family ("IPv4", "IPv6",
+// or "DualStack") from the network config's service networks.  An empty or
+// unparseable service network list yields an empty family.
+func ipFamilyForNetworkConfig(networkConfig *configv1.Network) string {
+	var v4, v6 bool
+	for _, cidr := range networkConfig.Spec.ServiceNetwork {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Info("ignoring unparseable service network CIDR", "cidr", cidr, "error", err.Error())
+			continue
+		}
+		if ip.To4() != nil {
+			v4 = true
+		} else {
+			v6 = true
+		}
+	}
+	switch {
+	case v4 && v6:
+		return "DualStack"
+	case v6:
+		return "IPv6"
+	case v4:
+		return "IPv4"
+	}
+	return ""
+}
+
+// recordDefaultIngressControllerAvailableDuration records how long the
+// default ingresscontroller took to become available after its creation,
+// once its Available status condition is true.  The duration is computed
+// from the ingresscontroller's creation timestamp and the condition's last
+// transition time, so it is stable across operator restarts; the metric is
+// recorded at most once per process.
+func (o *Operator) recordDefaultIngressControllerAvailableDuration(ic *operatorv1.IngressController) {
+	for i := range ic.Status.Conditions {
+		cond := &ic.Status.Conditions[i]
+		if cond.Type != operatorv1.OperatorStatusTypeAvailable || cond.Status != operatorv1.ConditionTrue {
+			continue
+		}
+		duration := cond.LastTransitionTime.Sub(ic.CreationTimestamp.Time)
+		if duration < 0 {
+			return
+		}
+		o.availableDurationOnce.Do(func() {
+			defaultIngressControllerAvailableDuration.Set(duration.Seconds())
+			log.Info("default ingresscontroller became available", "namespace", ic.Namespace, "name", ic.Name, "duration", duration.String())
+		})
+		return
+	}
+}
+
+// determineDefaultIngressControllerReplicas returns the replica count for the
+// default ingresscontroller at creation time.  The baseline is two replicas,
+// or one on single-replica infrastructure topology.  When replica scaling is
+// configured, the count grows with the number of worker nodes (one replica
+// per DefaultReplicasNodesPerReplica nodes, rounded up), never dropping below
+// the baseline and capped at DefaultReplicasMaximum when that is set.
+// Single-replica topology is never scaled up.
+func determineDefaultIngressControllerReplicas(config operatorconfig.Config, infraConfig *configv1.Infrastructure, workerCount int) int32 {
+	replicas := int32(2)
+	if infraConfig.Status.InfrastructureTopology == configv1.SingleReplicaTopologyMode {
+		return 1
+	}
+	if config.DefaultReplicasNodesPerReplica <= 0 {
+		return replicas
+	}
+	scaled := int32((workerCount + config.DefaultReplicasNodesPerReplica - 1) / config.DefaultReplicasNodesPerReplica)
+	if scaled > replicas {
+		replicas = scaled
+	}
+	if max := int32(config.DefaultReplicasMaximum); max > 0 && replicas > max {
+		replicas = max
+	}
+	return replicas
+}
+
+// placementForNodeRole returns a node selector and tolerations that direct
+// pods to nodes with the given role label, tolerating the taint
+// conventionally applied to such nodes.  An empty role returns nil selector
+// and tolerations, leaving placement at its defaults.
+func placementForNodeRole(role string) (map[string]string, []corev1.Toleration) {
+	if len(role) == 0 {
+		return nil, nil
+	}
+	label := fmt.Sprintf("node-role.kubernetes.io/%s", role)
+	return map[string]string{label: ""}, []corev1.Toleration{{
+		Key:      label,
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}}
+}
+
+// nodePlacementForNodeRole returns a node placement that directs router pods
+// to nodes with the given role label (for example,
+// node-role.kubernetes.io/infra), tolerating the taint conventionally applied
+// to such nodes.
+func nodePlacementForNodeRole(role string) *operatorv1.NodePlacement {
+	label := fmt.Sprintf("node-role.kubernetes.io/%s", role)
+	return &operatorv1.NodePlacement{
+		NodeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{label: ""},
+		},
+		Tolerations: []corev1.Toleration{{
+			Key:      label,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}},
+	}
+}
@@ -0,0 +1,931 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	iov1 "github.com/openshift/api/operatoringress/v1"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"k8s.io/client-go/rest"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyClientRateLimits(t *testing.T) {
+	testCases := []struct {
+		description   string
+		qps           float32
+		burst         int
+		expectError   bool
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		{
+			description:   "default rate limits",
+			qps:           20,
+			burst:         40,
+			expectedQPS:   20,
+			expectedBurst: 40,
+		},
+		{
+			description:   "custom rate limits",
+			qps:           50,
+			burst:         100,
+			expectedQPS:   50,
+			expectedBurst: 100,
+		},
+		{
+			description: "zero QPS is invalid",
+			qps:         0,
+			burst:       40,
+			expectError: true,
+		},
+		{
+			description: "negative QPS is invalid",
+			qps:         -1,
+			burst:       40,
+			expectError: true,
+		},
+		{
+			description: "burst less than QPS is invalid",
+			qps:         20,
+			burst:       10,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		kubeConfig := &rest.Config{}
+		config := operatorconfig.Config{
+			ClientQPS:   tc.qps,
+			ClientBurst: tc.burst,
+		}
+		err := applyClientRateLimits(kubeConfig, config)
+		switch {
+		case err != nil && !tc.expectError:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case err == nil && tc.expectError:
+			t.Errorf("%q: expected error, got none", tc.description)
+		case err == nil:
+			if kubeConfig.QPS != tc.expectedQPS {
+				t.Errorf("%q: expected QPS %v, got %v", tc.description, tc.expectedQPS, kubeConfig.QPS)
+			}
+			if kubeConfig.Burst != tc.expectedBurst {
+				t.Errorf("%q: expected burst %d, got %d", tc.description, tc.expectedBurst, kubeConfig.Burst)
+			}
+		}
+	}
+}
+
+func TestEffectiveReconcileInterval(t *testing.T) {
+	testCases := []struct {
+		description      string
+		configured       time.Duration
+		expectError      bool
+		expectedInterval time.Duration
+	}{
+		{
+			description:      "zero defaults to 1 minute",
+			configured:       0,
+			expectedInterval: 1 * time.Minute,
+		},
+		{
+			description:      "custom interval",
+			configured:       5 * time.Minute,
+			expectedInterval: 5 * time.Minute,
+		},
+		{
+			description:      "minimum interval",
+			configured:       10 * time.Second,
+			expectedInterval: 10 * time.Second,
+		},
+		{
+			description: "interval below the minimum is invalid",
+			configured:  5 * time.Second,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		interval, err := effectiveReconcileInterval(tc.configured)
+		switch {
+		case err != nil && !tc.expectError:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case err == nil && tc.expectError:
+			t.Errorf("%q: expected error, got none", tc.description)
+		case err == nil:
+			if interval != tc.expectedInterval {
+				t.Errorf("%q: expected interval %s, got %s", tc.description, tc.expectedInterval, interval)
+			}
+		}
+	}
+}
+
+func TestEnsureDefaultIngressControllerEmptyDomain(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	ingressConfig := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig)
+	o := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+	}
+	if err := o.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
+	if err := client.Get(context.TODO(), name, &operatorv1.IngressController{}); err == nil {
+		t.Fatal("expected no default ingresscontroller to be created while the ingress domain is empty")
+	}
+
+	// Once the domain is populated, the next ensure creates the default
+	// ingresscontroller.
+	ingressConfig.Spec.Domain = "apps.openshift.example.com"
+	if err := client.Update(context.TODO(), ingressConfig); err != nil {
+		t.Fatalf("failed to update ingress config: %v", err)
+	}
+	if err := o.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ic := &operatorv1.IngressController{}
+	if err := client.Get(context.TODO(), name, ic); err != nil {
+		t.Fatalf("expected default ingresscontroller to be created once the domain is set: %v", err)
+	}
+	if ic.Spec.Domain != ingressConfig.Spec.Domain {
+		t.Fatalf("expected the default ingresscontroller to carry the cluster ingress domain %q, got %q", ingressConfig.Spec.Domain, ic.Spec.Domain)
+	}
+}
+
+func TestEnsureDefaultIngressControllerNodePlacement(t *testing.T) {
+	testCases := []struct {
+		description     string
+		nodeRole        string
+		expectPlacement bool
+	}{
+		{
+			description:     "no node role configured",
+			nodeRole:        "",
+			expectPlacement: false,
+		},
+		{
+			description:     "infra node role configured",
+			nodeRole:        "infra",
+			expectPlacement: true,
+		},
+	}
+	for _, tc := range testCases {
+		infraConfig := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		}
+		ingressConfig := &configv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+		}
+		client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig)
+		o := &Operator{
+			client:    client,
+			namespace: "openshift-ingress-operator",
+			config: operatorconfig.Config{
+				DefaultPlacementNodeRole: tc.nodeRole,
+			},
+		}
+		if err := o.ensureDefaultIngressController(); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		ic := &operatorv1.IngressController{}
+		name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
+		if err := client.Get(context.TODO(), name, ic); err != nil {
+			t.Errorf("%q: failed to get default ingresscontroller: %v", tc.description, err)
+			continue
+		}
+		if !tc.expectPlacement {
+			if ic.Spec.NodePlacement != nil {
+				t.Errorf("%q: unexpected node placement: %+v", tc.description, ic.Spec.NodePlacement)
+			}
+			continue
+		}
+		if ic.Spec.NodePlacement == nil || ic.Spec.NodePlacement.NodeSelector == nil {
+			t.Errorf("%q: expected node placement to be set", tc.description)
+			continue
+		}
+		label := "node-role.kubernetes.io/" + tc.nodeRole
+		if _, ok := ic.Spec.NodePlacement.NodeSelector.MatchLabels[label]; !ok {
+			t.Errorf("%q: expected node selector for label %q, got %+v", tc.description, label, ic.Spec.NodePlacement.NodeSelector)
+		}
+		if len(ic.Spec.NodePlacement.Tolerations) != 1 || ic.Spec.NodePlacement.Tolerations[0].Key != label {
+			t.Errorf("%q: expected a toleration for %q, got %+v", tc.description, label, ic.Spec.NodePlacement.Tolerations)
+		}
+	}
+}
+
+func TestInventory(t *testing.T) {
+	objects := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-default"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-internal-default"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config-managed", Name: "default-ingress-cert"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress", Name: "router-certs-default"}},
+		&iov1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-ingress-operator", Name: "default-wildcard"}},
+		// Objects outside the managed namespaces must not appear in the
+		// inventory.
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unrelated"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "unrelated"}},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), objects...)
+	operator := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+	}
+	inventory, err := operator.Inventory()
+	if err != nil {
+		t.Fatalf("failed to get inventory: %v", err)
+	}
+	expected := map[string]bool{
+		"Deployment/openshift-ingress/router-default":             false,
+		"Service/openshift-ingress/router-internal-default":       false,
+		"ConfigMap/openshift-config-managed/default-ingress-cert": false,
+		"Secret/openshift-ingress/router-certs-default":           false,
+		"DNSRecord/openshift-ingress-operator/default-wildcard":   false,
+	}
+	for _, obj := range inventory.Objects {
+		key := fmt.Sprintf("%s/%s/%s", obj.Kind, obj.Namespace, obj.Name)
+		if _, ok := expected[key]; !ok {
+			t.Errorf("unexpected inventory object %q", key)
+			continue
+		}
+		expected[key] = true
+	}
+	for key, found := range expected {
+		if !found {
+			t.Errorf("expected inventory object %q, got none", key)
+		}
+	}
+}
+
+func TestDisabledControllers(t *testing.T) {
+	testCases := []struct {
+		description      string
+		disabled         []string
+		topologyDisabled []string
+		topology         configv1.TopologyMode
+		expectError      bool
+		expectedDisabled []string
+	}{
+		{
+			description: "nothing configured",
+			topology:    configv1.HighlyAvailableTopologyMode,
+		},
+		{
+			description:      "controllers disabled on any topology",
+			disabled:         []string{"dns"},
+			topology:         configv1.HighlyAvailableTopologyMode,
+			expectedDisabled: []string{"dns"},
+		},
+		{
+			description: "required controller cannot be disabled on any topology",
+			disabled:    []string{"status"},
+			topology:    configv1.HighlyAvailableTopologyMode,
+			expectError: true,
+		},
+		{
+			description:      "unconditional and topology-conditional disablement combine",
+			disabled:         []string{"canary"},
+			topologyDisabled: []string{"dns"},
+			topology:         externalTopologyMode,
+			expectedDisabled: []string{"canary", "dns"},
+		},
+		{
+			description:      "optional controllers disabled on External topology",
+			topologyDisabled: []string{"canary", "dns"},
+			topology:         externalTopologyMode,
+			expectedDisabled: []string{"canary", "dns"},
+		},
+		{
+			description:      "topology-conditional configuration is inert when topology is not External",
+			topologyDisabled: []string{"canary", "dns", "pod-health"},
+			topology:         configv1.HighlyAvailableTopologyMode,
+		},
+		{
+			description:      "required controller cannot be disabled on External topology",
+			topologyDisabled: []string{"ingress"},
+			topology:         externalTopologyMode,
+			expectError:      true,
+		},
+		{
+			description:      "unknown controller name is rejected on any topology",
+			topologyDisabled: []string{"bogus"},
+			topology:         configv1.HighlyAvailableTopologyMode,
+			expectError:      true,
+		},
+	}
+	for _, tc := range testCases {
+		config := operatorconfig.Config{
+			DisabledControllers:                 tc.disabled,
+			ExternalTopologyDisabledControllers: tc.topologyDisabled,
+		}
+		disabled, err := disabledControllers(config, tc.topology)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if len(disabled) != len(tc.expectedDisabled) {
+			t.Errorf("%q: expected %d disabled controllers, got %d", tc.description, len(tc.expectedDisabled), len(disabled))
+			continue
+		}
+		for _, name := range tc.expectedDisabled {
+			if !disabled[name] {
+				t.Errorf("%q: expected controller %q to be disabled", tc.description, name)
+			}
+		}
+	}
+}
+
+func TestWatchedNamespaces(t *testing.T) {
+	builtin := []string{
+		"openshift-ingress-operator",
+		"openshift-ingress",
+		"openshift-ingress-canary",
+		"openshift-config-managed",
+	}
+	testCases := []struct {
+		description string
+		additional  []string
+		expected    []string
+		expectError bool
+	}{
+		{
+			description: "no additional namespaces",
+			expected:    builtin,
+		},
+		{
+			description: "additional namespaces are appended",
+			additional:  []string{"custom-routers", "more-routers"},
+			expected:    append(append([]string{}, builtin...), "custom-routers", "more-routers"),
+		},
+		{
+			description: "built-in and repeated namespaces are included once",
+			additional:  []string{"openshift-ingress", "custom-routers", "custom-routers"},
+			expected:    append(append([]string{}, builtin...), "custom-routers"),
+		},
+		{
+			description: "empty namespace is rejected",
+			additional:  []string{""},
+			expectError: true,
+		},
+		{
+			description: "invalid namespace is rejected",
+			additional:  []string{"Not_A_Label"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		config := operatorconfig.Config{
+			Namespace:                 "openshift-ingress-operator",
+			AdditionalWatchNamespaces: tc.additional,
+		}
+		namespaces, err := watchedNamespaces(config)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected error, got none", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if !reflect.DeepEqual(namespaces, tc.expected) {
+			t.Errorf("%q: expected namespaces %v, got %v", tc.description, tc.expected, namespaces)
+		}
+	}
+}
+
+func TestDetermineDefaultIngressControllerReplicas(t *testing.T) {
+	testCases := []struct {
+		description     string
+		nodesPerReplica int
+		maximum         int
+		topology        configv1.TopologyMode
+		workerCount     int
+		expected        int32
+	}{
+		{
+			description: "scaling disabled",
+			workerCount: 100,
+			expected:    2,
+		},
+		{
+			description: "single-replica topology",
+			topology:    configv1.SingleReplicaTopologyMode,
+			expected:    1,
+		},
+		{
+			description:     "single-replica topology is never scaled up",
+			nodesPerReplica: 1,
+			topology:        configv1.SingleReplicaTopologyMode,
+			workerCount:     10,
+			expected:        1,
+		},
+		{
+			description:     "small cluster keeps the baseline",
+			nodesPerReplica: 10,
+			workerCount:     3,
+			expected:        2,
+		},
+		{
+			description:     "scaled count rounds up",
+			nodesPerReplica: 10,
+			workerCount:     31,
+			expected:        4,
+		},
+		{
+			description:     "maximum caps the scaled count",
+			nodesPerReplica: 10,
+			maximum:         5,
+			workerCount:     100,
+			expected:        5,
+		},
+		{
+			description:     "no workers keeps the baseline",
+			nodesPerReplica: 10,
+			workerCount:     0,
+			expected:        2,
+		},
+	}
+	for _, tc := range testCases {
+		config := operatorconfig.Config{
+			DefaultReplicasNodesPerReplica: tc.nodesPerReplica,
+			DefaultReplicasMaximum:         tc.maximum,
+		}
+		infraConfig := &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				InfrastructureTopology: tc.topology,
+			},
+		}
+		if actual := determineDefaultIngressControllerReplicas(config, infraConfig, tc.workerCount); actual != tc.expected {
+			t.Errorf("%q: expected %d replicas, got %d", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestDrainManagerStop(t *testing.T) {
+	// The manager goroutine finishing releases the wait and its error is
+	// returned.
+	errChan := make(chan error)
+	returned := make(chan error)
+	go func() {
+		returned <- drainManagerStop(errChan, time.Minute)
+	}()
+	select {
+	case <-returned:
+		t.Fatal("drainManagerStop returned before the manager goroutine completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	managerErr := fmt.Errorf("manager stopped")
+	errChan <- managerErr
+	select {
+	case err := <-returned:
+		if err != managerErr {
+			t.Fatalf("expected the manager's error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("drainManagerStop did not return after the manager goroutine completed")
+	}
+
+	// A manager that never returns does not block shutdown past the
+	// timeout.
+	if err := drainManagerStop(make(chan error), 10*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error on timeout: %v", err)
+	}
+}
+
+func TestPlacementForNodeRole(t *testing.T) {
+	selector, tolerations := placementForNodeRole("")
+	if selector != nil || tolerations != nil {
+		t.Errorf("expected no placement for an empty role, got selector %v and tolerations %v", selector, tolerations)
+	}
+	selector, tolerations = placementForNodeRole("master")
+	label := "node-role.kubernetes.io/master"
+	if _, ok := selector[label]; !ok || len(selector) != 1 {
+		t.Errorf("expected node selector for label %q, got %v", label, selector)
+	}
+	if len(tolerations) != 1 || tolerations[0].Key != label || tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+		t.Errorf("expected a NoSchedule toleration for %q, got %v", label, tolerations)
+	}
+}
+
+// countingGetClient counts Gets of the Infrastructure config.
+type countingGetClient struct {
+	crclient.Client
+	infraGets int
+}
+
+func (c *countingGetClient) Get(ctx context.Context, key crclient.ObjectKey, obj runtime.Object) error {
+	if _, ok := obj.(*configv1.Infrastructure); ok {
+		c.infraGets++
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestInfrastructureConfigCached(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	ingressConfig := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+	}
+	client := &countingGetClient{
+		Client: fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig),
+	}
+	o := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := o.infrastructureConfig(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if client.infraGets != 1 {
+		t.Errorf("expected 1 infrastructure get after repeated accessor calls, got %d", client.infraGets)
+	}
+	// The periodic ensure loop goes through the accessor too.
+	for i := 0; i < 2; i++ {
+		if err := o.ensureDefaultIngressController(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if client.infraGets != 1 {
+		t.Errorf("expected the ensure loop to reuse the cached infrastructure config, got %d gets", client.infraGets)
+	}
+}
+
+func TestEnsureDefaultIngressControllerManagedLabel(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	ingressConfig := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig)
+	o := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+	}
+	if err := o.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ic := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
+	if err := client.Get(context.TODO(), name, ic); err != nil {
+		t.Fatalf("failed to get default ingresscontroller: %v", err)
+	}
+	if ic.Labels[operatorManagedDefaultLabel] != "true" {
+		t.Fatalf("expected the created default ingresscontroller to carry the %s label, got labels %v", operatorManagedDefaultLabel, ic.Labels)
+	}
+
+	// An existing default without the label is left alone.
+	ic.Labels = nil
+	if err := client.Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingresscontroller: %v", err)
+	}
+	if err := o.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get(context.TODO(), name, ic); err != nil {
+		t.Fatalf("failed to get default ingresscontroller: %v", err)
+	}
+	if len(ic.Labels) != 0 {
+		t.Fatalf("expected the operator not to take ownership of an unlabeled default ingresscontroller, got labels %v", ic.Labels)
+	}
+}
+
+func TestIPFamilyForNetworkConfig(t *testing.T) {
+	testCases := []struct {
+		description     string
+		serviceNetworks []string
+		expected        string
+	}{
+		{
+			description:     "single-stack IPv4",
+			serviceNetworks: []string{"172.30.0.0/16"},
+			expected:        "IPv4",
+		},
+		{
+			description:     "single-stack IPv6",
+			serviceNetworks: []string{"fd02::/112"},
+			expected:        "IPv6",
+		},
+		{
+			description:     "dual-stack",
+			serviceNetworks: []string{"172.30.0.0/16", "fd02::/112"},
+			expected:        "DualStack",
+		},
+		{
+			description:     "unparseable CIDRs are ignored",
+			serviceNetworks: []string{"bogus", "fd02::/112"},
+			expected:        "IPv6",
+		},
+		{
+			description: "no service networks",
+			expected:    "",
+		},
+	}
+	for _, tc := range testCases {
+		networkConfig := &configv1.Network{
+			Spec: configv1.NetworkSpec{ServiceNetwork: tc.serviceNetworks},
+		}
+		if actual := ipFamilyForNetworkConfig(networkConfig); actual != tc.expected {
+			t.Errorf("%q: expected IP family %q, got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestEnsureDefaultIngressControllerIPFamilyAnnotation(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	ingressConfig := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+	}
+	networkConfig := &configv1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"}},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig, networkConfig)
+	o := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+	}
+	if err := o.ensureDefaultIngressController(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ic := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
+	if err := client.Get(context.TODO(), name, ic); err != nil {
+		t.Fatalf("failed to get default ingresscontroller: %v", err)
+	}
+	if ic.Annotations[ipFamilyAnnotation] != "DualStack" {
+		t.Fatalf("expected the %s annotation to be %q, got annotations %v", ipFamilyAnnotation, "DualStack", ic.Annotations)
+	}
+}
+
+func TestEffectiveReconcileJitter(t *testing.T) {
+	testCases := []struct {
+		description string
+		configured  float64
+		expected    float64
+		expectError bool
+	}{
+		{
+			description: "zero means the default",
+			configured:  0,
+			expected:    0.1,
+		},
+		{
+			description: "explicit factor",
+			configured:  0.5,
+			expected:    0.5,
+		},
+		{
+			description: "the maximum is allowed",
+			configured:  1.0,
+			expected:    1.0,
+		},
+		{
+			description: "negative factor is rejected",
+			configured:  -0.1,
+			expectError: true,
+		},
+		{
+			description: "factor above the maximum is rejected",
+			configured:  1.5,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		jitter, err := effectiveReconcileJitter(tc.configured)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected an error, got jitter %v", tc.description, jitter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if jitter != tc.expected {
+			t.Errorf("%q: expected jitter %v, got %v", tc.description, tc.expected, jitter)
+		}
+	}
+}
+
+// TestJitteredReconcileIntervalBounds documents the contract of the jittered
+// wait that the periodic ensure loop relies on: each pass waits the interval
+// plus a random fraction of it up to the jitter factor, never less.
+func TestJitteredReconcileIntervalBounds(t *testing.T) {
+	const (
+		interval = 1 * time.Minute
+		jitter   = 0.1
+	)
+	maximum := interval + time.Duration(jitter*float64(interval))
+	for i := 0; i < 100; i++ {
+		jittered := wait.Jitter(interval, jitter)
+		if jittered < interval || jittered > maximum {
+			t.Fatalf("iteration %d: jittered interval %s is outside [%s, %s]", i, jittered, interval, maximum)
+		}
+	}
+}
+
+func TestNotifyDefaultIngressControllerDeleted(t *testing.T) {
+	defaultIC := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      manifests.DefaultIngressControllerName,
+		},
+	}
+	closedStop := make(chan struct{})
+	close(closedStop)
+	testCases := []struct {
+		description   string
+		obj           interface{}
+		stop          chan struct{}
+		expectTrigger bool
+	}{
+		{
+			description:   "deleting the default ingresscontroller triggers a recreate",
+			obj:           defaultIC.DeepCopy(),
+			expectTrigger: true,
+		},
+		{
+			description: "deleting the default ingresscontroller as a tombstone triggers a recreate",
+			obj: toolscache.DeletedFinalStateUnknown{
+				Key: "openshift-ingress-operator/default",
+				Obj: defaultIC.DeepCopy(),
+			},
+			expectTrigger: true,
+		},
+		{
+			description: "deleting another ingresscontroller does not trigger a recreate",
+			obj: &operatorv1.IngressController{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "openshift-ingress-operator",
+					Name:      "internal",
+				},
+			},
+		},
+		{
+			description: "a non-ingresscontroller object does not trigger a recreate",
+			obj:         &corev1.ConfigMap{},
+		},
+		{
+			description: "no recreate is triggered while the operator is stopping",
+			obj:         defaultIC.DeepCopy(),
+			stop:        closedStop,
+		},
+	}
+	for _, tc := range testCases {
+		o := &Operator{
+			namespace:     "openshift-ingress-operator",
+			ensureTrigger: make(chan struct{}, 1),
+		}
+		var stop chan struct{}
+		if tc.stop != nil {
+			stop = tc.stop
+		} else {
+			stop = make(chan struct{})
+		}
+		o.notifyDefaultIngressControllerDeleted(tc.obj, stop)
+		triggered := false
+		select {
+		case <-o.ensureTrigger:
+			triggered = true
+		default:
+		}
+		if triggered != tc.expectTrigger {
+			t.Errorf("%q: expected trigger %t, got %t", tc.description, tc.expectTrigger, triggered)
+		}
+	}
+}
+
+func TestDeletedDefaultIngressControllerIsRecreated(t *testing.T) {
+	infraConfig := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	ingressConfig := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), infraConfig, ingressConfig)
+	o := &Operator{
+		client:        client,
+		namespace:     "openshift-ingress-operator",
+		ensureTrigger: make(chan struct{}, 1),
+	}
+	deleted := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.namespace,
+			Name:      manifests.DefaultIngressControllerName,
+		},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	o.notifyDefaultIngressControllerDeleted(deleted, stop)
+	select {
+	case <-o.ensureTrigger:
+		if err := o.ensureDefaultIngressController(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	default:
+		t.Fatal("expected a trigger after the default ingresscontroller was deleted")
+	}
+	ic := &operatorv1.IngressController{}
+	name := types.NamespacedName{Namespace: o.namespace, Name: manifests.DefaultIngressControllerName}
+	if err := client.Get(context.TODO(), name, ic); err != nil {
+		t.Fatalf("expected the default ingresscontroller to be recreated: %v", err)
+	}
+}
+
+func TestEffectiveCacheSyncPeriod(t *testing.T) {
+	testCases := []struct {
+		description string
+		configured  time.Duration
+		expected    time.Duration
+		expectError bool
+	}{
+		{
+			description: "zero means the default",
+			configured:  0,
+			expected:    24 * time.Hour,
+		},
+		{
+			description: "explicit period",
+			configured:  1 * time.Hour,
+			expected:    1 * time.Hour,
+		},
+		{
+			description: "the minimum is allowed",
+			configured:  10 * time.Minute,
+			expected:    10 * time.Minute,
+		},
+		{
+			description: "a period below the minimum is rejected",
+			configured:  1 * time.Minute,
+			expectError: true,
+		},
+		{
+			description: "a negative period is rejected",
+			configured:  -1 * time.Hour,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		period, err := effectiveCacheSyncPeriod(tc.configured)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%q: expected an error, got period %s", tc.description, period)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if period != tc.expected {
+			t.Errorf("%q: expected period %s, got %s", tc.description, tc.expected, period)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// preflightResource identifies an API resource that the operator's controllers
+// require to be registered with the API server.
+type preflightResource struct {
+	groupVersion string
+	resource     string
+}
+
+// preflightAccess identifies a verb that the operator's service account must be
+// allowed to perform on a resource.
+type preflightAccess struct {
+	group    string
+	resource string
+	verb     string
+}
+
+// preflightResources lists the resources the controllers watch or manage.  A
+// missing entry means a CRD the operator depends on is not installed, which
+// would otherwise only surface as reconcile-time log spam.
+var preflightResources = []preflightResource{
+	{"operator.openshift.io/v1", "ingresscontrollers"},
+	{"ingress.operator.openshift.io/v1", "dnsrecords"},
+	{"config.openshift.io/v1", "ingresses"},
+	{"config.openshift.io/v1", "infrastructures"},
+	{"config.openshift.io/v1", "dnses"},
+	{"config.openshift.io/v1", "proxies"},
+	{"route.openshift.io/v1", "routes"},
+	{"apps/v1", "deployments"},
+	{"v1", "services"},
+	{"v1", "configmaps"},
+	{"v1", "secrets"},
+	{"v1", "pods"},
+}
+
+// preflightAccessChecks lists representative verbs the controllers need.  The
+// list is not exhaustive; it covers the write paths whose failure is hardest
+// to diagnose from logs alone.
+var preflightAccessChecks = []preflightAccess{
+	{"operator.openshift.io", "ingresscontrollers", "update"},
+	{"operator.openshift.io", "ingresscontrollers/status", "update"},
+	{"ingress.operator.openshift.io", "dnsrecords", "create"},
+	{"route.openshift.io", "routes", "create"},
+	{"apps", "deployments", "create"},
+	{"", "services", "create"},
+	{"", "configmaps", "update"},
+	{"", "secrets", "get"},
+	{"", "pods", "list"},
+}
+
+// serverResourcesLister is the subset of the discovery interface that the
+// preflight checks use.  It is implemented by discovery.DiscoveryInterface.
+type serverResourcesLister interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// accessReviewer is the subset of the self-subject access review client that
+// the preflight checks use.
+type accessReviewer interface {
+	Create(ctx context.Context, sar *authorizationv1.SelfSubjectAccessReview, opts metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error)
+}
+
+// Preflight verifies that every API resource the operator's controllers
+// require is registered with the API server and that the operator's service
+// account is allowed to perform the verbs the controllers need.  The returned
+// error aggregates every missing prerequisite so that a misconfigured cluster
+// can be fixed in one pass rather than one failure at a time.
+func Preflight(kubeConfig *rest.Config) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %v", err)
+	}
+	return preflight(discoveryClient, kubeClient.AuthorizationV1().SelfSubjectAccessReviews())
+}
+
+// preflight runs the preflight checks using the given clients and aggregates
+// the failures.
+func preflight(lister serverResourcesLister, reviewer accessReviewer) error {
+	var errs []error
+	missing, err := missingResources(lister, preflightResources)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, resource := range missing {
+		errs = append(errs, fmt.Errorf("required resource %s is not registered with the API server", resource))
+	}
+	denied, err := deniedAccess(reviewer, preflightAccessChecks)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, access := range denied {
+		errs = append(errs, fmt.Errorf("the operator's service account cannot %s", access))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// missingResources returns a description of each required resource that the
+// API server does not report.  A group-version that is entirely absent (for
+// example, an uninstalled CRD's group) counts every required resource in it
+// as missing.
+func missingResources(lister serverResourcesLister, required []preflightResource) ([]string, error) {
+	lists := map[string]*metav1.APIResourceList{}
+	var missing []string
+	for _, req := range required {
+		list, ok := lists[req.groupVersion]
+		if !ok {
+			var err error
+			list, err = lister.ServerResourcesForGroupVersion(req.groupVersion)
+			if err != nil {
+				if !errors.IsNotFound(err) {
+					return missing, fmt.Errorf("failed to discover resources in %s: %v", req.groupVersion, err)
+				}
+				list = &metav1.APIResourceList{}
+			}
+			lists[req.groupVersion] = list
+		}
+		found := false
+		for i := range list.APIResources {
+			if list.APIResources[i].Name == req.resource {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("%s in %s", req.resource, req.groupVersion))
+		}
+	}
+	return missing, nil
+}
+
+// deniedAccess returns a description of each required verb that a
+// self-subject access review reports is not allowed.
+func deniedAccess(reviewer accessReviewer, required []preflightAccess) ([]string, error) {
+	var denied []string
+	for _, req := range required {
+		sar := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    req.group,
+					Resource: req.resource,
+					Verb:     req.verb,
+				},
+			},
+		}
+		result, err := reviewer.Create(context.TODO(), sar, metav1.CreateOptions{})
+		if err != nil {
+			return denied, fmt.Errorf("failed to review access to %s %s in group %q: %v", req.verb, req.resource, req.group, err)
+		}
+		if !result.Status.Allowed {
+			denied = append(denied, fmt.Sprintf("%s %s in group %q", req.verb, req.resource, req.group))
+		}
+	}
+	return denied, nil
+}
@@ -0,0 +1,118 @@
+package operator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fakeServerResourcesLister reports the resources in resources and a NotFound
+// error for any other group-version.
+type fakeServerResourcesLister struct {
+	resources map[string][]string
+}
+
+func (l *fakeServerResourcesLister) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	names, ok := l.resources[groupVersion]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Resource: "groupversions"}, groupVersion)
+	}
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, name := range names {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: name})
+	}
+	return list, nil
+}
+
+// fakeAccessReviewer denies the verbs in denied ("verb group/resource") and
+// allows everything else.
+type fakeAccessReviewer struct {
+	denied map[string]bool
+}
+
+func (r *fakeAccessReviewer) Create(ctx context.Context, sar *authorizationv1.SelfSubjectAccessReview, opts metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error) {
+	attrs := sar.Spec.ResourceAttributes
+	result := sar.DeepCopy()
+	result.Status.Allowed = !r.denied[attrs.Verb+" "+attrs.Group+"/"+attrs.Resource]
+	return result, nil
+}
+
+// completeResources returns a lister that reports every required resource.
+func completeResources() *fakeServerResourcesLister {
+	resources := map[string][]string{}
+	for _, req := range preflightResources {
+		resources[req.groupVersion] = append(resources[req.groupVersion], req.resource)
+	}
+	return &fakeServerResourcesLister{resources: resources}
+}
+
+func TestPreflight(t *testing.T) {
+	testCases := []struct {
+		description    string
+		mutateLister   func(*fakeServerResourcesLister)
+		denied         map[string]bool
+		expectMentions []string
+	}{
+		{
+			description: "all prerequisites present",
+		},
+		{
+			description: "one resource missing from a registered group",
+			mutateLister: func(l *fakeServerResourcesLister) {
+				l.resources["ingress.operator.openshift.io/v1"] = nil
+			},
+			expectMentions: []string{"dnsrecords"},
+		},
+		{
+			description: "an entire group-version missing",
+			mutateLister: func(l *fakeServerResourcesLister) {
+				delete(l.resources, "config.openshift.io/v1")
+			},
+			expectMentions: []string{"ingresses", "infrastructures", "dnses", "proxies"},
+		},
+		{
+			description: "a verb denied",
+			denied:      map[string]bool{"create apps/deployments": true},
+			expectMentions: []string{
+				"cannot create deployments",
+			},
+		},
+		{
+			description: "missing resource and denied verb are both reported",
+			mutateLister: func(l *fakeServerResourcesLister) {
+				delete(l.resources, "route.openshift.io/v1")
+			},
+			denied:         map[string]bool{"update /configmaps": true},
+			expectMentions: []string{"routes", "cannot update configmaps"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			lister := completeResources()
+			if tc.mutateLister != nil {
+				tc.mutateLister(lister)
+			}
+			err := preflight(lister, &fakeAccessReviewer{denied: tc.denied})
+			if len(tc.expectMentions) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error mentioning %v", tc.expectMentions)
+			}
+			for _, mention := range tc.expectMentions {
+				if !strings.Contains(err.Error(), mention) {
+					t.Errorf("expected error to mention %q, got: %v", mention, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package operator
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// trustedCAConfigMapName is the name of the configmap, created from the
+	// operator's manifests, that holds the CA bundle that the operator
+	// trusts when making outbound connections.
+	trustedCAConfigMapName = "trusted-ca"
+
+	// trustedCABundleKey is the configmap key under which the CA bundle is
+	// stored.
+	trustedCABundleKey = "ca-bundle.crt"
+
+	// userTrustedCABundleKey is the configmap key under which the operator
+	// records the user-provided CA bundle that it last appended to the
+	// trusted CA bundle, so that the appended portion can be identified
+	// and removed when the source configmap is removed.
+	userTrustedCABundleKey = "user-ca-bundle.crt"
+
+	// injectTrustedCABundleLabel is the label that tells the
+	// cluster-network-operator to inject the cluster's trusted CA bundle
+	// into a configmap.
+	injectTrustedCABundleLabel = "config.openshift.io/inject-trusted-cabundle"
+
+	// trustedCAMirrorLabel is the label that marks trusted CA configmaps
+	// that the operator mirrors into operand namespaces, so that stale
+	// mirrors can be found and deleted when a namespace is removed from
+	// the mirror set.
+	trustedCAMirrorLabel = "ingress.operator.openshift.io/trusted-ca-mirror"
+)
+
+// ensureTrustedCAConfigMap reconciles the trusted CA configmap.  When a
+// source configmap is configured, the valid certificates from its CA bundle
+// are appended to the injected bundle so that user-provided CAs (for
+// example, corporate proxy CAs) are trusted alongside the cluster's trust
+// store.  The injection label is kept so that the injector continues to
+// manage the injected portion.  A missing source configmap is not an error;
+// the trusted CA configmap then carries the injected bundle only.  The
+// trusted CA configmap itself is created from the operator's manifests, so a
+// missing configmap is not an error either.
+//
+// In addition, an injection-enabled copy of the configmap is reconciled into
+// each of the given mirror namespaces (for example, namespaces that hold
+// routers outside the default operand namespace), and stale copies in
+// namespaces no longer in the set are deleted.
+func (o *Operator) ensureTrustedCAConfigMap(mirrorNamespaces ...string) error {
+	var errs []error
+	if err := o.ensureOperatorTrustedCAConfigMap(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := o.ensureTrustedCAMirrorConfigMaps(mirrorNamespaces); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ensureOperatorTrustedCAConfigMap reconciles the trusted CA configmap in the
+// operator namespace.
+func (o *Operator) ensureOperatorTrustedCAConfigMap() error {
+	current := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: o.namespace, Name: trustedCAConfigMapName}
+	if err := o.client.Get(context.TODO(), name, current); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get configmap %s: %v", name, err)
+	}
+	var source *corev1.ConfigMap
+	if len(o.config.TrustedCASourceConfigMap) != 0 {
+		source = &corev1.ConfigMap{}
+		sourceName := types.NamespacedName{Namespace: o.namespace, Name: o.config.TrustedCASourceConfigMap}
+		if err := o.client.Get(context.TODO(), sourceName, source); err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get source configmap %s: %v", sourceName, err)
+			}
+			log.Info("trusted CA source configmap not found; using the injected bundle only", "name", sourceName)
+			source = nil
+		} else if _, ok := source.Data[trustedCABundleKey]; !ok {
+			log.Info("trusted CA source configmap is missing the CA bundle key; using the injected bundle only", "name", sourceName, "key", trustedCABundleKey)
+			source = nil
+		}
+	}
+	if changed, err := o.updateTrustedCAConfigMap(current, source); err != nil {
+		return err
+	} else if changed {
+		log.Info("updated trusted CA configmap", "namespace", current.Namespace, "name", current.Name)
+	}
+	return nil
+}
+
+// ensureTrustedCAMirrorConfigMaps reconciles an injection-enabled trusted CA
+// configmap into each of the given namespaces and deletes mirror configmaps
+// in namespaces that are no longer in the set.  The operator namespace's own
+// trusted CA configmap is managed separately and is never treated as a
+// mirror.
+func (o *Operator) ensureTrustedCAMirrorConfigMaps(namespaces []string) error {
+	desired := map[string]bool{}
+	for _, namespace := range namespaces {
+		if len(namespace) != 0 && namespace != o.namespace {
+			desired[namespace] = true
+		}
+	}
+	var errs []error
+	mirrors := &corev1.ConfigMapList{}
+	if err := o.client.List(context.TODO(), mirrors, crclient.MatchingLabels{trustedCAMirrorLabel: "true"}); err != nil {
+		return fmt.Errorf("failed to list trusted CA mirror configmaps: %v", err)
+	}
+	for i := range mirrors.Items {
+		mirror := &mirrors.Items[i]
+		if desired[mirror.Namespace] {
+			continue
+		}
+		if err := o.client.Delete(context.TODO(), mirror); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete stale trusted CA mirror configmap %s/%s: %v", mirror.Namespace, mirror.Name, err))
+			continue
+		}
+		log.Info("deleted stale trusted CA mirror configmap", "namespace", mirror.Namespace, "name", mirror.Name)
+	}
+	for namespace := range desired {
+		if err := o.ensureTrustedCAMirrorConfigMap(namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ensureTrustedCAMirrorConfigMap reconciles an injection-enabled trusted CA
+// configmap in the given namespace.  The configmap carries the injection
+// label so that the injector fills in the trust bundle and the mirror label
+// so that the operator can find and delete the configmap when the namespace
+// leaves the mirror set.
+func (o *Operator) ensureTrustedCAMirrorConfigMap(namespace string) error {
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCAConfigMapName}
+	current := &corev1.ConfigMap{}
+	if err := o.client.Get(context.TODO(), name, current); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get configmap %s: %v", name, err)
+		}
+		mirror := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      trustedCAConfigMapName,
+				Labels: map[string]string{
+					injectTrustedCABundleLabel: "true",
+					trustedCAMirrorLabel:       "true",
+				},
+			},
+		}
+		if err := o.client.Create(context.TODO(), mirror); err != nil {
+			return fmt.Errorf("failed to create configmap %s: %v", name, err)
+		}
+		log.Info("created trusted CA mirror configmap", "namespace", namespace, "name", mirror.Name)
+		return nil
+	}
+	if current.Labels[injectTrustedCABundleLabel] == "true" && current.Labels[trustedCAMirrorLabel] == "true" {
+		return nil
+	}
+	updated := current.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	updated.Labels[injectTrustedCABundleLabel] = "true"
+	updated.Labels[trustedCAMirrorLabel] = "true"
+	if err := o.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update configmap %s: %v", name, err)
+	}
+	log.Info("updated trusted CA mirror configmap", "namespace", namespace, "name", updated.Name)
+	return nil
+}
+
+// desiredTrustedCAConfigMap returns the desired trusted CA configmap given
+// the current configmap, an optional source configmap whose CA bundle is
+// appended to the injected bundle, and optional extra annotations and labels
+// (for example, compliance metadata) to enforce on the configmap.  Extra
+// metadata is merged: keys the operator is not configured to own are left
+// alone, and the injection label always wins over a conflicting extra label.
+func desiredTrustedCAConfigMap(current, source *corev1.ConfigMap, annotations, labels map[string]string) *corev1.ConfigMap {
+	desired := current.DeepCopy()
+	if len(annotations) != 0 && desired.Annotations == nil {
+		desired.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		desired.Annotations[key] = value
+	}
+	if desired.Labels == nil {
+		desired.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		desired.Labels[key] = value
+	}
+	desired.Labels[injectTrustedCABundleLabel] = "true"
+
+	// Strip the user-provided portion that a previous reconcile appended,
+	// leaving the injected bundle.  If the injector has since overwritten
+	// the bundle, the appended portion is already gone.
+	injected := desired.Data[trustedCABundleKey]
+	if previous := desired.Data[userTrustedCABundleKey]; len(previous) != 0 && strings.HasSuffix(injected, previous) {
+		injected = strings.TrimSuffix(strings.TrimSuffix(injected, previous), "\n")
+	}
+
+	var userBundle string
+	if source != nil {
+		userBundle = validCertificates(source.Data[trustedCABundleKey], fmt.Sprintf("%s/%s", source.Namespace, source.Name))
+	}
+	if len(userBundle) == 0 {
+		if _, ok := desired.Data[userTrustedCABundleKey]; ok {
+			// Revert to the injected bundle only.
+			desired.Data[trustedCABundleKey] = injected
+			delete(desired.Data, userTrustedCABundleKey)
+		}
+		return desired
+	}
+	if desired.Data == nil {
+		desired.Data = map[string]string{}
+	}
+	merged := userBundle
+	if len(injected) != 0 {
+		merged = injected + "\n" + userBundle
+	}
+	desired.Data[trustedCABundleKey] = merged
+	desired.Data[userTrustedCABundleKey] = userBundle
+	return desired
+}
+
+// validCertificates returns the PEM-encoded certificates from the given
+// bundle, skipping any block that is not a valid certificate.  The source
+// parameter names the bundle's origin for logging.
+func validCertificates(bundle, source string) string {
+	var out []byte
+	for rest := []byte(bundle); len(rest) != 0; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			log.Info("skipping non-certificate PEM block in CA bundle", "source", source, "type", block.Type)
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			log.Info("skipping invalid certificate in CA bundle", "source", source, "error", err.Error())
+			continue
+		}
+		out = append(out, pem.EncodeToMemory(block)...)
+	}
+	return string(out)
+}
+
+// conflictRetryBackoff bounds the retries of an update that conflicts with a
+// concurrent writer.  The values match client-go's default conflict retry
+// backoff.
+var conflictRetryBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: 10 * time.Millisecond,
+	Factor:   5.0,
+	Jitter:   0.1,
+}
+
+// updateTrustedCAConfigMap computes the desired trusted CA configmap from the
+// current configmap and the optional source configmap and updates it if the
+// desired state differs from the current state.  An update conflict (for
+// example, with the injector writing the same configmap concurrently) is
+// retried after re-reading the configmap and rebasing the desired state onto
+// the new version.
+func (o *Operator) updateTrustedCAConfigMap(current *corev1.ConfigMap, source *corev1.ConfigMap) (bool, error) {
+	name := types.NamespacedName{Namespace: current.Namespace, Name: current.Name}
+	changed := false
+	var conflictErr error
+	err := wait.ExponentialBackoff(conflictRetryBackoff, func() (bool, error) {
+		desired := desiredTrustedCAConfigMap(current, source, o.config.TrustedCAConfigMapAnnotations, o.config.TrustedCAConfigMapLabels)
+		if !trustedCAConfigMapChanged(current, desired, o.config.TrustedCAConfigMapAnnotations, o.config.TrustedCAConfigMapLabels) {
+			changed = false
+			return true, nil
+		}
+		if err := o.client.Update(context.TODO(), desired); err != nil {
+			if errors.IsConflict(err) {
+				conflictErr = err
+				if err := o.client.Get(context.TODO(), name, current); err != nil {
+					return false, fmt.Errorf("failed to get configmap %s: %v", name, err)
+				}
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to update configmap %s: %v", name, err)
+		}
+		changed = true
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return false, fmt.Errorf("failed to update configmap %s: %v", name, conflictErr)
+	}
+	return changed, err
+}
+
+// trustedCAConfigMapChanged returns whether the fields of the trusted CA
+// configmap that the operator manages, including any configured extra
+// annotations and labels, differ between the current and desired configmaps.
+// Annotations and labels the operator is not configured to own are not
+// compared, so they never trigger (or get clobbered by) an update.
+func trustedCAConfigMapChanged(current, desired *corev1.ConfigMap, annotations, labels map[string]string) bool {
+	if desired.Data[trustedCABundleKey] != current.Data[trustedCABundleKey] ||
+		desired.Data[userTrustedCABundleKey] != current.Data[userTrustedCABundleKey] ||
+		desired.Labels[injectTrustedCABundleLabel] != current.Labels[injectTrustedCABundleLabel] {
+		return true
+	}
+	for key := range annotations {
+		if desired.Annotations[key] != current.Annotations[key] {
+			return true
+		}
+	}
+	for key := range labels {
+		if desired.Labels[key] != current.Labels[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,437 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	operatorconfig "github.com/openshift/cluster-ingress-operator/pkg/operator/config"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// userCert is a PEM-encoded certificate used as user-provided CA material in
+// tests.
+const userCert = `-----BEGIN CERTIFICATE-----
+MIIDIjCCAgqgAwIBAgIBBjANBgkqhkiG9w0BAQUFADCBoTELMAkGA1UEBhMCVVMx
+CzAJBgNVBAgMAlNDMRUwEwYDVQQHDAxEZWZhdWx0IENpdHkxHDAaBgNVBAoME0Rl
+ZmF1bHQgQ29tcGFueSBMdGQxEDAOBgNVBAsMB1Rlc3QgQ0ExGjAYBgNVBAMMEXd3
+dy5leGFtcGxlY2EuY29tMSIwIAYJKoZIhvcNAQkBFhNleGFtcGxlQGV4YW1wbGUu
+Y29tMB4XDTE2MDExMzE5NDA1N1oXDTI2MDExMDE5NDA1N1owfDEYMBYGA1UEAxMP
+d3d3LmV4YW1wbGUuY29tMQswCQYDVQQIEwJTQzELMAkGA1UEBhMCVVMxIjAgBgkq
+hkiG9w0BCQEWE2V4YW1wbGVAZXhhbXBsZS5jb20xEDAOBgNVBAoTB0V4YW1wbGUx
+EDAOBgNVBAsTB0V4YW1wbGUwgZ8wDQYJKoZIhvcNAQEBBQADgY0AMIGJAoGBAM0B
+u++oHV1wcphWRbMLUft8fD7nPG95xs7UeLPphFZuShIhhdAQMpvcsFeg+Bg9PWCu
+v3jZljmk06MLvuWLfwjYfo9q/V+qOZVfTVHHbaIO5RTXJMC2Nn+ACF0kHBmNcbth
+OOgF8L854a/P8tjm1iPR++vHnkex0NH7lyosVc/vAgMBAAGjDTALMAkGA1UdEwQC
+MAAwDQYJKoZIhvcNAQEFBQADggEBADjFm5AlNH3DNT1Uzx3m66fFjqqrHEs25geT
+yA3rvBuynflEHQO95M/8wCxYVyuAx4Z1i4YDC7tx0vmOn/2GXZHY9MAj1I8KCnwt
+Jik7E2r1/yY0MrkawljOAxisXs821kJ+Z/51Ud2t5uhGxS6hJypbGspMS7OtBbw7
+8oThK7cWtCXOldNF6ruqY1agWnhRdAq5qSMnuBXuicOP0Kbtx51a1ugE3SnvQenJ
+nZxdtYUXvEsHZC/6bAtTfNh+/SwgxQJuL2ZM+VG3X2JIKY8xTDui+il7uTh422lq
+wED8uwKl+bOj6xFDyw4gWoBxRobsbFaME8pkykP1+GnKDberyAM=
+-----END CERTIFICATE-----
+`
+
+func TestEnsureTrustedCAConfigMap(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      trustedCAConfigMapName,
+			Labels: map[string]string{
+				injectTrustedCABundleLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			trustedCABundleKey: "injected bundle",
+		},
+	}
+	sourceConfigMap := func(bundle string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "custom-ca",
+			},
+			Data: map[string]string{
+				trustedCABundleKey: bundle,
+			},
+		}
+	}
+	testCases := []struct {
+		description    string
+		sourceName     string
+		objects        []runtime.Object
+		expectedBundle string
+	}{
+		{
+			description:    "no source configured",
+			objects:        []runtime.Object{trustedCA.DeepCopy()},
+			expectedBundle: "injected bundle",
+		},
+		{
+			description:    "source bundle is appended to the injected bundle",
+			sourceName:     "custom-ca",
+			objects:        []runtime.Object{trustedCA.DeepCopy(), sourceConfigMap(userCert)},
+			expectedBundle: "injected bundle\n" + userCert,
+		},
+		{
+			description:    "invalid blocks in the source bundle are skipped",
+			sourceName:     "custom-ca",
+			objects:        []runtime.Object{trustedCA.DeepCopy(), sourceConfigMap(userCert + "-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydAo=\n-----END CERTIFICATE-----\n")},
+			expectedBundle: "injected bundle\n" + userCert,
+		},
+		{
+			description:    "source with no valid certificates leaves the injected bundle alone",
+			sourceName:     "custom-ca",
+			objects:        []runtime.Object{trustedCA.DeepCopy(), sourceConfigMap("not a pem bundle")},
+			expectedBundle: "injected bundle",
+		},
+		{
+			description:    "configured source does not exist",
+			sourceName:     "custom-ca",
+			objects:        []runtime.Object{trustedCA.DeepCopy()},
+			expectedBundle: "injected bundle",
+		},
+		{
+			description: "trusted CA configmap does not exist yet",
+			sourceName:  "custom-ca",
+			objects:     []runtime.Object{sourceConfigMap(userCert)},
+		},
+	}
+	for _, tc := range testCases {
+		client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), tc.objects...)
+		operator := &Operator{
+			client:    client,
+			namespace: namespace,
+			config: operatorconfig.Config{
+				TrustedCASourceConfigMap: tc.sourceName,
+			},
+		}
+		if err := operator.ensureTrustedCAConfigMap(); err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+			continue
+		}
+		if len(tc.expectedBundle) == 0 {
+			continue
+		}
+		cm := &corev1.ConfigMap{}
+		name := types.NamespacedName{Namespace: namespace, Name: trustedCAConfigMapName}
+		if err := client.Get(context.TODO(), name, cm); err != nil {
+			t.Errorf("%q: failed to get configmap: %v", tc.description, err)
+			continue
+		}
+		if cm.Data[trustedCABundleKey] != tc.expectedBundle {
+			t.Errorf("%q: expected bundle %q, got %q", tc.description, tc.expectedBundle, cm.Data[trustedCABundleKey])
+		}
+		if cm.Labels[injectTrustedCABundleLabel] != "true" {
+			t.Errorf("%q: expected the injection label to be kept, got labels %v", tc.description, cm.Labels)
+		}
+	}
+}
+
+// TestEnsureTrustedCAConfigMapRevert verifies that removing the source
+// configmap reverts the trusted CA bundle to the injected portion, and that
+// re-running the merge is idempotent.
+func TestEnsureTrustedCAConfigMapRevert(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      trustedCAConfigMapName,
+			Labels: map[string]string{
+				injectTrustedCABundleLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			trustedCABundleKey: "injected bundle",
+		},
+	}
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "custom-ca",
+		},
+		Data: map[string]string{
+			trustedCABundleKey: userCert,
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), trustedCA, source)
+	operator := &Operator{
+		client:    client,
+		namespace: namespace,
+		config: operatorconfig.Config{
+			TrustedCASourceConfigMap: "custom-ca",
+		},
+	}
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCAConfigMapName}
+	getBundle := func() *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		if err := client.Get(context.TODO(), name, cm); err != nil {
+			t.Fatalf("failed to get configmap: %v", err)
+		}
+		return cm
+	}
+
+	// Merge, and verify that a second reconcile does not append again.
+	for i := 0; i < 2; i++ {
+		if err := operator.ensureTrustedCAConfigMap(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cm := getBundle()
+		if expected := "injected bundle\n" + userCert; cm.Data[trustedCABundleKey] != expected {
+			t.Fatalf("reconcile %d: expected bundle %q, got %q", i, expected, cm.Data[trustedCABundleKey])
+		}
+		if count := strings.Count(cm.Data[trustedCABundleKey], "BEGIN CERTIFICATE"); count != 1 {
+			t.Fatalf("reconcile %d: expected 1 certificate in the bundle, got %d", i, count)
+		}
+	}
+
+	// Removing the source reverts to the injected bundle.
+	if err := client.Delete(context.TODO(), source); err != nil {
+		t.Fatalf("failed to delete source configmap: %v", err)
+	}
+	if err := operator.ensureTrustedCAConfigMap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cm := getBundle()
+	if cm.Data[trustedCABundleKey] != "injected bundle" {
+		t.Fatalf("expected the bundle to revert to the injected portion, got %q", cm.Data[trustedCABundleKey])
+	}
+	if _, ok := cm.Data[userTrustedCABundleKey]; ok {
+		t.Fatalf("expected the user bundle key to be removed, got %q", cm.Data[userTrustedCABundleKey])
+	}
+}
+
+func TestEnsureTrustedCAConfigMapRestoresInjection(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	// Simulate a trusted CA configmap whose injection label has been
+	// removed.
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      trustedCAConfigMapName,
+		},
+		Data: map[string]string{
+			trustedCABundleKey: "custom bundle",
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), trustedCA)
+	operator := &Operator{
+		client:    client,
+		namespace: namespace,
+		config:    operatorconfig.Config{},
+	}
+	if err := operator.ensureTrustedCAConfigMap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCAConfigMapName}
+	if err := client.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if cm.Labels[injectTrustedCABundleLabel] != "true" {
+		t.Errorf("expected injection label to be restored, got labels %v", cm.Labels)
+	}
+}
+
+// conflictOnceClient returns a conflict error for the first n updates and
+// delegates thereafter.
+type conflictOnceClient struct {
+	crclient.Client
+	conflicts int
+	updates   int
+}
+
+func (c *conflictOnceClient) Update(ctx context.Context, obj runtime.Object, opts ...crclient.UpdateOption) error {
+	c.updates++
+	if c.updates <= c.conflicts {
+		return errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "trusted-ca", fmt.Errorf("the object has been modified"))
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// TestUpdateTrustedCAConfigMapConflictRetry verifies that an update conflict
+// with a concurrent writer is retried and the reconcile succeeds.
+func TestUpdateTrustedCAConfigMapConflictRetry(t *testing.T) {
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      trustedCAConfigMapName,
+		},
+		Data: map[string]string{trustedCABundleKey: "injected"},
+	}
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "user-ca-bundle",
+		},
+		Data: map[string]string{trustedCABundleKey: userCert},
+	}
+	client := &conflictOnceClient{
+		Client:    fake.NewFakeClientWithScheme(operatorclient.GetScheme(), trustedCA, source),
+		conflicts: 1,
+	}
+	o := &Operator{
+		client:    client,
+		namespace: "openshift-ingress-operator",
+		config: operatorconfig.Config{
+			TrustedCASourceConfigMap: source.Name,
+		},
+	}
+	if err := o.ensureTrustedCAConfigMap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.updates != 2 {
+		t.Fatalf("expected 2 update attempts, got %d", client.updates)
+	}
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: o.namespace, Name: trustedCAConfigMapName}
+	if err := client.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get trusted CA configmap: %v", err)
+	}
+	if !strings.Contains(cm.Data[trustedCABundleKey], "BEGIN CERTIFICATE") {
+		t.Errorf("expected the user CA bundle to be appended after the retried update, got: %q", cm.Data[trustedCABundleKey])
+	}
+}
+
+func TestEnsureTrustedCAMirrorConfigMaps(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme())
+	operator := &Operator{
+		client:    client,
+		namespace: namespace,
+	}
+	mirrorExists := func(mirrorNamespace string) (bool, error) {
+		cm := &corev1.ConfigMap{}
+		name := types.NamespacedName{Namespace: mirrorNamespace, Name: trustedCAConfigMapName}
+		if err := client.Get(context.TODO(), name, cm); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if cm.Labels[injectTrustedCABundleLabel] != "true" {
+			return false, fmt.Errorf("mirror configmap %s is missing the %s label: %v", name, injectTrustedCABundleLabel, cm.Labels)
+		}
+		if cm.Labels[trustedCAMirrorLabel] != "true" {
+			return false, fmt.Errorf("mirror configmap %s is missing the %s label: %v", name, trustedCAMirrorLabel, cm.Labels)
+		}
+		return true, nil
+	}
+	if err := operator.ensureTrustedCAConfigMap("ns-a", "ns-b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, mirrorNamespace := range []string{"ns-a", "ns-b"} {
+		if exists, err := mirrorExists(mirrorNamespace); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if !exists {
+			t.Fatalf("expected a mirror configmap in namespace %s", mirrorNamespace)
+		}
+	}
+	if err := operator.ensureTrustedCAConfigMap("ns-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exists, err := mirrorExists("ns-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !exists {
+		t.Fatalf("expected the mirror configmap in namespace ns-a to be kept")
+	}
+	if exists, err := mirrorExists("ns-b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if exists {
+		t.Fatalf("expected the mirror configmap in namespace ns-b to be deleted")
+	}
+}
+
+// TestEnsureTrustedCAConfigMapExtraMetadata verifies that configured extra
+// annotations and labels are enforced on the trusted CA configmap while
+// foreign metadata keys are preserved.
+func TestEnsureTrustedCAConfigMapExtraMetadata(t *testing.T) {
+	const namespace = "openshift-ingress-operator"
+	trustedCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      trustedCAConfigMapName,
+			Annotations: map[string]string{
+				"cost-center": "stale",
+				"description": "trusted CA bundle",
+			},
+			Labels: map[string]string{
+				injectTrustedCABundleLabel: "true",
+				"foreign-label":            "kept",
+			},
+		},
+		Data: map[string]string{
+			trustedCABundleKey: "injected bundle",
+		},
+	}
+	client := fake.NewFakeClientWithScheme(operatorclient.GetScheme(), trustedCA)
+	operator := &Operator{
+		client:    client,
+		namespace: namespace,
+		config: operatorconfig.Config{
+			TrustedCAConfigMapAnnotations: map[string]string{
+				"cost-center": "ingress",
+				"owner":       "network-edge",
+			},
+			TrustedCAConfigMapLabels: map[string]string{
+				"compliance-scope": "platform",
+				// Attempts to override the injection label are
+				// ignored.
+				injectTrustedCABundleLabel: "false",
+			},
+		},
+	}
+	if err := operator.ensureTrustedCAConfigMap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCAConfigMapName}
+	if err := client.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	expectedAnnotations := map[string]string{
+		"cost-center": "ingress",
+		"owner":       "network-edge",
+		"description": "trusted CA bundle",
+	}
+	for key, value := range expectedAnnotations {
+		if cm.Annotations[key] != value {
+			t.Errorf("expected annotation %s=%s, got %q", key, value, cm.Annotations[key])
+		}
+	}
+	expectedLabels := map[string]string{
+		"compliance-scope":         "platform",
+		"foreign-label":            "kept",
+		injectTrustedCABundleLabel: "true",
+	}
+	for key, value := range expectedLabels {
+		if cm.Labels[key] != value {
+			t.Errorf("expected label %s=%s, got %q", key, value, cm.Labels[key])
+		}
+	}
+
+	// A second pass is a no-op.
+	resourceVersion := cm.ResourceVersion
+	if err := operator.ensureTrustedCAConfigMap(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get configmap: %v", err)
+	}
+	if cm.ResourceVersion != resourceVersion {
+		t.Errorf("expected the second pass not to update the configmap")
+	}
+}
@@ -14,15 +14,17 @@ import (
 // Consumers can configure the aws Session using that file to use the credentials like,
 //
 // sharedCredentialsFile, err := SharedCredentialsFileFromSecret(secret)
-// if err != nil {
-// 	// handle error
-// }
-// options := session.Options{
-// 	SharedConfigState: session.SharedConfigEnable,
-// 	SharedConfigFiles: []string{sharedCredentialsFile},
-// }
-// sess := session.Must(session.NewSessionWithOptions(options))
 //
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	options := session.Options{
+//		SharedConfigState: session.SharedConfigEnable,
+//		SharedConfigFiles: []string{sharedCredentialsFile},
+//	}
+//
+// sess := session.Must(session.NewSessionWithOptions(options))
 func SharedCredentialsFileFromSecret(secret *corev1.Secret) (string, error) {
 	var data []byte
 	switch {